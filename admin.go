@@ -0,0 +1,63 @@
+package main
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// broadcastHud is the time a broadcast is shown in the frames of every session
+const broadcastHud = 30 * time.Second
+
+// broadcastMessage is the JSON body of adminBroadcast
+type broadcastMessage struct {
+	Message string `json:"message"`
+}
+
+// registerAdmin serves the session admin API below /admin/, requests have to carry the token
+// as bearer token. In farm mode requests are forwarded to the workers.
+func registerAdmin(router gin.IRouter, token string, workers *farm) {
+	admin := router.Group("/admin", requireToken(token))
+	if workers != nil {
+		admin.GET("/sessions", workers.listSessions)
+		admin.DELETE("/sessions/:id", workers.lookup)
+		admin.POST("/broadcast", workers.broadcast)
+		return
+	}
+	admin.GET("/sessions", adminSessions)
+	admin.DELETE("/sessions/:id", adminDisconnect)
+	admin.POST("/broadcast", adminBroadcast)
+}
+
+// adminSessions lists the running sessions with client, model, uptime and bandwidth
+func adminSessions(c *gin.Context) {
+	c.JSON(http.StatusOK, sessions.list(time.Now()))
+}
+
+// adminDisconnect closes a session, the client gets a disconnected message with the optional reason query parameter
+func adminDisconnect(c *gin.Context) {
+	id := c.Param("id")
+	clients := sessions.clients(id, "")
+	if len(clients) == 0 {
+		c.String(http.StatusNotFound, "unknown session %s", id)
+		return
+	}
+	go clients[0].app.Disconnect("disconnected", c.Query("reason"))
+	c.JSON(http.StatusAccepted, gin.H{"sessions": 1})
+}
+
+// adminBroadcast sends a message to the clients of all sessions, e.g. before maintenance
+func adminBroadcast(c *gin.Context) {
+	var m broadcastMessage
+	if err := c.ShouldBindJSON(&m); err != nil || m.Message == "" {
+		c.String(http.StatusBadRequest, "a broadcast needs a message")
+		return
+	}
+	clients := sessions.clients("", "")
+	for _, client := range clients {
+		go client.app.Notify("broadcast", m.Message)
+		client.app.ShowHud("broadcast", m.Message, true, broadcastHud)
+	}
+	c.JSON(http.StatusAccepted, gin.H{"sessions": len(clients)})
+}
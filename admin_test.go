@@ -0,0 +1,67 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestAdminEndpoints(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	registerAdmin(router, "secret", nil)
+
+	request := func(method, url, body, token string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(method, url, strings.NewReader(body))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+	if rec := request("GET", "/admin/sessions", "", ""); rec.Code != http.StatusUnauthorized {
+		t.Error("sessions listed without token", rec.Code)
+	}
+	rec := request("GET", "/admin/sessions", "", "secret")
+	if rec.Code != http.StatusOK || strings.TrimSpace(rec.Body.String()) != "[]" {
+		t.Error("sessions not listed", rec.Code, rec.Body.String())
+	}
+	if rec := request("DELETE", "/admin/sessions/unknown", "", "secret"); rec.Code != http.StatusNotFound {
+		t.Error("unknown session disconnected", rec.Code)
+	}
+	if rec := request("POST", "/admin/broadcast", `{"message": ""}`, "secret"); rec.Code != http.StatusBadRequest {
+		t.Error("empty broadcast accepted", rec.Code)
+	}
+	if rec := request("POST", "/admin/broadcast", `{"message": "restart at 18:00"}`, "secret"); rec.Code != http.StatusAccepted {
+		t.Error("broadcast rejected", rec.Code)
+	}
+}
+
+func TestSessionManagerList(t *testing.T) {
+	m := NewSessionManager()
+	m.add("a", &Client{model: "Cathedral.glb", addr: "10.0.0.1", user: "jane"})
+	time.Sleep(time.Millisecond)
+	m.add("b", &Client{model: "Building.glb"})
+	m.sessions["a"].started = m.sessions["a"].started.Add(-time.Minute)
+
+	list := m.list(time.Now())
+	assert(t, len(list), 2)
+	assert(t, list[0].ID, "a")
+	assert(t, list[0].Client, "10.0.0.1")
+	assert(t, list[0].User, "jane")
+	assert(t, list[0].Model, "Cathedral.glb")
+	if list[0].Uptime < 60 {
+		t.Error("wrong uptime", list[0].Uptime)
+	}
+	data, err := json.Marshal(list[1])
+	assert(t, err, nil)
+	if !strings.Contains(string(data), `"bytes_sent":0`) {
+		t.Error("bytes sent missing", string(data))
+	}
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// envPrefix is the prefix of environment variables overriding flags, e.g. WEBG3N_MAX_SESSIONS
+const envPrefix = "WEBG3N_"
+
+// envName returns the environment variable of a flag
+func envName(flagName string) string {
+	return envPrefix + strings.ToUpper(strings.Replace(flagName, "-", "_", -1))
+}
+
+// loadConfig sets all flags not given on the command line from environment variables
+// and then from the YAML config file, if any.
+// The config file maps flag names to values, repeatable flags take a list.
+func loadConfig(fs *flag.FlagSet, path string, getenv func(string) string) error {
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+
+	var err error
+	fs.VisitAll(func(f *flag.Flag) {
+		value := getenv(envName(f.Name))
+		if set[f.Name] || value == "" || err != nil {
+			return
+		}
+		if e := fs.Set(f.Name, value); e != nil {
+			err = fmt.Errorf("invalid %s: %v", envName(f.Name), e)
+		}
+		set[f.Name] = true
+	})
+	if err != nil || path == "" {
+		return err
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var values map[string]interface{}
+	if err := yaml.Unmarshal(data, &values); err != nil {
+		return fmt.Errorf("invalid config file %s: %v", path, err)
+	}
+	for name, value := range values {
+		if fs.Lookup(name) == nil {
+			return fmt.Errorf("unknown setting %s in %s", name, path)
+		}
+		if set[name] {
+			continue
+		}
+		list, ok := value.([]interface{})
+		if !ok {
+			list = []interface{}{value}
+		}
+		for _, v := range list {
+			if err := fs.Set(name, fmt.Sprint(v)); err != nil {
+				return fmt.Errorf("invalid %s in %s: %v", name, path, err)
+			}
+		}
+	}
+	return nil
+}
+
+// configPath returns the config file given by flag or environment
+func configPath(path string) string {
+	if path != "" {
+		return path
+	}
+	return os.Getenv(envName("config"))
+}
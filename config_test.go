@@ -0,0 +1,58 @@
+package main
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+// newTestFlags returns a flag set with a few settings of different types
+func newTestFlags() (*flag.FlagSet, *string, *int, *bool) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	addr := fs.String("addr", ":8000", "")
+	sessions := fs.Int("max-sessions", 0, "")
+	watch := fs.Bool("watch", false, "")
+	return fs, addr, sessions, watch
+}
+
+func TestLoadConfig(t *testing.T) {
+	f, err := ioutil.TempFile("", "webg3n*.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	f.WriteString("addr: :9000\nmax-sessions: 4\nwatch: true\n")
+	f.Close()
+
+	fs, addr, sessions, watch := newTestFlags()
+	fs.Parse([]string{"-watch=false"})
+	env := map[string]string{"WEBG3N_MAX_SESSIONS": "8"}
+	if err := loadConfig(fs, f.Name(), func(name string) string { return env[name] }); err != nil {
+		t.Fatal(err)
+	}
+	if *addr != ":9000" {
+		t.Error("file setting not applied:", *addr)
+	}
+	if *sessions != 8 {
+		t.Error("environment does not override file:", *sessions)
+	}
+	if *watch {
+		t.Error("file overrides command line")
+	}
+}
+
+func TestLoadConfigUnknownSetting(t *testing.T) {
+	f, err := ioutil.TempFile("", "webg3n*.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	f.WriteString("port: 9000\n")
+	f.Close()
+
+	fs, _, _, _ := newTestFlags()
+	if err := loadConfig(fs, f.Name(), func(string) string { return "" }); err == nil {
+		t.Error("unknown setting accepted")
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/moethu/webg3n/renderer"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maximum size of uploaded models
+const maxConvertSize = 256 << 20
+
+// convertModel converts an uploaded model to glTF.
+// The model is sent as multipart form file "model", its format is taken from the file extension.
+// Meshes can be simplified with the maxtriangles and tolerance query parameters.
+func convertModel(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxConvertSize)
+	header, err := c.FormFile("model")
+	if err != nil {
+		c.String(http.StatusBadRequest, "missing model: %v", err)
+		return
+	}
+	ext := strings.ToLower(filepath.Ext(header.Filename))
+	supported := false
+	for _, format := range renderer.ConvertFormats {
+		supported = supported || ext == format
+	}
+	if !supported {
+		c.String(http.StatusUnsupportedMediaType, "unsupported format: %s", ext)
+		return
+	}
+	file, err := header.Open()
+	if err != nil {
+		c.String(http.StatusBadRequest, err.Error())
+		return
+	}
+	defer file.Close()
+
+	options := renderer.LoadOptions{}
+	if maxTriangles, err := strconv.Atoi(c.Query("maxtriangles")); err == nil {
+		options.MaxTriangles = maxTriangles
+	}
+	if tolerance, err := strconv.ParseFloat(c.Query("tolerance"), 32); err == nil {
+		options.Tolerance = float32(tolerance)
+	}
+
+	var out bytes.Buffer
+	if err := renderer.ConvertModel(file, ext, options, &out); err != nil {
+		c.String(http.StatusUnprocessableEntity, "conversion failed: %v", err)
+		return
+	}
+	name := strings.TrimSuffix(filepath.Base(header.Filename), filepath.Ext(header.Filename)) + ".gltf"
+	c.Header("Content-Disposition", "attachment; filename=\""+name+"\"")
+	c.Data(http.StatusOK, "model/gltf+json", out.Bytes())
+}
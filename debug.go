@@ -0,0 +1,50 @@
+package main
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"net/http/pprof"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// registerDebug serves the pprof profiles and execution traces below /debug/pprof/,
+// requests have to carry the token as bearer token
+func registerDebug(router gin.IRouter, token string) {
+	debug := router.Group("/debug/pprof", requireToken(token))
+	debug.GET("/*name", debugHandler)
+	debug.POST("/*name", debugHandler)
+}
+
+// debugHandler serves the index, a named profile or an execution trace
+func debugHandler(c *gin.Context) {
+	switch name := strings.TrimPrefix(c.Param("name"), "/"); name {
+	case "":
+		pprof.Index(c.Writer, c.Request)
+	case "cmdline":
+		pprof.Cmdline(c.Writer, c.Request)
+	case "profile":
+		pprof.Profile(c.Writer, c.Request)
+	case "symbol":
+		pprof.Symbol(c.Writer, c.Request)
+	case "trace":
+		pprof.Trace(c.Writer, c.Request)
+	default:
+		// heap, goroutine, allocs, block, mutex and threadcreate profiles
+		pprof.Handler(name).ServeHTTP(c.Writer, c.Request)
+	}
+}
+
+// requireToken rejects requests without the token in the Authorization header
+func requireToken(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// tokens in query strings would end up in access logs
+		given := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestDebugEndpoints(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	registerDebug(router, "secret")
+
+	get := func(url string, header string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", url, nil)
+		if header != "" {
+			req.Header.Set("Authorization", header)
+		}
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+	if rec := get("/debug/pprof/", ""); rec.Code != http.StatusUnauthorized {
+		t.Error("profiles served without token", rec.Code)
+	}
+	if rec := get("/debug/pprof/", "Bearer wrong"); rec.Code != http.StatusUnauthorized {
+		t.Error("profiles served with wrong token", rec.Code)
+	}
+	if rec := get("/debug/pprof/", "Bearer secret"); rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "goroutine") {
+		t.Error("index not served", rec.Code)
+	}
+	if rec := get("/debug/pprof/goroutine?debug=1", "Bearer secret"); rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "goroutine profile") {
+		t.Error("goroutine profile not served", rec.Code)
+	}
+	if rec := get("/debug/pprof/?token=secret", ""); rec.Code != http.StatusUnauthorized {
+		t.Error("profiles served with token in query", rec.Code)
+	}
+}
@@ -0,0 +1,365 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+// time to wait before a crashed worker gets restarted
+const workerRestartDelay = time.Second
+
+// worker is a render process of the farm, serving sessions on a local port
+type worker struct {
+	addr     string
+	gpu      string
+	device   int // index of the GPU in the scheduler of the farm
+	cmd      *exec.Cmd
+	sessions int
+	restarts int
+	rooms    map[string]int // sessions per room
+}
+
+// farm dispatches sessions to worker processes, so a crashing GL context only ends the sessions of its worker
+type farm struct {
+	mu       sync.Mutex
+	workers  []*worker
+	gpus     *gpuScheduler
+	stopping bool
+	wg       sync.WaitGroup
+}
+
+// newFarm creates a farm of n workers listening on consecutive ports, GPUs are assigned round robin
+func newFarm(n int, port int, gpus *gpuScheduler) *farm {
+	devices := gpus.devices
+	if n < len(devices) {
+		// GPUs without a worker can't take sessions
+		devices = devices[:n]
+	}
+	f := &farm{gpus: newGPUScheduler(strings.Join(devices, ","))}
+	for i := 0; i < n; i++ {
+		f.workers = append(f.workers, &worker{
+			addr:   "127.0.0.1:" + strconv.Itoa(port+i),
+			gpu:    devices[i%len(devices)],
+			device: i % len(devices),
+		})
+	}
+	return f
+}
+
+// workerArgs returns the command line of a worker, later flags override the ones of the dispatcher
+func workerArgs(args []string, w *worker) []string {
+	// WebTransport clients are relayed by the dispatcher
+	return append(append([]string(nil), args...), "-workers=0", "-addr="+w.addr, "-gpus="+w.gpu, "-webtransport-addr=")
+}
+
+// start runs all workers and restarts them when they exit.
+// Each worker selects its GPU by -gpus before it creates any GL context.
+func (f *farm) start(args []string) {
+	for _, w := range f.workers {
+		f.wg.Add(1)
+		go f.supervise(w, workerArgs(args, w))
+	}
+}
+
+// supervise runs a worker until the farm stops
+func (f *farm) supervise(w *worker, args []string) {
+	defer f.wg.Done()
+	for {
+		cmd := exec.Command(os.Args[0], args...)
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		err := cmd.Start()
+		if err == nil {
+			f.mu.Lock()
+			w.cmd = cmd
+			f.mu.Unlock()
+			err = cmd.Wait()
+		}
+		f.mu.Lock()
+		w.cmd = nil
+		stopping := f.stopping
+		if !stopping {
+			w.restarts++
+		}
+		f.mu.Unlock()
+		if stopping {
+			return
+		}
+		log.Printf("Worker %s exited: %v, restarting", w.addr, err)
+		time.Sleep(workerRestartDelay)
+	}
+}
+
+// stop asks all workers to drain their sessions and waits for them to exit
+func (f *farm) stop(ctx context.Context) error {
+	f.mu.Lock()
+	f.stopping = true
+	for _, w := range f.workers {
+		if w.cmd != nil {
+			w.cmd.Process.Signal(syscall.SIGTERM)
+		}
+	}
+	f.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		f.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// acquire returns the worker running the fewest sessions on the least loaded GPU and counts a new session on it.
+// Sessions of a room are placed on the worker already hosting the room, so participants see each other.
+func (f *farm) acquire(room string) *worker {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var best *worker
+	if room != "" {
+		for _, w := range f.workers {
+			if w.rooms[room] > 0 {
+				best = w
+				break
+			}
+		}
+	}
+	if best == nil {
+		device := f.gpus.leastLoaded()
+		for _, w := range f.workers {
+			if w.device == device && (best == nil || w.sessions < best.sessions) {
+				best = w
+			}
+		}
+	}
+	if room != "" {
+		if best.rooms == nil {
+			best.rooms = make(map[string]int)
+		}
+		best.rooms[room]++
+	}
+	f.gpus.assign(best.device)
+	best.sessions++
+	return best
+}
+
+// release removes a session from a worker
+func (f *farm) release(w *worker, room string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	w.sessions--
+	f.gpus.release(w.device)
+	if room != "" {
+		if w.rooms[room]--; w.rooms[room] <= 0 {
+			delete(w.rooms, room)
+		}
+	}
+}
+
+// serveWebsocket relays a websocket or WebTransport client to the least loaded worker
+func (f *farm) serveWebsocket(c *gin.Context) {
+	room := c.Request.URL.Query().Get("room")
+	w := f.acquire(room)
+	defer f.release(w, room)
+	u := url.URL{Scheme: "ws", Host: w.addr, Path: "/webg3n", RawQuery: c.Request.URL.RawQuery}
+	conn, _, err := websocket.DefaultDialer.Dial(u.String(), http.Header{"X-Forwarded-For": {c.ClientIP()}})
+	if err != nil {
+		log.Printf("Worker %s unavailable: %v", w.addr, err)
+		c.AbortWithStatus(http.StatusServiceUnavailable)
+		return
+	}
+	backend := &wsTransport{conn: conn}
+	client, err := upgradeClient(c)
+	if err != nil {
+		backend.close(websocket.CloseGoingAway, "")
+		return
+	}
+
+	errc := make(chan error, 2)
+	go relay(client, backend, errc)
+	go relay(backend, client, errc)
+	err = <-errc
+	if _, closed := err.(*websocket.CloseError); !closed {
+		// the worker went away without closing the session
+		client.close(websocket.CloseTryAgainLater, "worker-failed")
+	}
+	backend.close(websocket.CloseNoStatusReceived, "")
+}
+
+// relay copies messages from src to dst, close messages are passed on
+func relay(dst transport, src transport, errc chan<- error) {
+	for {
+		message, err := src.readMessage()
+		if err != nil {
+			if ce, ok := err.(*websocket.CloseError); ok {
+				dst.close(ce.Code, ce.Text)
+			}
+			errc <- err
+			return
+		}
+		if err := dst.writeMessage(message); err != nil {
+			errc <- err
+			return
+		}
+	}
+}
+
+// broadcast forwards a request to all workers and sums the number of updated sessions
+func (f *farm) broadcast(c *gin.Context) {
+	body, err := ioutil.ReadAll(c.Request.Body)
+	if err != nil {
+		c.String(http.StatusBadRequest, err.Error())
+		return
+	}
+	total := 0
+	for _, w := range f.workers {
+		req, err := http.NewRequest(c.Request.Method, "http://"+w.addr+c.Request.URL.RequestURI(), bytes.NewReader(body))
+		if err != nil {
+			c.String(http.StatusBadRequest, err.Error())
+			return
+		}
+		req.Header.Set("Content-Type", c.GetHeader("Content-Type"))
+		req.Header.Set("Authorization", c.GetHeader("Authorization"))
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			log.Printf("Worker %s unavailable: %v", w.addr, err)
+			continue
+		}
+		data, _ := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusAccepted {
+			// all workers validate the same way, so the first error is returned
+			c.Data(resp.StatusCode, resp.Header.Get("Content-Type"), data)
+			return
+		}
+		var result struct {
+			Sessions int `json:"sessions"`
+		}
+		json.Unmarshal(data, &result)
+		total += result.Sessions
+	}
+	c.JSON(http.StatusAccepted, gin.H{"sessions": total})
+}
+
+// lookup forwards a request about a single session to all workers until one knows the session
+func (f *farm) lookup(c *gin.Context) {
+	for _, w := range f.workers {
+		req, err := http.NewRequest(c.Request.Method, "http://"+w.addr+c.Request.URL.RequestURI(), nil)
+		if err != nil {
+			c.String(http.StatusBadRequest, err.Error())
+			return
+		}
+		req.Header.Set("Authorization", c.GetHeader("Authorization"))
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			log.Printf("Worker %s unavailable: %v", w.addr, err)
+			continue
+		}
+		data, _ := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusNotFound {
+			c.Data(resp.StatusCode, resp.Header.Get("Content-Type"), data)
+			return
+		}
+	}
+	id := c.Query("session")
+	if id == "" {
+		id = c.Param("id")
+	}
+	c.String(http.StatusNotFound, "unknown session %s", id)
+}
+
+// listSessions merges the session lists of all workers
+func (f *farm) listSessions(c *gin.Context) {
+	all := []json.RawMessage{}
+	for _, w := range f.workers {
+		req, err := http.NewRequest(http.MethodGet, "http://"+w.addr+c.Request.URL.RequestURI(), nil)
+		if err != nil {
+			c.String(http.StatusBadRequest, err.Error())
+			return
+		}
+		req.Header.Set("Authorization", c.GetHeader("Authorization"))
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			log.Printf("Worker %s unavailable: %v", w.addr, err)
+			continue
+		}
+		var list []json.RawMessage
+		err = json.NewDecoder(resp.Body).Decode(&list)
+		resp.Body.Close()
+		if err != nil {
+			log.Printf("Worker %s sent invalid sessions: %v", w.addr, err)
+			continue
+		}
+		all = append(all, list...)
+	}
+	c.JSON(http.StatusOK, all)
+}
+
+// proxy forwards a request to the least loaded worker
+func (f *farm) proxy(c *gin.Context) {
+	w := f.acquire("")
+	defer f.release(w, "")
+	httputil.NewSingleHostReverseProxy(&url.URL{Scheme: "http", Host: w.addr}).ServeHTTP(c.Writer, c.Request)
+}
+
+// metrics serves sessions and restarts per worker in the Prometheus text format
+func (f *farm) metrics(c *gin.Context) {
+	c.Header("Content-Type", "text/plain; version=0.0.4")
+	c.Status(http.StatusOK)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	fmt.Fprintln(c.Writer, "# HELP webg3n_worker_sessions Running sessions per worker.")
+	fmt.Fprintln(c.Writer, "# TYPE webg3n_worker_sessions gauge")
+	for _, w := range f.workers {
+		fmt.Fprintf(c.Writer, "webg3n_worker_sessions{worker=%q,gpu=%q} %d\n", w.addr, w.gpu, w.sessions)
+	}
+	placement := f.gpus.placement()
+	fmt.Fprintln(c.Writer, "# HELP webg3n_gpu_sessions Running sessions per GPU.")
+	fmt.Fprintln(c.Writer, "# TYPE webg3n_gpu_sessions gauge")
+	for _, d := range f.gpus.devices {
+		fmt.Fprintf(c.Writer, "webg3n_gpu_sessions{gpu=%q} %d\n", d, placement[d])
+	}
+	fmt.Fprintln(c.Writer, "# HELP webg3n_worker_restarts_total Restarts of crashed workers.")
+	fmt.Fprintln(c.Writer, "# TYPE webg3n_worker_restarts_total counter")
+	for _, w := range f.workers {
+		fmt.Fprintf(c.Writer, "webg3n_worker_restarts_total{worker=%q,gpu=%q} %d\n", w.addr, w.gpu, w.restarts)
+	}
+}
+
+// forwardHangup passes SIGHUP on to all workers, so they reload their config
+func (f *farm) forwardHangup() {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	for range hup {
+		f.mu.Lock()
+		for _, w := range f.workers {
+			if w.cmd != nil {
+				w.cmd.Process.Signal(syscall.SIGHUP)
+			}
+		}
+		f.mu.Unlock()
+	}
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestFarmPlacement(t *testing.T) {
+	f := newFarm(3, 9001, newGPUScheduler("0,1"))
+	if f.workers[0].gpu != "0" || f.workers[1].gpu != "1" || f.workers[2].gpu != "0" {
+		t.Error("GPUs not assigned round robin")
+	}
+	a, b := f.acquire(""), f.acquire("")
+	if a == b {
+		t.Error("sessions not balanced")
+	}
+	f.release(a, "")
+	if f.acquire("") != a {
+		t.Error("session not placed on least loaded worker")
+	}
+	c := f.acquire("")
+	if c == a || c == b {
+		t.Error("session not placed on idle worker:", c.addr)
+	}
+	// all workers run a session, the second GPU has the fewest
+	if d := f.acquire(""); d != b {
+		t.Error("session not placed on least loaded GPU:", d.addr)
+	}
+	if p := f.gpus.placement(); p["0"] != 2 || p["1"] != 2 {
+		t.Error("wrong GPU placement:", p)
+	}
+	if f := newFarm(1, 9001, newGPUScheduler("0,1")); len(f.gpus.devices) != 1 {
+		t.Error("GPU without worker kept:", f.gpus.devices)
+	}
+}
+
+func TestFarmRoomPlacement(t *testing.T) {
+	f := newFarm(2, 9001, newGPUScheduler(""))
+	a := f.acquire("review")
+	f.acquire("")
+	f.acquire("")
+	if f.acquire("review") != a {
+		t.Error("room participants placed on different workers")
+	}
+	f.release(a, "review")
+	f.release(a, "review")
+	if len(a.rooms) != 0 {
+		t.Error("room not released:", a.rooms)
+	}
+}
+
+func TestWorkerArgs(t *testing.T) {
+	args := workerArgs([]string{"-workers", "2", "-quality", "low"}, &worker{addr: "127.0.0.1:9001", gpu: "1"})
+	if strings.Join(args, " ") != "-workers 2 -quality low -workers=0 -addr=127.0.0.1:9001 -gpus=1 -webtransport-addr=" {
+		t.Error("wrong worker args:", args)
+	}
+}
+
+func TestFarmBroadcast(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+		w.Write([]byte(`{"sessions": 2}`))
+	}))
+	defer backend.Close()
+	f := &farm{workers: []*worker{{addr: backend.Listener.Addr().String()}, {addr: backend.Listener.Addr().String()}}}
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/patch", f.broadcast)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("POST", "/patch", strings.NewReader("[]")))
+	if rec.Code != http.StatusAccepted || rec.Body.String() != `{"sessions":4}` {
+		t.Error("wrong broadcast result:", rec.Code, rec.Body.String())
+	}
+}
+
+func TestFarmLookup(t *testing.T) {
+	missing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer missing.Close()
+	found := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"model":"` + r.URL.Query().Get("session") + `"}`))
+	}))
+	defer found.Close()
+	f := &farm{workers: []*worker{{addr: missing.Listener.Addr().String()}, {addr: found.Listener.Addr().String()}}}
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/scenegraph", f.lookup)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("GET", "/scenegraph?session=abc", nil))
+	if rec.Code != http.StatusOK || rec.Body.String() != `{"model":"abc"}` {
+		t.Error("wrong lookup result:", rec.Code, rec.Body.String())
+	}
+
+	f.workers = f.workers[:1]
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("GET", "/scenegraph?session=abc", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Error("unknown session found:", rec.Code)
+	}
+}
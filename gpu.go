@@ -0,0 +1,85 @@
+package main
+
+import (
+	"os"
+	"strings"
+	"sync"
+)
+
+// gpuScheduler places sessions on the least loaded of several GPUs.
+// GL contexts of a process are all created on one device, so sessions are balanced across the GPUs of the farm workers.
+type gpuScheduler struct {
+	mu      sync.Mutex
+	devices []string
+	load    []int // running sessions per device
+}
+
+// newGPUScheduler creates a scheduler for a comma separated list of devices,
+// an empty list is a single default device
+func newGPUScheduler(devices string) *gpuScheduler {
+	s := &gpuScheduler{}
+	for _, d := range strings.Split(devices, ",") {
+		if d = strings.TrimSpace(d); d != "" {
+			s.devices = append(s.devices, d)
+		}
+	}
+	if len(s.devices) == 0 {
+		s.devices = []string{"default"}
+	}
+	s.load = make([]int, len(s.devices))
+	return s
+}
+
+// leastLoaded returns the index of the device with the fewest sessions
+func (s *gpuScheduler) leastLoaded() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	best := 0
+	for i, load := range s.load {
+		if load < s.load[best] {
+			best = i
+		}
+	}
+	return best
+}
+
+// assign places a session on a device
+func (s *gpuScheduler) assign(device int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.load[device]++
+}
+
+// release removes a session from a device
+func (s *gpuScheduler) release(device int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.load[device] > 0 {
+		s.load[device]--
+	}
+}
+
+// name returns the name of a device
+func (s *gpuScheduler) name(device int) string {
+	return s.devices[device]
+}
+
+// placement returns the number of sessions by device name
+func (s *gpuScheduler) placement() map[string]int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p := make(map[string]int, len(s.devices))
+	for i, d := range s.devices {
+		p[d] = s.load[i]
+	}
+	return p
+}
+
+// selectGPU makes the GL contexts of this process be created on a device by setting the environment variable
+// read by the GL driver, e.g. DRI_PRIME of Mesa. It has to run before the first session opens a window.
+func selectGPU(device string, env string) error {
+	if device == "default" || env == "" {
+		return nil
+	}
+	return os.Setenv(env, device)
+}
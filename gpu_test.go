@@ -0,0 +1,48 @@
+package main
+
+import (
+	"os"
+	"testing"
+)
+
+func TestGPUScheduler(t *testing.T) {
+	s := newGPUScheduler("0, 1")
+	var placed []int
+	for i := 0; i < 3; i++ {
+		d := s.leastLoaded()
+		s.assign(d)
+		placed = append(placed, d)
+	}
+	if placed[0] != 0 || placed[1] != 1 || placed[2] != 0 {
+		t.Error("sessions not balanced:", placed)
+	}
+	s.release(placed[0])
+	s.release(placed[2])
+	if d := s.leastLoaded(); d != 0 {
+		t.Error("session not placed on least loaded device:", d)
+	}
+	s.assign(0)
+	p := s.placement()
+	if p["0"] != 1 || p["1"] != 1 {
+		t.Error("wrong placement:", p)
+	}
+}
+
+func TestGPUSchedulerDefault(t *testing.T) {
+	s := newGPUScheduler("")
+	if s.name(s.leastLoaded()) != "default" {
+		t.Error("missing default device")
+	}
+}
+
+func TestSelectGPU(t *testing.T) {
+	defer os.Unsetenv("WEBG3N_TEST_GPU")
+	selectGPU("default", "WEBG3N_TEST_GPU")
+	if _, set := os.LookupEnv("WEBG3N_TEST_GPU"); set {
+		t.Error("default GPU selected by environment")
+	}
+	selectGPU("1", "WEBG3N_TEST_GPU")
+	if os.Getenv("WEBG3N_TEST_GPU") != "1" {
+		t.Error("GPU not selected")
+	}
+}
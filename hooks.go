@@ -0,0 +1,115 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// Events sent to the session hook
+const (
+	hookSessionRequested = "session.requested"
+	hookSessionEnded     = "session.ended"
+)
+
+// hookEvent is the JSON passed to the session hook
+type hookEvent struct {
+	Event    string  `json:"event"`
+	Session  string  `json:"session"`
+	Client   string  `json:"client"`
+	Model    string  `json:"model"`
+	Width    int     `json:"width,omitempty"`
+	Height   int     `json:"height,omitempty"`
+	Duration float64 `json:"duration,omitempty"` // seconds, only set when the session ended
+}
+
+// hookDecision is the optional JSON answer to a session request.
+// An empty decision runs the session on this server.
+type hookDecision struct {
+	Redirect string `json:"redirect,omitempty"` // websocket URL the client reconnects to
+	Reject   string `json:"reject,omitempty"`   // reason the session is refused
+}
+
+// sessionHook lets an orchestrator decide where sessions run.
+// URLs get the event posted, any other value is run as command with the event on stdin.
+type sessionHook struct {
+	target  string
+	timeout time.Duration
+}
+
+// endEvent returns the session.ended event of a requested session
+func endEvent(requested hookEvent, duration time.Duration) hookEvent {
+	ended := requested
+	ended.Event = hookSessionEnded
+	ended.Duration = duration.Seconds()
+	return ended
+}
+
+// call passes an event to the hook and returns its decision
+func (h sessionHook) call(event hookEvent) (hookDecision, error) {
+	var decision hookDecision
+	if h.target == "" {
+		return decision, nil
+	}
+	body, err := json.Marshal(event)
+	if err != nil {
+		return decision, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), h.timeout)
+	defer cancel()
+
+	var out []byte
+	if strings.HasPrefix(h.target, "http://") || strings.HasPrefix(h.target, "https://") {
+		out, err = h.post(ctx, body)
+	} else {
+		out, err = h.exec(ctx, body)
+	}
+	if err != nil {
+		return decision, err
+	}
+	if len(bytes.TrimSpace(out)) == 0 {
+		return decision, nil
+	}
+	if err := json.Unmarshal(out, &decision); err != nil {
+		return decision, fmt.Errorf("invalid hook response: %v", err)
+	}
+	return decision, nil
+}
+
+// post sends the event to the hook URL and returns the response body
+func (h sessionHook) post(ctx context.Context, body []byte) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.target, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	var out bytes.Buffer
+	if _, err := out.ReadFrom(resp.Body); err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("hook returned %s", resp.Status)
+	}
+	return out.Bytes(), nil
+}
+
+// exec runs the hook command with the event on stdin and returns its output
+func (h sessionHook) exec(ctx context.Context, body []byte) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "sh", "-c", h.target)
+	cmd.Stdin = bytes.NewReader(body)
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("hook command: %v", err)
+	}
+	return out, nil
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSessionHookURL(t *testing.T) {
+	var got hookEvent
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&got)
+		w.Write([]byte(`{"redirect":"wss://gpu-pod-1/webg3n"}`))
+	}))
+	defer srv.Close()
+
+	h := sessionHook{target: srv.URL, timeout: time.Second}
+	d, err := h.call(hookEvent{Event: hookSessionRequested, Session: "s1", Model: "Cathedral.glb"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Redirect != "wss://gpu-pod-1/webg3n" {
+		t.Error("wrong redirect:", d.Redirect)
+	}
+	if got.Event != hookSessionRequested || got.Session != "s1" || got.Model != "Cathedral.glb" {
+		t.Error("wrong event:", got)
+	}
+}
+
+func TestSessionHookCommand(t *testing.T) {
+	h := sessionHook{target: `grep -q session.requested && echo '{"reject":"no capacity"}'`, timeout: time.Second}
+	d, err := h.call(hookEvent{Event: hookSessionRequested})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Reject != "no capacity" {
+		t.Error("wrong rejection:", d.Reject)
+	}
+	// a command without output keeps the session local
+	h.target = "cat > /dev/null"
+	if d, err := h.call(hookEvent{Event: hookSessionEnded}); err != nil || d != (hookDecision{}) {
+		t.Error("unexpected decision:", d, err)
+	}
+}
+
+func TestEndEvent(t *testing.T) {
+	requested := hookEvent{Event: hookSessionRequested, Session: "s1", Model: "Cathedral.glb"}
+	ended := endEvent(requested, 1500*time.Millisecond)
+	if ended.Event != hookSessionEnded || ended.Session != "s1" || ended.Model != "Cathedral.glb" || ended.Duration != 1.5 {
+		t.Error("wrong event:", ended)
+	}
+}
+
+func TestSessionHookFailure(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+	h := sessionHook{target: srv.URL, timeout: time.Second}
+	if _, err := h.call(hookEvent{}); err == nil {
+		t.Error("missing error")
+	}
+	h.target = "exit 1"
+	if _, err := h.call(hookEvent{}); err == nil {
+		t.Error("missing error")
+	}
+	h.target = ""
+	if _, err := h.call(hookEvent{}); err != nil {
+		t.Error(err)
+	}
+}
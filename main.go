@@ -4,6 +4,7 @@ import (
 	"context"
 	"flag"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -12,37 +13,238 @@ import (
 	"time"
 
 	"github.com/g3n/engine/window"
+	"github.com/moethu/webg3n/renderer"
 
 	"github.com/gin-gonic/gin"
 	"github.com/gorilla/websocket"
 )
 
+var (
+	configFile     = flag.String("config", "", "YAML file with flag values, flags and WEBG3N_* environment variables take precedence")
+	addr           = flag.String("addr", ":8000", "address the HTTP server listens on")
+	modelDir       = flag.String("model-dir", "models", "directory of local models")
+	defaultModel   = flag.String("default-model", "Cathedral.glb", "model of sessions not requesting one")
+	defaultWidth   = flag.Int("width", 800, "image width of sessions not requesting one")
+	defaultHeight  = flag.Int("height", 800, "image height of sessions not requesting one")
+	encoder        = flag.String("encoder", "libjpeg", "default image encoder (libjpeg, progressive, jpeg, png, png-interlaced)")
+	quality        = flag.String("quality", "high", "default image quality preset (high, medium, low)")
+	maxSessions    = flag.Int("max-sessions", 0, "maximum number of concurrent sessions, 0 disables the limit")
+	maxBandwidth   = flag.Int64("max-bandwidth", 0, "kilobytes per second a session may stream, frames get degraded to stay below, 0 disables the limit")
+	drainTimeout   = flag.Duration("drain-timeout", 10*time.Second, "time to wait for running sessions to close on shutdown")
+	sessionTTL     = flag.Duration("session-ttl", 0, "maximum lifetime of a session, 0 disables the limit")
+	idleTimeout    = flag.Duration("idle-timeout", 0, "evict sessions without client activity, 0 disables the limit")
+	evictWarning   = flag.Duration("evict-warning", 30*time.Second, "time before eviction a client gets warned")
+	logLevel       = flag.String("log-level", "debug", "minimum session log level (debug, info, warn, error)")
+	logJSON        = flag.Bool("log-json", false, "write session logs as JSON")
+	auditDir       = flag.String("audit-dir", "", "directory of append-only audit logs of the commands of each session, empty disables auditing")
+	journalDir     = flag.String("journal-dir", "", "directory of journals of scene commands replayed when a client reconnects, empty disables journaling")
+	journalTTL     = flag.Duration("journal-ttl", 24*time.Hour, "remove journals not written for this time at startup")
+	modelScale     = flag.Float64("scale", 1.0, "scale factor applied to all models at load")
+	modelUnit      = flag.String("unit", "m", "unit of models without unit configuration (mm, cm, m, ft-in)")
+	maxTriangles   = flag.Int("max-triangles", 0, "simplify meshes with more triangles at load, 0 disables simplification")
+	tolerance      = flag.Float64("decimate-tolerance", 0, "simplification error in model units, 0 derives it from max-triangles")
+	maxTexture     = flag.Int("max-texture-size", 4096, "downscale larger textures at load, 0 disables downscaling")
+	modelCache     = flag.Int64("model-cache", 512, "memory in MB for model files and their geometry shared by all sessions, 0 disables the cache")
+	progressive    = flag.Bool("progressive-textures", false, "show models untextured first and stream textures in the background")
+	watchModels    = flag.Bool("watch", false, "reload the scene of running sessions when their model file changes")
+	maxModelSize   = flag.Int64("max-model-size", 512, "maximum size of downloaded models in MB")
+	memoryBudget   = flag.Int64("memory-budget", 0, "memory in MB for geometry and textures per session, meshes out of view are swapped to disk, 0 disables the budget")
+	ipd            = flag.Float64("ipd", 64, "interpupillary distance of stereo rendering in millimeters")
+	zoomSpeed      = flag.Float64("zoom-sensitivity", 1, "zoom per mouse wheel step, lower values suit trackpads")
+	naturalScroll  = flag.Bool("natural-scrolling", false, "invert the zoom direction of the mouse wheel")
+	minPolar       = flag.Float64("min-polar-angle", 0, "minimum camera angle in degrees from straight above the target")
+	maxPolar       = flag.Float64("max-polar-angle", 180, "maximum camera angle in degrees from straight above the target, 90 keeps the camera above the ground")
+	minDistance    = flag.Float64("min-distance", 0, "minimum camera distance to its target in model units")
+	maxDistance    = flag.Float64("max-distance", 0, "maximum camera distance to its target in model units, 0 disables the limit")
+	panBounds      = flag.Float64("pan-bounds", 0, "keep the camera target within the model bounds grown by this fraction of their size, 0 disables the bounds")
+	gpuList        = flag.String("gpus", "", "comma separated GPUs the workers of -workers are balanced across, or the GPU of a single process")
+	workerCount    = flag.Int("workers", 0, "run sessions in this many worker processes behind a dispatcher, 0 runs them in process")
+	workerPort     = flag.Int("worker-port", 9001, "first local port of worker processes")
+	gpuEnv         = flag.String("gpu-env", "DRI_PRIME", "environment variable the GL driver reads the GPU of -gpus from")
+	gpuPicking     = flag.Bool("gpu-picking", false, "select clicked nodes by an object id render pass instead of raycasting")
+	linear         = flag.Bool("linear", false, "render in linear color space and convert frames to sRGB or the gamma of the session when encoding")
+	hookTarget     = flag.String("session-hook", "", "URL or command asked where requested sessions run and told when they end")
+	hookTimeout    = flag.Duration("hook-timeout", 30*time.Second, "time to wait for the session hook")
+	watermarkLogo  = flag.String("watermark-logo", "", "png or jpeg logo drawn onto snapshots and recordings")
+	watermarkAlpha = flag.Float64("watermark-opacity", 0.5, "opacity of the watermark logo from 0 to 1")
+	caption        = flag.String("watermark-caption", "", "caption of snapshots and recordings, {model}, {time} and {user} are replaced")
+	debugToken     = flag.String("debug-token", "", "serve pprof profiles and execution traces at /debug/pprof/ to requests with this bearer token, empty disables them")
+	adminToken     = flag.String("admin-token", "", "serve the session admin API at /admin/ to requests with this bearer token, empty disables it")
+	apiToken       = flag.String("api-token", "", "serve /patch, /values, /fields, /clashes, /scenegraph and /convert to requests with this bearer token, empty disables them")
+	compression    = flag.Bool("compression", true, "negotiate permessage-deflate for JSON messages, image frames are sent uncompressed")
+	wtAddr         = flag.String("webtransport-addr", "", "UDP address serving sessions over WebTransport (HTTP/3) besides websockets, empty disables it")
+	tlsCert        = flag.String("tls-cert", "", "certificate file of -webtransport-addr")
+	tlsKey         = flag.String("tls-key", "", "key file of -webtransport-addr")
+)
+
+// modelSourceFlag collects repeated -model-source flags
+type modelSourceFlag struct{}
+
+func (modelSourceFlag) String() string { return "" }
+
+func (modelSourceFlag) Set(value string) error {
+	renderer.ModelSources = append(renderer.ModelSources, value)
+	return nil
+}
+
+// webhookFlag collects repeated -webhook flags
+type webhookFlag struct{}
+
+func (webhookFlag) String() string { return "" }
+
+func (webhookFlag) Set(value string) error {
+	renderer.Webhooks = append(renderer.Webhooks, renderer.ParseWebhook(value))
+	return nil
+}
+
+func init() {
+	flag.Var(webhookFlag{}, "webhook", "URL receiving viewer events, optionally filtered by url#event1,event2 (repeatable)")
+	flag.Var(modelSourceFlag{}, "model-source", "URL remote models may be loaded from below, e.g. s3://bucket/models/ (repeatable)")
+}
+
 func main() {
 	flag.Parse()
 	log.SetFlags(0)
 
+	// flags given on the command line are kept when the config gets reloaded
+	commandLine := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) { commandLine[f.Name] = true })
+	if err := loadConfig(flag.CommandLine, configPath(*configFile), os.Getenv); err != nil {
+		log.Fatal(err)
+	}
+	if err := applySettings(); err != nil {
+		log.Fatal(err)
+	}
+	renderer.LogJSON = *logJSON
+	renderer.AuditDir = *auditDir
+	renderer.JournalDir = *journalDir
+	if err := renderer.LoadJournalSecret(); err != nil {
+		log.Fatalf("loading journal secret failed: %v", err)
+	}
+	if removed, err := renderer.PruneJournals(*journalTTL); err != nil {
+		log.Printf("pruning journals failed: %v", err)
+	} else if removed > 0 {
+		log.Printf("removed %d expired journals", removed)
+	}
+
+	if !renderer.IsUnit(*modelUnit) {
+		log.Fatalf("invalid unit: %s", *modelUnit)
+	}
+	renderer.DefaultUnit = *modelUnit
+	renderer.ModelScale = float32(*modelScale)
+	renderer.ModelCacheSize = *modelCache << 20
+	renderer.MaxModelSize = *maxModelSize << 20
+	renderer.MemoryBudget = *memoryBudget << 20
+	renderer.WatchModels = *watchModels
+	renderer.DefaultIPD = float32(*ipd)
+	if *zoomSpeed <= 0 {
+		log.Fatalf("invalid zoom-sensitivity: %v", *zoomSpeed)
+	}
+	renderer.DefaultZoomSensitivity = float32(*zoomSpeed)
+	renderer.DefaultNaturalScrolling = *naturalScroll
+	limits := renderer.OrbitLimits{
+		MinPolarAngle: float32(*minPolar), MaxPolarAngle: float32(*maxPolar),
+		MinDistance: float32(*minDistance), MaxDistance: float32(*maxDistance),
+		PanBounds: float32(*panBounds),
+	}
+	if err := limits.Validate(); err != nil {
+		log.Fatalf("invalid orbit limits: %v", err)
+	}
+	renderer.DefaultOrbitLimits = limits
+	renderer.GPUPicking = *gpuPicking
+	renderer.LinearWorkflow = *linear
+	renderer.PresenceHandler = rooms.update
+	renderer.ChatHandler = rooms.chat
+	watermark, err := loadWatermark(*watermarkLogo, float32(*watermarkAlpha), *caption)
+	if err != nil {
+		log.Fatal(err)
+	}
+	renderer.DefaultWatermark = watermark
+
+	if *wtAddr != "" && (*tlsCert == "" || *tlsKey == "") {
+		log.Fatal("-webtransport-addr needs -tls-cert and -tls-key")
+	}
+	gpus = newGPUScheduler(*gpuList)
+	if *workerCount == 0 {
+		if len(gpus.devices) > 1 {
+			log.Fatal("several GPUs need -workers, all GL contexts of a process are created on one GPU")
+		}
+		if err := selectGPU(gpus.name(0), *gpuEnv); err != nil {
+			log.Fatalf("selecting GPU failed: %v", err)
+		}
+	}
+	hook = sessionHook{target: *hookTarget, timeout: *hookTimeout}
+	upgrader.EnableCompression = *compression
+
 	router := gin.Default()
-	port := ":8000"
 	srv := &http.Server{
-		Addr:         port,
+		Addr:         *addr,
 		Handler:      router,
 		ReadTimeout:  600 * time.Second,
 		WriteTimeout: 600 * time.Second,
 	}
 
 	router.Static("/static/", "./static/")
-	router.Any("/webg3n", serveWebsocket)
 	router.GET("/", home)
-	log.Println("Starting HTTP Server on Port 8000")
+	if *debugToken != "" {
+		registerDebug(router, *debugToken)
+	}
+
+	// in farm mode sessions run in worker processes, which are started with the same flags
+	var workers *farm
+	if *workerCount > 0 {
+		workers = newFarm(*workerCount, *workerPort, gpus)
+		workers.start(os.Args[1:])
+		router.Any("/webg3n", workers.serveWebsocket)
+		router.GET("/metrics", workers.metrics)
+		if *apiToken != "" {
+			api := router.Group("/", requireToken(*apiToken))
+			api.POST("/patch", workers.broadcast)
+			api.POST("/values", workers.broadcast)
+			api.POST("/fields", workers.broadcast)
+			api.POST("/clashes", workers.broadcast)
+			api.GET("/scenegraph", workers.lookup)
+			api.POST("/convert", workers.proxy)
+		}
+		go workers.forwardHangup()
+	} else {
+		router.Any("/webg3n", serveWebsocket)
+		router.GET("/metrics", metrics)
+		if *apiToken != "" {
+			// these change or reveal the scenes of all sessions
+			api := router.Group("/", requireToken(*apiToken))
+			api.POST("/patch", patchScene)
+			api.POST("/values", bindValues)
+			api.POST("/fields", setField)
+			api.POST("/clashes", setClashes)
+			api.GET("/scenegraph", sceneGraph)
+			api.POST("/convert", convertModel)
+		}
+		go sessions.Evict(*sessionTTL, *idleTimeout, *evictWarning)
+		go reloadOnHangup(configPath(*configFile), commandLine)
+	}
+	if *adminToken != "" {
+		registerAdmin(router, *adminToken, workers)
+	}
+	log.Printf("Starting HTTP Server on %s", *addr)
 
 	go func() {
 		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 			log.Fatalf("listen: %s\n", err)
 		}
 	}()
+	if *wtAddr != "" {
+		webTransportServer = newWebTransportServer(*wtAddr, router)
+		log.Printf("Starting WebTransport Server on %s", *wtAddr)
+		go func() {
+			if err := webTransportServer.ListenAndServeTLS(*tlsCert, *tlsKey); err != nil && err != http.ErrServerClosed {
+				log.Fatalf("webtransport: %s\n", err)
+			}
+		}()
+	}
 
 	// Wait for interrupt signal to gracefully shutdown the server with
-	// a timeout of 5 seconds.
+	// a timeout of drainTimeout.
 	quit := make(chan os.Signal, 1)
 	// kill (no param) default send syscanll.SIGTERM
 	// kill -2 is syscall.SIGINT
@@ -50,21 +252,64 @@ func main() {
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 	log.Println("Shutdown Server")
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), *drainTimeout)
 	defer cancel()
 
+	// stop accepting new sessions and wait for running ones to close
+	if workers != nil {
+		if err := workers.stop(ctx); err != nil {
+			log.Println("Worker shutdown: ", err)
+		}
+	} else if err := sessions.Drain(ctx); err != nil {
+		log.Println("Session drain: ", err)
+	}
+
 	if err := srv.Shutdown(ctx); err != nil {
-		log.Fatal("Server Shutdown: ", err)
+		log.Println("Server Shutdown: ", err)
+	}
+	if webTransportServer != nil {
+		webTransportServer.Close()
 	}
 
-	window.DestroyGlfwManager()
+	if workers == nil {
+		window.DestroyGlfwManager()
+	}
 	log.Println("Server exiting")
 }
 
 var upgrader = websocket.Upgrader{}
 
+var sessions = NewSessionManager()
+
+var gpus = newGPUScheduler("")
+
+var rooms = newRoomManager()
+
+var hook sessionHook
+
 // Home route, loading template and serving it
 func home(c *gin.Context) {
 	viewertemplate := template.Must(template.ParseFiles("templates/webg3n.html"))
-	viewertemplate.Execute(c.Writer, c.Request.Host)
+	viewertemplate.Execute(c.Writer, viewerPage{Host: c.Request.Host, WebTransport: webTransportURL(c.Request.Host, *wtAddr)})
+}
+
+// viewerPage holds the addresses the viewer connects to
+type viewerPage struct {
+	Host         string
+	WebTransport string // empty if disabled
+}
+
+// webTransportURL returns the WebTransport URL of sessions on the host of a page, empty if WebTransport is disabled
+func webTransportURL(host string, addr string) string {
+	if addr == "" {
+		return ""
+	}
+	_, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return ""
+	}
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return "https://" + net.JoinHostPort(host, port) + "/webg3n"
 }
@@ -0,0 +1,19 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// metrics serves server metrics in the Prometheus text format
+func metrics(c *gin.Context) {
+	c.Header("Content-Type", "text/plain; version=0.0.4")
+	c.Status(http.StatusOK)
+	w := c.Writer
+
+	fmt.Fprintln(w, "# HELP webg3n_sessions Running sessions.")
+	fmt.Fprintln(w, "# TYPE webg3n_sessions gauge")
+	fmt.Fprintf(w, "webg3n_sessions %d\n", sessions.count())
+}
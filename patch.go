@@ -0,0 +1,133 @@
+package main
+
+import (
+	"encoding/binary"
+	"io/ioutil"
+	"math"
+	"net/http"
+
+	"github.com/moethu/webg3n/renderer"
+
+	"github.com/gin-gonic/gin"
+)
+
+// patchScene applies node patches of an external feed to running sessions without reloading them.
+// The body is a JSON list of patches, the session or model query parameter selects the sessions,
+// by default all sessions are patched.
+func patchScene(c *gin.Context) {
+	var patches []renderer.NodePatch
+	if err := c.ShouldBindJSON(&patches); err != nil {
+		c.String(http.StatusBadRequest, "invalid patch: %v", err)
+		return
+	}
+	for _, p := range patches {
+		if err := p.Validate(); err != nil {
+			c.String(http.StatusBadRequest, "invalid patch for node %s: %v", p.Node, err)
+			return
+		}
+	}
+	clients := sessions.clients(c.Query("session"), c.Query("model"))
+	for _, client := range clients {
+		client.app.Patch(patches)
+	}
+	c.JSON(http.StatusAccepted, gin.H{"sessions": len(clients)})
+}
+
+// bindValues colors nodes of running sessions by live values.
+// The body is a JSON object of node ids and values, sessions are selected like for patchScene.
+// The optional ramp query parameter sets the color ramp, e.g. 0:blue,50:yellow,100:red
+func bindValues(c *gin.Context) {
+	var values map[string]float32
+	if err := c.ShouldBindJSON(&values); err != nil {
+		c.String(http.StatusBadRequest, "invalid values: %v", err)
+		return
+	}
+	var ramp renderer.ColorRamp
+	if c.Query("ramp") != "" {
+		var err error
+		if ramp, err = renderer.ParseColorRamp(c.Query("ramp")); err != nil {
+			c.String(http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	clients := sessions.clients(c.Query("session"), c.Query("model"))
+	for _, client := range clients {
+		client.app.BindValues(values, ramp)
+	}
+	c.JSON(http.StatusAccepted, gin.H{"sessions": len(clients)})
+}
+
+// scalarField is the JSON body of setField
+type scalarField struct {
+	Name   string    `json:"name"`
+	Node   string    `json:"node"`
+	Values []float32 `json:"values"`
+}
+
+// setField uploads per-vertex values of a scalar field for a mesh node, shown by the Heatmap command.
+// The body is JSON or, with content type application/octet-stream, little endian float32 values
+// with name and node as query parameters. Sessions are selected like for patchScene.
+func setField(c *gin.Context) {
+	var field scalarField
+	if c.ContentType() == "application/octet-stream" {
+		data, err := ioutil.ReadAll(c.Request.Body)
+		if err != nil || len(data)%4 != 0 {
+			c.String(http.StatusBadRequest, "invalid field values")
+			return
+		}
+		field.Name, field.Node = c.Query("name"), c.Query("node")
+		field.Values = make([]float32, len(data)/4)
+		for i := range field.Values {
+			field.Values[i] = math.Float32frombits(binary.LittleEndian.Uint32(data[i*4:]))
+		}
+	} else if err := c.ShouldBindJSON(&field); err != nil {
+		c.String(http.StatusBadRequest, "invalid field: %v", err)
+		return
+	}
+	if field.Name == "" || field.Node == "" || len(field.Values) == 0 {
+		c.String(http.StatusBadRequest, "a field needs a name, a node and values")
+		return
+	}
+	clients := sessions.clients(c.Query("session"), c.Query("model"))
+	for _, client := range clients {
+		client.app.SetField(field.Name, field.Node, field.Values)
+	}
+	c.JSON(http.StatusAccepted, gin.H{"sessions": len(clients)})
+}
+
+// sceneGraph sends the scene graph of a running session selected by the session query parameter as JSON
+func sceneGraph(c *gin.Context) {
+	id := c.Query("session")
+	clients := sessions.clients(id, "")
+	if id == "" || len(clients) == 0 {
+		c.String(http.StatusNotFound, "unknown session %s", id)
+		return
+	}
+	graph, ok := clients[0].app.SceneGraph()
+	if !ok {
+		c.String(http.StatusNotFound, "session %s has ended", id)
+		return
+	}
+	c.JSON(http.StatusOK, graph)
+}
+
+// setClashes replaces the clashes of running sessions, e.g. from a coordination tool.
+// The body is a JSON list of node id pairs, an empty list removes all clashes. Sessions are selected like for patchScene.
+func setClashes(c *gin.Context) {
+	var clashes []renderer.Clash
+	if err := c.ShouldBindJSON(&clashes); err != nil {
+		c.String(http.StatusBadRequest, "invalid clashes: %v", err)
+		return
+	}
+	for _, clash := range clashes {
+		if err := clash.Validate(); err != nil {
+			c.String(http.StatusBadRequest, "invalid clash %s: %v", clash.Name, err)
+			return
+		}
+	}
+	clients := sessions.clients(c.Query("session"), c.Query("model"))
+	for _, client := range clients {
+		client.app.SetClashes(clashes)
+	}
+	c.JSON(http.StatusAccepted, gin.H{"sessions": len(clients)})
+}
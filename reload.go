@@ -0,0 +1,128 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+
+	"github.com/moethu/webg3n/renderer"
+)
+
+// reloadable lists the settings a config reload applies to new sessions
+var reloadable = map[string]bool{
+	"log-level":            true,
+	"quality":              true,
+	"encoder":              true,
+	"max-sessions":         true,
+	"max-bandwidth":        true,
+	"width":                true,
+	"height":               true,
+	"max-triangles":        true,
+	"decimate-tolerance":   true,
+	"max-texture-size":     true,
+	"progressive-textures": true,
+}
+
+// settingsMutex guards flag values changed by config reloads
+var settingsMutex sync.RWMutex
+
+// textValue records a flag value without parsing it
+type textValue struct {
+	value string
+}
+
+func (v *textValue) String() string {
+	if v == nil {
+		return ""
+	}
+	return v.value
+}
+
+func (v *textValue) Set(value string) error {
+	v.value = value
+	return nil
+}
+
+// applySettings validates and applies all reloadable settings
+func applySettings() error {
+	level, err := renderer.ParseLogLevel(*logLevel)
+	if err != nil {
+		return err
+	}
+	if !renderer.IsEncoder(*encoder) {
+		return fmt.Errorf("invalid encoder: %s", *encoder)
+	}
+	if !renderer.IsQuality(*quality) {
+		return fmt.Errorf("invalid quality: %s", *quality)
+	}
+	if *maxBandwidth < 0 {
+		return fmt.Errorf("invalid max-bandwidth: %d", *maxBandwidth)
+	}
+	renderer.UpdateSettings(func() {
+		renderer.LogLevel = level
+		renderer.DefaultEncoder = *encoder
+		renderer.DefaultQuality = *quality
+		renderer.MaxBandwidth = *maxBandwidth << 10
+	})
+	sessions.setLimit(*maxSessions)
+	renderer.DefaultLoadOptions = renderer.LoadOptions{MaxTriangles: *maxTriangles, Tolerance: float32(*tolerance), MaxTexture: *maxTexture, Progressive: *progressive}
+	return nil
+}
+
+// reloadConfig reads the config file and environment again and applies reloadable settings,
+// flags given on the command line keep their value. Invalid settings leave the current ones in place.
+func reloadConfig(path string, commandLine map[string]bool) error {
+	fs := flag.NewFlagSet("reload", flag.ContinueOnError)
+	values := make(map[string]*textValue)
+	flag.VisitAll(func(f *flag.Flag) {
+		values[f.Name] = &textValue{value: f.DefValue}
+		fs.Var(values[f.Name], f.Name, f.Usage)
+	})
+	for name := range commandLine {
+		fs.Set(name, flag.Lookup(name).Value.String())
+	}
+	if err := loadConfig(fs, path, os.Getenv); err != nil {
+		return err
+	}
+
+	settingsMutex.Lock()
+	defer settingsMutex.Unlock()
+	previous := make(map[string]string)
+	for name := range reloadable {
+		previous[name] = flag.Lookup(name).Value.String()
+	}
+	restore := func() {
+		for name, value := range previous {
+			flag.Set(name, value)
+		}
+	}
+	for name := range reloadable {
+		if err := flag.Set(name, values[name].value); err != nil {
+			restore()
+			return fmt.Errorf("invalid %s: %v", name, err)
+		}
+	}
+	if err := applySettings(); err != nil {
+		restore()
+		applySettings()
+		return err
+	}
+	return nil
+}
+
+// reloadOnHangup reloads the config whenever the process receives SIGHUP
+func reloadOnHangup(path string, commandLine map[string]bool) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	for range hup {
+		if err := reloadConfig(path, commandLine); err != nil {
+			log.Println("Config reload: ", err)
+			continue
+		}
+		log.Println("Config reloaded")
+	}
+}
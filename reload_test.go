@@ -0,0 +1,41 @@
+package main
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestReloadConfig(t *testing.T) {
+	f, err := ioutil.TempFile("", "webg3n*.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	defer func() {
+		flag.Set("quality", "high")
+		flag.Set("max-sessions", "0")
+		flag.Set("width", "800")
+		applySettings()
+	}()
+
+	ioutil.WriteFile(f.Name(), []byte("quality: low\nmax-sessions: 3\nwidth: 1024\n"), 0644)
+	if err := reloadConfig(f.Name(), map[string]bool{"width": true}); err != nil {
+		t.Fatal(err)
+	}
+	if *quality != "low" || sessions.limit != 3 {
+		t.Error("reloaded settings not applied:", *quality, sessions.limit)
+	}
+	if *defaultWidth != 800 {
+		t.Error("command line flag changed by reload:", *defaultWidth)
+	}
+
+	ioutil.WriteFile(f.Name(), []byte("quality: ultra\nmax-sessions: 5\n"), 0644)
+	if err := reloadConfig(f.Name(), nil); err == nil {
+		t.Error("invalid quality accepted")
+	}
+	if *quality != "low" || sessions.limit != 3 {
+		t.Error("invalid reload changed settings:", *quality, sessions.limit)
+	}
+}
@@ -0,0 +1,153 @@
+package renderer
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"image"
+	"image/png"
+	"time"
+
+	"github.com/g3n/engine/math32"
+	uuid "github.com/satori/go.uuid"
+)
+
+// Annotation is an issue raised in the viewer with the view it was raised in
+type Annotation struct {
+	GUID      string      `json:"guid"`
+	Title     string      `json:"title"`
+	Created   time.Time   `json:"created"`
+	Camera    CameraState `json:"camera"`
+	Selection []string    `json:"selection"`
+	Snapshot  []byte      `json:"snapshot"` // png, base64 encoded in JSON
+}
+
+// CameraState is a perspective camera in world coordinates
+type CameraState struct {
+	Position  [3]float32 `json:"position"`
+	Direction [3]float32 `json:"direction"`
+	Up        [3]float32 `json:"up"`
+	Fov       float32    `json:"fov"` // vertical field of view in degrees
+}
+
+// annotationExport is a file with all annotations of a session sent to the client
+type annotationExport struct {
+	Name   string `json:"name"`
+	Format string `json:"format"`
+	File   string `json:"file"` // base64 encoded
+}
+
+// Annotate raises an issue titled by the value with the current camera, selection and frame
+func (app *RenderingApp) Annotate(cmd Command) {
+	if cmd.Val == "" {
+		app.sendMessageToClient("annotate", "missing title")
+		return
+	}
+	a := Annotation{
+		GUID:      uuid.NewV4().String(),
+		Title:     cmd.Val,
+		Created:   time.Now().UTC(),
+		Camera:    app.cameraState(),
+		Selection: app.selectedNames(),
+	}
+	// the snapshot is taken on the render thread, annotations are kept by the command goroutine
+	snapshot := make(chan []byte, 1)
+	app.captureNextFrame(func(img *image.RGBA) {
+		buf := new(bytes.Buffer)
+		if err := png.Encode(buf, app.watermark(img)); err != nil {
+			app.log.Error("encoding snapshot failed: %v", err)
+		}
+		snapshot <- buf.Bytes()
+	})
+	select {
+	case a.Snapshot = <-snapshot:
+	case <-app.quit:
+		return
+	}
+	app.annotations = append(app.annotations, a)
+	app.journal(journalEntry{Annotation: &a})
+	app.sendDataToClient("annotation", map[string]string{"guid": a.GUID, "title": a.Title})
+	FireEvent(EventAnnotationCreated, app.log.Session(), a)
+}
+
+// Screenshot sends the next frame as png image with the snapshot watermark in a screenshot message
+func (app *RenderingApp) Screenshot(cmd Command) {
+	app.captureNextFrame(func(img *image.RGBA) {
+		buf := new(bytes.Buffer)
+		if err := png.Encode(buf, app.watermark(img)); err != nil {
+			app.log.Error("encoding screenshot failed: %v", err)
+			app.sendMessageToClient("screenshot", err.Error())
+			return
+		}
+		export := annotationExport{
+			Name:   "screenshot.png",
+			Format: "png",
+			File:   base64.StdEncoding.EncodeToString(buf.Bytes()),
+		}
+		app.sendDataToClient("screenshot", export)
+		FireEvent(EventScreenshotTaken, app.log.Session(), export)
+	})
+}
+
+// Export sends all annotations as BCF 2.1 archive (bcf, default) or as JSON document (json)
+func (app *RenderingApp) Export(cmd Command) {
+	buf := new(bytes.Buffer)
+	var err error
+	export := annotationExport{Format: cmd.Val}
+	switch cmd.Val {
+	case "", "bcf":
+		export.Format, export.Name = "bcf", "annotations.bcfzip"
+		err = writeBCF(buf, app.annotations)
+	case "json":
+		export.Name = "annotations.json"
+		err = json.NewEncoder(buf).Encode(app.annotations)
+	default:
+		app.sendMessageToClient("export", "unknown export format "+cmd.Val)
+		return
+	}
+	if err != nil {
+		app.log.Error("export failed: %v", err)
+		app.sendMessageToClient("export", err.Error())
+		return
+	}
+	export.File = base64.StdEncoding.EncodeToString(buf.Bytes())
+	app.sendDataToClient("export", export)
+}
+
+// cameraState returns the current camera in world coordinates
+func (app *RenderingApp) cameraState() CameraState {
+	cam := app.Camera().GetCamera()
+	var position, direction math32.Vector3
+	var quaternion math32.Quaternion
+	cam.WorldPosition(&position)
+	cam.WorldDirection(&direction)
+	cam.WorldQuaternion(&quaternion)
+	up := math32.Vector3{X: 0, Y: 1, Z: 0}
+	up.ApplyQuaternion(&quaternion)
+	return CameraState{
+		Position:  toArray(position),
+		Direction: toArray(direction),
+		Up:        toArray(up),
+		Fov:       app.CameraPersp().Fov(),
+	}
+}
+
+// captureNextFrame passes the next finished frame to a function on the render thread
+func (app *RenderingApp) captureNextFrame(capture func(img *image.RGBA)) {
+	select {
+	case app.frameCaptures <- capture:
+	case <-app.quit:
+	}
+}
+
+// captureFrame passes a finished frame to all queued captures
+func (app *RenderingApp) captureFrame(img *image.RGBA) {
+	for {
+		select {
+		case capture := <-app.frameCaptures:
+			capture(img)
+		default:
+			return
+		}
+	}
+}
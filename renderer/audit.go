@@ -0,0 +1,140 @@
+package renderer
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// AuditDir is the directory of the append-only audit logs of sessions, empty disables auditing
+var AuditDir = ""
+
+// sceneActions are commands changing the scene or its elements, other commands only change the view
+var sceneActions = map[string]bool{
+	"Hide": true, "Unhide": true, "Shownodes": true, "Hidenodes": true, "Filter": true, "Unfilter": true, "Colorby": true,
+	"Patch": true, "Script": true, "Scenario": true, "Compare": true, "Heatmap": true,
+	"BindValues": true, "Unbind": true, "Clipping": true, "Annotate": true,
+	"Defineview": true, "Removeview": true, "Ground": true, "Shadows": true, "Sun": true,
+	"Clashes": true,
+}
+
+// auditEntry is a single line of an audit log
+type auditEntry struct {
+	Time    string `json:"time"`
+	Session string `json:"session"`
+	User    string `json:"user,omitempty"`
+	Action  string `json:"action"`
+	Value   string `json:"value,omitempty"`
+	Scene   bool   `json:"scene"`
+}
+
+// auditLog appends the entries of a session to a JSON lines file, e.g. its audit trail or command journal
+type auditLog struct {
+	mutex  sync.Mutex
+	file   *os.File
+	closed bool
+}
+
+// Audit sends the audit trail of the session to the client
+func (app *RenderingApp) Audit(cmd Command) {
+	if AuditDir == "" {
+		app.sendMessageToClient("audit", "auditing is disabled")
+		return
+	}
+	entries, err := readAudit(auditPath(app.log.Session()))
+	if err != nil {
+		app.sendMessageToClient("audit", "no audit trail available")
+		return
+	}
+	app.sendDataToClient("audit", entries)
+}
+
+// auditCommand records a received command, cursor moves are not recorded
+func (app *RenderingApp) auditCommand(cmd Command) {
+	if cmd.Cmd == "Navigate" {
+		return
+	}
+	app.audit(cmd.Cmd, cmd.Val, sceneActions[cmd.Cmd])
+}
+
+// audit appends an action of the session to its audit log
+func (app *RenderingApp) audit(action string, value string, scene bool) {
+	if AuditDir == "" {
+		return
+	}
+	entry := auditEntry{
+		Time:    time.Now().UTC().Format(time.RFC3339Nano),
+		Session: app.log.Session(),
+		User:    app.loadOptions.User,
+		Action:  action,
+		Value:   value,
+		Scene:   scene,
+	}
+	if err := app.auditLog.write(auditPath(entry.Session), entry); err != nil {
+		app.log.Error("writing audit log failed: %v", err)
+	}
+}
+
+// write appends an entry, opening the file on first use
+func (a *auditLog) write(path string, entry interface{}) error {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	if a.closed {
+		return nil
+	}
+	if a.file == nil {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0640)
+		if err != nil {
+			return err
+		}
+		a.file = f
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	_, err = a.file.Write(append(line, '\n'))
+	return err
+}
+
+// close closes the audit file, later entries are dropped
+func (a *auditLog) close() {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	if a.file != nil {
+		a.file.Close()
+		a.file = nil
+	}
+	a.closed = true
+}
+
+// auditPath returns the audit file of a session
+func auditPath(session string) string {
+	return filepath.Join(AuditDir, filepath.Base(session)+".jsonl")
+}
+
+// readAudit reads all entries of an audit file
+func readAudit(path string) ([]auditEntry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	entries := []auditEntry{}
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		var e auditEntry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, scanner.Err()
+}
@@ -0,0 +1,52 @@
+package renderer
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAuditLog(t *testing.T) {
+	dir, err := ioutil.TempDir("", "audit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	defer func(dir string) { AuditDir = dir }(AuditDir)
+	AuditDir = filepath.Join(dir, "logs")
+
+	path := auditPath("session")
+	assert(t, path, filepath.Join(dir, "logs", "session.jsonl"))
+	assert(t, auditPath("../session"), path)
+
+	var a auditLog
+	if err := a.write(path, auditEntry{Session: "session", User: "jane", Action: "Hide", Scene: true}); err != nil {
+		t.Fatal(err)
+	}
+	a.write(path, auditEntry{Session: "session", Action: "Zoom", Value: "1"})
+	a.close()
+	// entries after closing are dropped
+	a.write(path, auditEntry{Session: "session", Action: "Unhide"})
+
+	// a second log of the same session appends
+	var b auditLog
+	b.write(path, auditEntry{Session: "session", Action: "Audit"})
+	b.close()
+
+	entries, err := readAudit(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert(t, len(entries), 3)
+	assert(t, entries[0].Action, "Hide")
+	assert(t, entries[0].User, "jane")
+	assert(t, entries[0].Scene, true)
+	assert(t, entries[1].Value, "1")
+	assert(t, entries[1].Scene, false)
+	assert(t, entries[2].Action, "Audit")
+
+	if _, err := readAudit(auditPath("missing")); err == nil {
+		t.Error("expected error reading a missing audit log")
+	}
+}
@@ -0,0 +1,109 @@
+package renderer
+
+import (
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// MaxBandwidth is the bytes per second new sessions may stream, 0 disables the limit
+var MaxBandwidth int64
+
+// bandwidthWindow is the interval the streamed bytes are measured over
+const bandwidthWindow = time.Second
+
+// bandwidthLevel degrades frames to stream less data
+type bandwidthLevel struct {
+	maxQuality    int     // jpeg quality cap, 0 keeps the quality
+	minPixelation float64 // pixelation floor
+	frameDivisor  int     // every n-th frame is sent
+}
+
+// bandwidthLevels lists degradations from none to the lowest quality, applied step by step while over the limit
+var bandwidthLevels = []bandwidthLevel{
+	{0, 1, 1},
+	{70, 1, 1},
+	{50, 1.5, 1},
+	{40, 2, 2},
+	{30, 3, 3},
+	{20, 4, 4},
+}
+
+// bandwidthState measures the streamed bytes of a session and picks the degradation level
+type bandwidthState struct {
+	sent   int64 // bytes within the current window, updated atomically as messages are sent by any goroutine
+	total  int64 // bytes of the session, updated atomically
+	limit  int64 // bytes per second, 0 disables the limit
+	max    int64 // limit of the server, sessions may only lower it
+	start  time.Time
+	level  int
+	frames int
+}
+
+// Bandwidth limits the bytes per second of the session as kilobytes, the limit of the server can't be exceeded.
+// An empty value resets the limit to the server limit.
+func (app *RenderingApp) Bandwidth(cmd Command) {
+	limit := app.bandwidth.max
+	if cmd.Val != "" {
+		kb, err := strconv.ParseInt(cmd.Val, 10, 64)
+		if err != nil || kb < 1 {
+			app.sendMessageToClient("bandwidth", "invalid bandwidth "+cmd.Val)
+			return
+		}
+		limit = kb << 10
+		if app.bandwidth.max > 0 && limit > app.bandwidth.max {
+			limit = app.bandwidth.max
+		}
+	}
+	app.bandwidth.limit = limit
+	if limit == 0 {
+		app.bandwidth.level = 0
+		app.imageSettings.degradation = bandwidthLevels[0]
+	}
+}
+
+// stream sends a frame or message to the client, counting its bytes
+func (app *RenderingApp) stream(data []byte) {
+	atomic.AddInt64(&app.bandwidth.sent, int64(len(data)))
+	atomic.AddInt64(&app.bandwidth.total, int64(len(data)))
+	app.cImagestream <- data
+}
+
+// BytesSent returns the number of bytes the session has streamed to its client
+func (app *RenderingApp) BytesSent() int64 {
+	return atomic.LoadInt64(&app.bandwidth.total)
+}
+
+// throttleFrame adjusts the degradation level once per window and returns false if the frame should be skipped
+func (app *RenderingApp) throttleFrame(now time.Time) bool {
+	b := &app.bandwidth
+	if b.limit == 0 {
+		return true
+	}
+	if b.start.IsZero() {
+		b.start = now
+	}
+	if elapsed := now.Sub(b.start); elapsed >= bandwidthWindow {
+		sent := atomic.SwapInt64(&b.sent, 0)
+		b.level = nextBandwidthLevel(b.level, sent*int64(time.Second)/int64(elapsed), b.limit)
+		b.start = now
+		app.imageSettings.degradation = bandwidthLevels[b.level]
+	}
+	b.frames++
+	// frames beyond the budget of the window are dropped until the next one starts
+	if atomic.LoadInt64(&b.sent) >= b.limit {
+		return false
+	}
+	return b.frames%bandwidthLevels[b.level].frameDivisor == 0
+}
+
+// nextBandwidthLevel degrades while the rate exceeds the limit and recovers once it is well below
+func nextBandwidthLevel(level int, rate, limit int64) int {
+	if rate > limit && level < len(bandwidthLevels)-1 {
+		return level + 1
+	}
+	if rate < limit/2 && level > 0 {
+		return level - 1
+	}
+	return level
+}
@@ -0,0 +1,49 @@
+package renderer
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestNextBandwidthLevel(t *testing.T) {
+	assert(t, nextBandwidthLevel(0, 2000, 1000), 1)
+	assert(t, nextBandwidthLevel(len(bandwidthLevels)-1, 2000, 1000), len(bandwidthLevels)-1)
+	assert(t, nextBandwidthLevel(2, 800, 1000), 2)
+	assert(t, nextBandwidthLevel(2, 400, 1000), 1)
+	assert(t, nextBandwidthLevel(0, 0, 1000), 0)
+}
+
+func TestDegradation(t *testing.T) {
+	i := ImageSettings{quality: highQ}
+	assert(t, i.getJpegQuality(), 100)
+	assert(t, i.getPixelation(), 1.0)
+	i.degradation = bandwidthLevels[3]
+	assert(t, i.getJpegQuality(), 40)
+	assert(t, i.getPixelation(), 2.0)
+	i.pixelation = 3
+	assert(t, i.getPixelation(), 3.0)
+}
+
+func TestThrottleFrame(t *testing.T) {
+	app := RenderingApp{}
+	now := time.Now()
+	assert(t, app.throttleFrame(now), true)
+
+	app.bandwidth.limit = 1000
+	atomic.AddInt64(&app.bandwidth.sent, 1500)
+	assert(t, app.throttleFrame(now), false) // over the budget of the window
+	assert(t, app.throttleFrame(now.Add(bandwidthWindow)), true)
+	assert(t, app.bandwidth.level, 1)
+	assert(t, app.imageSettings.degradation, bandwidthLevels[1])
+
+	// frames are skipped at levels reducing the frame rate
+	app.bandwidth.level = 3
+	sent := 0
+	for i := 0; i < 10; i++ {
+		if app.throttleFrame(now.Add(bandwidthWindow)) {
+			sent++
+		}
+	}
+	assert(t, sent, 5)
+}
@@ -0,0 +1,140 @@
+package renderer
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"io"
+	"time"
+)
+
+// bcf markup of a topic, the subset of BCF 2.1 written by writeBCF
+type bcfMarkup struct {
+	XMLName    xml.Name      `xml:"Markup"`
+	Topic      bcfTopic      `xml:"Topic"`
+	Viewpoints bcfViewpoints `xml:"Viewpoints"`
+}
+
+type bcfTopic struct {
+	GUID           string `xml:"Guid,attr"`
+	TopicType      string `xml:"TopicType,attr"`
+	TopicStatus    string `xml:"TopicStatus,attr"`
+	Title          string `xml:"Title"`
+	CreationDate   string `xml:"CreationDate"`
+	CreationAuthor string `xml:"CreationAuthor"`
+}
+
+type bcfViewpoints struct {
+	GUID      string `xml:"Guid,attr"`
+	Viewpoint string `xml:"Viewpoint"`
+	Snapshot  string `xml:"Snapshot,omitempty"`
+}
+
+type bcfVisualizationInfo struct {
+	XMLName           xml.Name             `xml:"VisualizationInfo"`
+	GUID              string               `xml:"Guid,attr"`
+	Selection         []bcfComponent       `xml:"Components>Selection>Component,omitempty"`
+	PerspectiveCamera bcfPerspectiveCamera `xml:"PerspectiveCamera"`
+}
+
+type bcfComponent struct {
+	AuthoringToolID string `xml:"AuthoringToolId,attr"`
+}
+
+type bcfPerspectiveCamera struct {
+	CameraViewPoint bcfVector `xml:"CameraViewPoint"`
+	CameraDirection bcfVector `xml:"CameraDirection"`
+	CameraUpVector  bcfVector `xml:"CameraUpVector"`
+	FieldOfView     float32   `xml:"FieldOfView"`
+}
+
+type bcfVector struct {
+	X float32 `xml:"X"`
+	Y float32 `xml:"Y"`
+	Z float32 `xml:"Z"`
+}
+
+// bcfAuthor is the author of topics raised in the viewer
+const bcfAuthor = "webg3n"
+
+// writeBCF writes annotations as BCF 2.1 archive with one topic per annotation.
+// Selected nodes are referenced by name as authoring tool id, as nodes carry no IFC guid.
+func writeBCF(w io.Writer, annotations []Annotation) error {
+	z := zip.NewWriter(w)
+	if err := writeZipFile(z, "bcf.version", `<?xml version="1.0" encoding="UTF-8"?>
+<Version VersionId="2.1"><DetailedVersion>2.1</DetailedVersion></Version>
+`); err != nil {
+		return err
+	}
+	for _, a := range annotations {
+		dir := a.GUID + "/"
+		markup := bcfMarkup{
+			Topic: bcfTopic{
+				GUID:           a.GUID,
+				TopicType:      "Issue",
+				TopicStatus:    "Open",
+				Title:          a.Title,
+				CreationDate:   a.Created.Format(time.RFC3339),
+				CreationAuthor: bcfAuthor,
+			},
+			Viewpoints: bcfViewpoints{GUID: a.GUID, Viewpoint: "viewpoint.bcfv"},
+		}
+		if len(a.Snapshot) > 0 {
+			markup.Viewpoints.Snapshot = "snapshot.png"
+			f, err := z.Create(dir + "snapshot.png")
+			if err != nil {
+				return err
+			}
+			if _, err := f.Write(a.Snapshot); err != nil {
+				return err
+			}
+		}
+		if err := writeZipXML(z, dir+"markup.bcf", markup); err != nil {
+			return err
+		}
+		if err := writeZipXML(z, dir+"viewpoint.bcfv", bcfViewpoint(a)); err != nil {
+			return err
+		}
+	}
+	return z.Close()
+}
+
+// bcfViewpoint returns the camera and selection of an annotation
+func bcfViewpoint(a Annotation) bcfVisualizationInfo {
+	v := bcfVisualizationInfo{
+		GUID: a.GUID,
+		PerspectiveCamera: bcfPerspectiveCamera{
+			CameraViewPoint: zUp(a.Camera.Position),
+			CameraDirection: zUp(a.Camera.Direction),
+			CameraUpVector:  zUp(a.Camera.Up),
+			FieldOfView:     a.Camera.Fov,
+		},
+	}
+	for _, name := range a.Selection {
+		v.Selection = append(v.Selection, bcfComponent{AuthoringToolID: name})
+	}
+	return v
+}
+
+// zUp converts a y-up glTF vector into the z-up coordinates of BCF
+func zUp(v [3]float32) bcfVector {
+	return bcfVector{X: v[0], Y: -v[2], Z: v[1]}
+}
+
+// writeZipXML adds an XML document to an archive
+func writeZipXML(z *zip.Writer, name string, v interface{}) error {
+	data, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeZipFile(z, name, xml.Header+string(data)+"\n")
+}
+
+// writeZipFile adds a text file to an archive
+func writeZipFile(z *zip.Writer, name string, content string) error {
+	f, err := z.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(f, content)
+	return err
+}
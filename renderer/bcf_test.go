@@ -0,0 +1,54 @@
+package renderer
+
+import (
+	"archive/zip"
+	"bytes"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteBCF(t *testing.T) {
+	a := Annotation{
+		GUID:      "0b7e0d6c-5f4a-4c43-9a55-2f1f5d1b8e01",
+		Title:     "Door <blocked>",
+		Created:   time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC),
+		Camera:    CameraState{Position: [3]float32{1, 2, 3}, Direction: [3]float32{0, 0, -1}, Up: [3]float32{0, 1, 0}, Fov: 50},
+		Selection: []string{"Door 7"},
+		Snapshot:  []byte("png"),
+	}
+	buf := new(bytes.Buffer)
+	if err := writeBCF(buf, []Annotation{a}); err != nil {
+		t.Fatal(err)
+	}
+	r, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := make(map[string]string)
+	for _, f := range r.File {
+		rc, _ := f.Open()
+		data, _ := ioutil.ReadAll(rc)
+		rc.Close()
+		files[f.Name] = string(data)
+	}
+	assert(t, len(files), 4)
+	assert(t, files[a.GUID+"/snapshot.png"], "png")
+	markup := files[a.GUID+"/markup.bcf"]
+	if !strings.Contains(markup, "<Title>Door &lt;blocked&gt;</Title>") || !strings.Contains(markup, "<Snapshot>snapshot.png</Snapshot>") {
+		t.Error("wrong markup:", markup)
+	}
+	viewpoint := files[a.GUID+"/viewpoint.bcfv"]
+	if !strings.Contains(viewpoint, `<Component AuthoringToolId="Door 7"></Component>`) || !strings.Contains(viewpoint, "<X>1</X>\n      <Y>-3</Y>\n      <Z>2</Z>") {
+		t.Error("wrong viewpoint:", viewpoint)
+	}
+	if !strings.Contains(files["bcf.version"], `VersionId="2.1"`) {
+		t.Error("missing version")
+	}
+}
+
+func TestZUp(t *testing.T) {
+	assert(t, zUp([3]float32{0, 1, 0}), bcfVector{X: 0, Y: 0, Z: 1})
+	assert(t, zUp([3]float32{0, 0, -1}), bcfVector{X: 0, Y: 1, Z: 0})
+}
@@ -0,0 +1,171 @@
+package renderer
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/graphic"
+	"github.com/g3n/engine/material"
+	"github.com/g3n/engine/math32"
+)
+
+// RampStop is a color at a value of a color ramp
+type RampStop struct {
+	Value float32
+	Color math32.Color
+}
+
+// ColorRamp maps values to colors, interpolating linearly between stops sorted by value
+type ColorRamp []RampStop
+
+// DefaultColorRamp maps values from 0 to 1 to blue, green and red
+var DefaultColorRamp = ColorRamp{
+	{0, math32.Color{R: 0, G: 0, B: 1}},
+	{0.5, math32.Color{R: 0, G: 1, B: 0}},
+	{1, math32.Color{R: 1, G: 0, B: 0}},
+}
+
+// bindingState holds graphics colored by live values
+type bindingState struct {
+	ramp      ColorRamp
+	values    map[string]float32
+	originals map[core.INode][]graphic.GraphicMaterial
+	materials map[core.INode]*material.Standard
+}
+
+// ParseColorRamp parses comma separated value:color stops, e.g. 0:blue,20:#00ff00,40:red
+func ParseColorRamp(value string) (ColorRamp, error) {
+	var ramp ColorRamp
+	for _, stop := range strings.Split(value, ",") {
+		s := strings.SplitN(strings.TrimSpace(stop), ":", 2)
+		if len(s) != 2 {
+			return nil, fmt.Errorf("invalid ramp stop %s", stop)
+		}
+		v, err := strconv.ParseFloat(s[0], 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ramp value %s", s[0])
+		}
+		color, err := parseColor(s[1])
+		if err != nil {
+			return nil, err
+		}
+		ramp = append(ramp, RampStop{Value: float32(v), Color: *color})
+	}
+	if len(ramp) < 2 {
+		return nil, fmt.Errorf("a color ramp needs at least two stops")
+	}
+	sort.Slice(ramp, func(i, j int) bool { return ramp[i].Value < ramp[j].Value })
+	return ramp, nil
+}
+
+// At returns the color of a value, values outside the ramp get the color of the closest stop
+func (r ColorRamp) At(value float32) math32.Color {
+	if value <= r[0].Value {
+		return r[0].Color
+	}
+	for i := 1; i < len(r); i++ {
+		if value <= r[i].Value {
+			t := (value - r[i-1].Value) / (r[i].Value - r[i-1].Value)
+			c := r[i-1].Color
+			return *c.Lerp(&r[i].Color, t)
+		}
+	}
+	return r[len(r)-1].Color
+}
+
+// Colorramp sets the color ramp of live values and recolors all bound nodes
+func (app *RenderingApp) Colorramp(cmd Command) {
+	ramp, err := ParseColorRamp(cmd.Val)
+	if err != nil {
+		app.log.Warn("colorramp: %v", err)
+		app.sendMessageToClient("colorramp", err.Error())
+		return
+	}
+	// live values are bound on the render thread
+	update := func() {
+		app.binding.ramp = ramp
+		app.applyValues(app.binding.values)
+	}
+	select {
+	case app.sceneUpdates <- update:
+	case <-app.quit:
+	}
+}
+
+// Unbind restores the original colors of all nodes colored by live values
+func (app *RenderingApp) Unbind(cmd Command) {
+	select {
+	case app.sceneUpdates <- app.unbindValues:
+	case <-app.quit:
+	}
+}
+
+// unbindValues restores the original materials of bound graphics and drops their values
+func (app *RenderingApp) unbindValues() {
+	for inode, materials := range app.binding.originals {
+		gfx := inode.(graphic.IGraphic).GetGraphic()
+		gfx.ClearMaterials()
+		for _, gm := range materials {
+			gfx.AddMaterial(gm.IGraphic(), gm.IMaterial(), 0, 0)
+		}
+	}
+	app.binding.values = nil
+	app.binding.originals = nil
+	app.binding.materials = nil
+}
+
+// BindValues queues live values by node id, bound nodes are colored by the color ramp before the next frame.
+// A nil ramp keeps the current color ramp.
+func (app *RenderingApp) BindValues(values map[string]float32, ramp ColorRamp) {
+	if app.Window() == nil {
+		return
+	}
+	update := func() {
+		if ramp != nil {
+			app.binding.ramp = ramp
+			app.applyValues(app.binding.values)
+		}
+		app.applyValues(values)
+	}
+	select {
+	case app.sceneUpdates <- update:
+	case <-app.quit:
+	}
+}
+
+// applyValues colors all graphics of the given nodes by their value
+func (app *RenderingApp) applyValues(values map[string]float32) {
+	b := &app.binding
+	if b.ramp == nil {
+		b.ramp = DefaultColorRamp
+	}
+	if b.values == nil {
+		b.values = make(map[string]float32)
+		b.originals = make(map[core.INode][]graphic.GraphicMaterial)
+		b.materials = make(map[core.INode]*material.Standard)
+	}
+	for id, value := range values {
+		inode, ok := app.nodeBuffer[id]
+		if !ok {
+			continue
+		}
+		b.values[id] = value
+		color := b.ramp.At(value)
+		forEachGraphic([]core.INode{inode}, func(inode core.INode) {
+			if mat, ok := b.materials[inode]; ok {
+				mat.SetColor(&color)
+				return
+			}
+			gnode := inode.(graphic.IGraphic)
+			gfx := gnode.GetGraphic()
+			b.originals[inode] = append([]graphic.GraphicMaterial(nil), gfx.Materials()...)
+			mat := material.NewStandard(&color)
+			b.materials[inode] = mat
+			gfx.ClearMaterials()
+			gfx.AddMaterial(gnode, mat, 0, 0)
+		})
+	}
+}
@@ -0,0 +1,29 @@
+package renderer
+
+import (
+	"testing"
+
+	"github.com/g3n/engine/math32"
+)
+
+func TestParseColorRamp(t *testing.T) {
+	ramp, err := ParseColorRamp("100:red, 0:#0000ff")
+	assert(t, err, nil)
+	assert(t, len(ramp), 2)
+	// stops are sorted by value
+	assert(t, ramp[0].Value, float32(0))
+
+	_, err = ParseColorRamp("0:blue")
+	assert(t, err != nil, true)
+	_, err = ParseColorRamp("0:blue,x:red")
+	assert(t, err != nil, true)
+	_, err = ParseColorRamp("0:blue,1:nocolor")
+	assert(t, err != nil, true)
+}
+
+func TestColorRampAt(t *testing.T) {
+	ramp := ColorRamp{{0, math32.Color{R: 0, G: 0, B: 1}}, {10, math32.Color{R: 1, G: 0, B: 1}}}
+	assert(t, ramp.At(-5), math32.Color{R: 0, G: 0, B: 1})
+	assert(t, ramp.At(5), math32.Color{R: 0.5, G: 0, B: 1})
+	assert(t, ramp.At(20), math32.Color{R: 1, G: 0, B: 1})
+}
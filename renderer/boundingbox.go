@@ -0,0 +1,67 @@
+package renderer
+
+import (
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/graphic"
+	"github.com/g3n/engine/math32"
+)
+
+// BoundingBox is an axis-aligned box in world coordinates sent to the client
+type BoundingBox struct {
+	Min    [3]float32 `json:"min"`
+	Max    [3]float32 `json:"max"`
+	Center [3]float32 `json:"center"`
+	Size   [3]float32 `json:"size"`
+}
+
+// newBoundingBox creates the client data of a box
+func newBoundingBox(box math32.Box3) *BoundingBox {
+	return &BoundingBox{
+		Min:    toArray(box.Min),
+		Max:    toArray(box.Max),
+		Center: toArray(*box.Center(nil)),
+		Size:   toArray(*box.Size(nil)),
+	}
+}
+
+// getWorldBoundingBox returns the world space box of all graphics within the given nodes
+func getWorldBoundingBox(nodes []core.INode) (math32.Box3, bool) {
+	var bbox math32.Box3
+	bbox.MakeEmpty()
+	found := false
+	forEachGraphic(nodes, func(inode core.INode) {
+		box := inode.(graphic.IGraphic).GetGeometry().BoundingBox()
+		matrixWorld := inode.GetNode().MatrixWorld()
+		box.ApplyMatrix4(&matrixWorld)
+		bbox.Union(&box)
+		found = true
+	})
+	return bbox, found
+}
+
+// Boundingbox sends the world space bounding box of the scene, the selection or a node by id.
+// The value is empty or scene, selection or a node id
+func (app *RenderingApp) Boundingbox(cmd Command) {
+	var nodes []core.INode
+	switch cmd.Val {
+	case "", "scene":
+		// the model without ground, shadows or markers
+		if app.modelRoot != nil {
+			nodes = []core.INode{app.modelRoot}
+		}
+	case "selection":
+		for inode := range app.selectionBuffer {
+			nodes = append(nodes, inode)
+		}
+	default:
+		if node, ok := app.nodeBuffer[cmd.Val]; ok {
+			nodes = append(nodes, node)
+		}
+	}
+	bbox, found := getWorldBoundingBox(nodes)
+	if !found {
+		app.sendDataToClient("boundingbox", nil)
+		return
+	}
+	app.sendDataToClient("boundingbox", newBoundingBox(bbox))
+}
@@ -0,0 +1,13 @@
+package renderer
+
+import (
+	"testing"
+
+	"github.com/g3n/engine/math32"
+)
+
+func TestNewBoundingBox(t *testing.T) {
+	b := newBoundingBox(math32.Box3{Min: math32.Vector3{X: 0, Y: -2, Z: 0}, Max: math32.Vector3{X: 4, Y: 2, Z: 10}})
+	assert(t, b.Center, [3]float32{2, 0, 5})
+	assert(t, b.Size, [3]float32{4, 4, 10})
+}
@@ -1,10 +1,29 @@
 package renderer
 
 import (
+	"strconv"
+	"time"
+
 	"github.com/g3n/engine/math32"
-	"log"
 )
 
+// defaultTransition is the duration of camera transitions of new sessions
+const defaultTransition = 500 * time.Millisecond
+
+// cameraView is a camera position looking at a target
+type cameraView struct {
+	position math32.Vector3
+	target   math32.Vector3
+}
+
+// cameraTransition animates the camera between two views
+type cameraTransition struct {
+	duration time.Duration
+	from, to cameraView
+	start    time.Time
+	active   bool
+}
+
 // getCenter gets the centerpoint of a 3D box
 func getCenter(box math32.Box3) *math32.Vector3 {
 	return box.Center(nil)
@@ -18,7 +37,7 @@ func (app *RenderingApp) focusOnSelection() {
 		tmp := inode.BoundingBox()
 		if first {
 			bbox = math32.NewBox3(&tmp.Min, &tmp.Max)
-			log.Println(bbox)
+			app.log.Debug("focus: %v", bbox)
 			first = false
 		} else {
 			bbox.ExpandByPoint(&tmp.Min)
@@ -37,8 +56,7 @@ func (app *RenderingApp) focusOnSelection() {
 	dir := math32.Vector3{X: C.X, Y: C.Y, Z: C.Z}
 	P.Add(((position.Sub(C)).Normalize().MultiplyScalar(d)))
 	dir.Sub(&P)
-	app.Camera().GetCamera().SetPositionVec(&P)
-	app.Camera().GetCamera().LookAt(C)
+	app.moveCamera(P, *C)
 }
 
 // getViewVectorByName gets a view direction vector by name
@@ -64,6 +82,14 @@ func getViewVectorByName(view string) math32.Vector3 {
 // setCamera set camera sets a camera standard view by name
 func (app *RenderingApp) setCamera(view string) {
 	modifier := getViewVectorByName(view)
+	up := math32.Vector3{X: 0, Y: 1, Z: 0}
+	if custom, ok := app.customView(view); ok {
+		modifier = math32.Vector3{X: -custom.Direction[0], Y: -custom.Direction[1], Z: -custom.Direction[2]}
+		if custom.Up != [3]float32{} {
+			up = math32.Vector3{X: custom.Up[0], Y: custom.Up[1], Z: custom.Up[2]}
+		}
+	}
+	app.Camera().GetCamera().SetUp(&up)
 	bbox := app.Scene().ChildAt(0).BoundingBox()
 	C := bbox.Center(nil)
 	pos := modifier.Add(C)
@@ -81,8 +107,7 @@ func (app *RenderingApp) focusCameraToCenter(position math32.Vector3) {
 	dir := math32.Vector3{X: C.X, Y: C.Y, Z: C.Z}
 	P.Add(((position.Sub(C)).Normalize().MultiplyScalar(d)))
 	dir.Sub(&P)
-	app.Camera().GetCamera().SetPositionVec(&P)
-	app.Camera().GetCamera().LookAt(C)
+	app.moveCamera(P, *C)
 }
 
 // zoomToExtent zooms the view to extent
@@ -90,3 +115,120 @@ func (app *RenderingApp) zoomToExtent() {
 	pos := app.Camera().GetCamera().Position()
 	app.focusCameraToCenter(pos)
 }
+
+// Pan moves the view parallel to the screen by x and y pixels, the model follows the cursor like dragging it
+func (app *RenderingApp) Pan(cmd Command) {
+	app.stopTransition()
+	cam := app.Camera().GetCamera()
+	position, target := panView(cam.Position(), cam.Target(), cam.Up(), app.CameraPersp().Fov(), app.Height, cmd.X, cmd.Y)
+	cam.SetPositionVec(&position)
+	cam.LookAt(&target)
+}
+
+// panView moves position and target by screen pixels, scaled to world units at the distance of the target
+func panView(position, target, up math32.Vector3, fov float32, height int, dx, dy float32) (math32.Vector3, math32.Vector3) {
+	view := target.Clone().Sub(&position)
+	scale := 2 * view.Length() * math32.Tan(math32.DegToRad(fov)/2) / float32(height)
+	right := view.Clone().Cross(&up).Normalize()
+	screenUp := right.Clone().Cross(view).Normalize()
+	offset := right.MultiplyScalar(-dx * scale).Add(screenUp.MultiplyScalar(dy * scale))
+	return *position.Add(offset), *target.Add(offset)
+}
+
+// Transition sets the duration of camera transitions in milliseconds, 0 moves the camera instantly
+func (app *RenderingApp) Transition(cmd Command) {
+	ms, err := strconv.Atoi(cmd.Val)
+	if err != nil || ms < 0 {
+		app.sendMessageToClient("transition", "invalid duration "+cmd.Val)
+		return
+	}
+	app.transition.duration = time.Duration(ms) * time.Millisecond
+}
+
+// moveCamera moves the camera to look from a position at a target, animated if transitions are enabled
+func (app *RenderingApp) moveCamera(position math32.Vector3, target math32.Vector3) {
+	cam := app.Camera().GetCamera()
+	t := &app.transition
+	if t.duration <= 0 {
+		t.active = false
+		cam.SetPositionVec(&position)
+		cam.LookAt(&target)
+		return
+	}
+	t.from = cameraView{position: cam.Position(), target: cam.Target()}
+	t.to = cameraView{position: position, target: target}
+	t.start = time.Now()
+	t.active = true
+}
+
+// stopTransition leaves the camera where a running transition has moved it, e.g. when the user navigates
+func (app *RenderingApp) stopTransition() {
+	app.transition.active = false
+}
+
+// animateCamera moves the camera along a running transition before rendering
+func (app *RenderingApp) animateCamera(evname string, ev interface{}) {
+	t := &app.transition
+	if !t.active {
+		return
+	}
+	progress := float32(time.Since(t.start)) / float32(t.duration)
+	if progress >= 1 {
+		progress = 1
+		t.active = false
+	}
+	view := interpolateView(t.from, t.to, easeInOut(progress))
+	cam := app.Camera().GetCamera()
+	cam.SetPositionVec(&view.position)
+	cam.LookAt(&view.target)
+}
+
+// interpolateView returns the view at a fraction between two views.
+// The target moves straight while the camera swings around it, so the model stays in sight.
+func interpolateView(from, to cameraView, f float32) cameraView {
+	target := *from.target.Clone().Lerp(&to.target, f)
+
+	a := *from.position.Clone().Sub(&from.target)
+	b := *to.position.Clone().Sub(&to.target)
+	la, lb := a.Length(), b.Length()
+	if la == 0 || lb == 0 {
+		return cameraView{position: *from.position.Clone().Lerp(&to.position, f), target: target}
+	}
+	a.Normalize()
+	b.Normalize()
+	direction := slerp(a, b, f)
+	position := *direction.MultiplyScalar(la + (lb-la)*f)
+	position.Add(&target)
+	return cameraView{position: position, target: target}
+}
+
+// slerp interpolates between two unit vectors along the great circle
+func slerp(a, b math32.Vector3, f float32) *math32.Vector3 {
+	dot := math32.Clamp(a.Dot(&b), -1, 1)
+	if dot > 0.9995 {
+		return a.Clone().Lerp(&b, f).Normalize()
+	}
+	if dot < -0.9995 {
+		// opposite directions, swing over any perpendicular axis
+		axis := math32.Vector3{Y: 1}
+		if math32.Abs(a.Y) > 0.9 {
+			axis = math32.Vector3{X: 1}
+		}
+		axis.Cross(&a).Normalize()
+		var q math32.Quaternion
+		q.SetFromAxisAngle(&axis, math32.Pi*f)
+		return a.Clone().ApplyQuaternion(&q)
+	}
+	theta := math32.Acos(dot) * f
+	relative := *b.Clone().Sub(a.Clone().MultiplyScalar(dot)).Normalize()
+	return a.Clone().MultiplyScalar(math32.Cos(theta)).Add(relative.MultiplyScalar(math32.Sin(theta)))
+}
+
+// easeInOut accelerates at the start and slows down at the end of a transition
+func easeInOut(t float32) float32 {
+	if t < 0.5 {
+		return 4 * t * t * t
+	}
+	f := 2*t - 2
+	return 1 + f*f*f/2
+}
@@ -40,3 +40,45 @@ func TestGetViewVectorByName(t *testing.T) {
 		t.Error("front view incorrect")
 	}
 }
+
+func TestEaseInOut(t *testing.T) {
+	assert(t, easeInOut(0), float32(0))
+	assert(t, easeInOut(0.5), float32(0.5))
+	assert(t, easeInOut(1), float32(1))
+	if easeInOut(0.1) >= 0.1 || easeInOut(0.9) <= 0.9 {
+		t.Error("transition does not ease in and out")
+	}
+}
+
+func TestInterpolateView(t *testing.T) {
+	from := cameraView{position: math32.Vector3{Z: 10}}
+	to := cameraView{position: math32.Vector3{X: 20}, target: math32.Vector3{X: 10}}
+	assert(t, interpolateView(from, to, 0), from)
+	end := interpolateView(from, to, 1)
+	if end.position.DistanceTo(&to.position) > 1e-4 || end.target != to.target {
+		t.Error("transition does not end at the target view", end)
+	}
+	// the camera swings around the target instead of passing through it
+	opposite := cameraView{position: math32.Vector3{Z: -10}}
+	half := interpolateView(from, opposite, 0.5)
+	if math32.Abs(half.position.Length()-10) > 1e-4 {
+		t.Error("camera does not keep its distance", half.position)
+	}
+}
+
+func TestPanView(t *testing.T) {
+	position := math32.Vector3{Z: 10}
+	up := math32.Vector3{Y: 1}
+	// at 90 degrees a pixel of a 100 pixel high view is 0.2 units at the target distance of 10
+	p, target := panView(position, math32.Vector3{}, up, 90, 100, 10, 5)
+	expected := math32.Vector3{X: -2, Y: 1, Z: 10}
+	if p.DistanceTo(&expected) > 1e-4 {
+		t.Error("camera not panned", p)
+	}
+	expected.Z = 0
+	if target.DistanceTo(&expected) > 1e-4 {
+		t.Error("target not panned", target)
+	}
+	p, _ = panView(position, math32.Vector3{}, up, 90, 100, 0, 0)
+	assert(t, p, position)
+}
@@ -0,0 +1,121 @@
+package renderer
+
+import (
+	"time"
+	"unicode/utf8"
+
+	"github.com/g3n/engine/graphic"
+)
+
+// maximum length of a chat message in bytes, longer messages are cut
+const maxChatLength = 500
+
+// time a pointer stays visible
+const pointerDuration = 3 * time.Second
+
+// ChatMessage is a chat message or pointer of a participant of a shared session.
+// Name and color are set by the server.
+type ChatMessage struct {
+	Name  string      `json:"name"`
+	Color string      `json:"color"`
+	Text  string      `json:"text,omitempty"`
+	Point *[3]float32 `json:"point,omitempty"` // world position pointed at
+}
+
+// ChatHandler receives chat messages and pointers by session id, nil disables chat
+var ChatHandler func(session string, m ChatMessage)
+
+// pointerMarker is a pointer of a participant shown until it expires
+type pointerMarker struct {
+	mesh    *graphic.Mesh
+	expires time.Time
+}
+
+// Chat sends a text message to all participants of a shared session
+func (app *RenderingApp) Chat(cmd Command) {
+	if ChatHandler == nil || cmd.Val == "" {
+		return
+	}
+	ChatHandler(app.log.Session(), ChatMessage{Text: truncateChat(cmd.Val)})
+}
+
+// Pointer points at the model under the screen position for all participants of a shared session,
+// an optional value is sent along as message
+func (app *RenderingApp) Pointer(cmd Command) {
+	if ChatHandler == nil {
+		return
+	}
+	hit := app.pick(cmd.X, cmd.Y)
+	if hit == nil {
+		app.sendMessageToClient("pointer", "nothing to point at")
+		return
+	}
+	ChatHandler(app.log.Session(), ChatMessage{Text: truncateChat(cmd.Val), Point: &hit.Point})
+}
+
+// ShowChat sends a chat message to the client of an app and shows its pointer.
+// It is no method, so clients can't call it as command.
+func ShowChat(app *RenderingApp, m ChatMessage) {
+	app.showChatMessage(m)
+}
+
+// showChatMessage queues a chat message, messages are dropped while the scene update queue is full
+func (app *RenderingApp) showChatMessage(m ChatMessage) {
+	if app.Window() == nil {
+		return
+	}
+	select {
+	case app.sceneUpdates <- func() { app.showChat(m) }:
+	default:
+	}
+}
+
+// showChat places the pointer of a message, a newer pointer of the same participant replaces the last one
+func (app *RenderingApp) showChat(m ChatMessage) {
+	if m.Point != nil {
+		p := app.participantGroup()
+		pointer, ok := p.pointers[m.Color]
+		if !ok {
+			mesh, err := app.newCursorMarker(m.Color)
+			if err != nil {
+				app.log.Warn("pointer of %s: %v", m.Name, err)
+			} else {
+				mesh.SetScale(3, 3, 3)
+				p.group.Add(mesh)
+				pointer.mesh = mesh
+				ok = true
+			}
+		}
+		if ok {
+			pointer.mesh.SetPosition(m.Point[0], m.Point[1], m.Point[2])
+			pointer.expires = time.Now().Add(pointerDuration)
+			p.pointers[m.Color] = pointer
+		}
+	}
+	app.sendDataToClient("chat", m)
+}
+
+// expirePointers removes pointers shown long enough
+func (app *RenderingApp) expirePointers(arg interface{}) {
+	p := &app.presence
+	for color, pointer := range p.pointers {
+		if time.Now().After(pointer.expires) {
+			p.group.Remove(pointer.mesh)
+			pointer.mesh.Dispose()
+			delete(p.pointers, color)
+			app.frameCache.invalidate()
+		}
+	}
+}
+
+// truncateChat cuts a message to the maximum length without splitting characters
+func truncateChat(text string) string {
+	if len(text) <= maxChatLength {
+		return text
+	}
+	cut := maxChatLength
+	for cut > 0 && !utf8.RuneStart(text[cut]) {
+		cut--
+	}
+	return text[:cut]
+}
@@ -0,0 +1,14 @@
+package renderer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTruncateChat(t *testing.T) {
+	assert(t, truncateChat("hello"), "hello")
+	assert(t, len(truncateChat(strings.Repeat("a", 600))), maxChatLength)
+	// a two byte character crossing the limit is dropped as a whole
+	text := truncateChat(strings.Repeat("a", maxChatLength-1) + "ü")
+	assert(t, len(text), maxChatLength-1)
+}
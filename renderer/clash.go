@@ -0,0 +1,191 @@
+package renderer
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/graphic"
+	"github.com/g3n/engine/material"
+	"github.com/g3n/engine/math32"
+)
+
+// clash colors of the first and second node of a pair
+var clashColors = [2]math32.Color{{R: 0.9, G: 0.1, B: 0.1}, {R: 1, G: 0.6, B: 0}}
+
+// Clash is a pair of nodes by id flagged as clashing by a coordination tool
+type Clash struct {
+	Name string `json:"name,omitempty"`
+	A    string `json:"a"`
+	B    string `json:"b"`
+}
+
+// Validate checks a clash before it is queued
+func (c Clash) Validate() error {
+	if c.A == "" || c.B == "" {
+		return fmt.Errorf("a clash needs two node ids")
+	}
+	return nil
+}
+
+// clashState holds the clashes under review, the camera bookmark of each clash and the graphics colored by them
+type clashState struct {
+	clashes   []Clash
+	views     []cameraView
+	current   int // reviewed clash, -1 before navigating
+	originals map[core.INode][]graphic.GraphicMaterial
+}
+
+// clashInfo is the reviewed clash sent to the client
+type clashInfo struct {
+	Index int   `json:"index"`
+	Count int   `json:"count"`
+	Clash Clash `json:"clash"`
+}
+
+// SetClashes queues a list of clashes replacing the previous one, an empty list removes all clashes.
+// Clashes are expected to be validated.
+func (app *RenderingApp) SetClashes(clashes []Clash) {
+	if app.Window() == nil {
+		return
+	}
+	app.queueCommandUpdate(func() { app.setClashes(clashes) })
+}
+
+// Clashes sets the clashes of a session as json list of {"name", "a", "b"}, an empty value removes all clashes
+func (app *RenderingApp) Clashes(cmd Command) {
+	var clashes []Clash
+	if strings.TrimSpace(cmd.Val) != "" {
+		if err := json.Unmarshal([]byte(cmd.Val), &clashes); err != nil {
+			app.sendMessageToClient("clashes", "invalid clashes: "+err.Error())
+			return
+		}
+		for _, c := range clashes {
+			if err := c.Validate(); err != nil {
+				app.sendMessageToClient("clashes", err.Error())
+				return
+			}
+		}
+	}
+	app.setClashes(clashes)
+}
+
+// Clash moves the camera to a clash by index or to the next or previous clash and sends it as clash message.
+// Clashes are replaced while the command goroutine waits, so they are read without a queued update.
+func (app *RenderingApp) Clash(cmd Command) {
+	c := &app.clashes
+	index, err := clashIndex(cmd.Val, c.current, len(c.clashes))
+	if err != nil {
+		app.sendMessageToClient("clash", err.Error())
+		return
+	}
+	c.current = index
+	app.stopTransition()
+	app.moveCamera(c.views[index].position, c.views[index].target)
+	app.sendDataToClient("clash", clashInfo{Index: index, Count: len(c.clashes), Clash: c.clashes[index]})
+}
+
+// setClashes replaces the clashes and colors the clashing nodes on the render thread while the command goroutine waits.
+// The selection is cleared first and highlighted again on top of the clash colors,
+// so neither keeps the highlight of the other as original materials.
+func (app *RenderingApp) setClashes(clashes []Clash) {
+	app.renderSync(func() {
+		selection := make([]core.INode, 0, len(app.selectionBuffer))
+		for inode := range app.selectionBuffer {
+			selection = append(selection, inode)
+		}
+		app.resetSelection()
+		app.resetClashes()
+		app.clashes.clashes = clashes
+		app.applyClashes()
+		for _, inode := range selection {
+			app.changeNodeMaterial(inode)
+		}
+	})
+}
+
+// applyClashes colors the nodes of all clashes and creates their camera bookmarks, unknown nodes are skipped
+func (app *RenderingApp) applyClashes() {
+	c := &app.clashes
+	c.current = -1
+	c.views = make([]cameraView, len(c.clashes))
+	c.originals = make(map[core.INode][]graphic.GraphicMaterial)
+	materials := [2]*material.Standard{material.NewStandard(&clashColors[0]), material.NewStandard(&clashColors[1])}
+	for i, clash := range c.clashes {
+		var bbox *math32.Box3
+		for j, id := range [2]string{clash.A, clash.B} {
+			inode, ok := app.nodeBuffer[id]
+			if !ok {
+				app.log.Warn("clash: unknown node %s", id)
+				continue
+			}
+			box := inode.BoundingBox()
+			if bbox == nil {
+				bbox = math32.NewBox3(&box.Min, &box.Max)
+			} else {
+				bbox.Union(&box)
+			}
+			forEachGraphic([]core.INode{inode}, func(g core.INode) {
+				gnode := g.(graphic.IGraphic)
+				gfx := gnode.GetGraphic()
+				if _, ok := c.originals[g]; !ok {
+					c.originals[g] = append([]graphic.GraphicMaterial(nil), gfx.Materials()...)
+				}
+				gfx.ClearMaterials()
+				gfx.AddMaterial(gnode, materials[j], 0, 0)
+			})
+		}
+		if bbox != nil {
+			c.views[i] = clashView(*bbox, app.CameraPersp().Fov())
+		} else {
+			// clashes of unknown nodes keep the camera where it is
+			cam := app.Camera().GetCamera()
+			c.views[i] = cameraView{position: cam.Position(), target: cam.Target()}
+		}
+	}
+}
+
+// resetClashes restores the original colors of all graphics colored by clashes
+func (app *RenderingApp) resetClashes() {
+	for inode, materials := range app.clashes.originals {
+		gfx := inode.(graphic.IGraphic).GetGraphic()
+		gfx.ClearMaterials()
+		for _, gm := range materials {
+			gfx.AddMaterial(gm.IGraphic(), gm.IMaterial(), 0, 0)
+		}
+	}
+	app.clashes = clashState{current: -1}
+}
+
+// clashView returns a view fitting a clash, looking down from the front right
+func clashView(bbox math32.Box3, fov float32) cameraView {
+	center := bbox.Center(nil)
+	r := math32.Max(center.DistanceTo(&bbox.Max), 1e-3)
+	d := r / math32.Sin(math32.DegToRad(fov)/2)
+	position := math32.Vector3{X: 1, Y: 1, Z: 1}
+	position.Normalize().MultiplyScalar(d).Add(center)
+	return cameraView{position: position, target: *center}
+}
+
+// clashIndex returns the clash to navigate to by next, previous or index, wrapping around at both ends
+func clashIndex(value string, current, count int) (int, error) {
+	if count == 0 {
+		return 0, fmt.Errorf("no clashes")
+	}
+	switch value {
+	case "next", "":
+		return (current + 1) % count, nil
+	case "previous":
+		if current <= 0 {
+			return count - 1, nil
+		}
+		return current - 1, nil
+	}
+	i, err := strconv.Atoi(value)
+	if err != nil || i < 0 || i >= count {
+		return 0, fmt.Errorf("invalid clash %s", value)
+	}
+	return i, nil
+}
@@ -0,0 +1,44 @@
+package renderer
+
+import (
+	"testing"
+
+	"github.com/g3n/engine/math32"
+)
+
+func TestClashIndex(t *testing.T) {
+	i, err := clashIndex("next", -1, 3)
+	assert(t, err, nil)
+	assert(t, i, 0)
+	i, _ = clashIndex("next", 2, 3)
+	assert(t, i, 0)
+	i, _ = clashIndex("previous", -1, 3)
+	assert(t, i, 2)
+	i, _ = clashIndex("previous", 2, 3)
+	assert(t, i, 1)
+	i, _ = clashIndex("1", 0, 3)
+	assert(t, i, 1)
+
+	if _, err = clashIndex("3", 0, 3); err == nil {
+		t.Error("index out of range accepted")
+	}
+	if _, err = clashIndex("next", -1, 0); err == nil {
+		t.Error("navigation without clashes accepted")
+	}
+}
+
+func TestClashView(t *testing.T) {
+	bbox := math32.Box3{Min: math32.Vector3{X: 0, Y: 0, Z: 0}, Max: math32.Vector3{X: 2, Y: 2, Z: 2}}
+	view := clashView(bbox, 60)
+	assert(t, view.target, math32.Vector3{X: 1, Y: 1, Z: 1})
+	// the clash fits into the view at twice its bounding radius
+	d := view.position.DistanceTo(&view.target)
+	if math32.Abs(d-2*math32.Sqrt(3)) > 1e-4 {
+		t.Errorf("distance %v", d)
+	}
+	assert(t, view.position.X == view.position.Y && view.position.Y == view.position.Z, true)
+
+	if (Clash{A: "/0/1"}).Validate() == nil {
+		t.Error("clash of one node accepted")
+	}
+}
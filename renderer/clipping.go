@@ -0,0 +1,129 @@
+package renderer
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/g3n/engine/camera"
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/math32"
+)
+
+// clipping planes of the default camera in scene units
+const (
+	defaultNear = 0.01
+	defaultFar  = 1000
+)
+
+// ratio of near to far plane in auto mode, keeping the depth buffer precise enough to avoid z-fighting
+const autoClipRatio = 0.0005
+
+// clippingState holds the mode of the camera clipping planes
+type clippingState struct {
+	auto bool
+}
+
+// clippingPlanes are the distances of the clipping planes sent to the client in model units
+type clippingPlanes struct {
+	Near float32 `json:"near"`
+	Far  float32 `json:"far"`
+	Auto bool    `json:"auto"`
+}
+
+// Clipping sets the near and far plane of the camera in model units, the value is near,far.
+// With auto the planes follow the model bounds, an empty value restores the defaults.
+func (app *RenderingApp) Clipping(cmd Command) {
+	app.clipping.auto = false
+	switch cmd.Val {
+	case "", "default":
+		app.setClipping(defaultNear, defaultFar)
+	case "auto":
+		app.clipping.auto = true
+		app.autoClip("", nil)
+	default:
+		near, far, err := parseClipping(cmd.Val)
+		if err != nil {
+			app.sendMessageToClient("clipping", err.Error())
+			return
+		}
+		app.setClipping(near*ModelScale, far*ModelScale)
+	}
+	cam := app.CameraPersp()
+	app.sendDataToClient("clipping", clippingPlanes{
+		Near: toModelUnits(cam.Near()),
+		Far:  toModelUnits(cam.Far()),
+		Auto: app.clipping.auto,
+	})
+}
+
+// autoClip fits the clipping planes tightly around the model before rendering
+func (app *RenderingApp) autoClip(evname string, ev interface{}) {
+	if !app.clipping.auto || app.modelRoot == nil {
+		return
+	}
+	box, ok := app.culling.boxes[app.modelRoot]
+	if !ok {
+		if box, ok = getWorldBoundingBox([]core.INode{app.modelRoot}); !ok {
+			return
+		}
+	}
+	if app.ground.node != nil {
+		// the reflection below the ground
+		box.Min.Y -= box.Max.Y - box.Min.Y
+	}
+	var position math32.Vector3
+	app.Camera().GetCamera().WorldPosition(&position)
+	near, far := fitClipping(box, position)
+	app.setClipping(near, far)
+}
+
+// setClipping changes the clipping planes of the camera in scene units.
+// The camera has no setters for its planes, so the projection is recreated around the camera node.
+func (app *RenderingApp) setClipping(near, far float32) {
+	cam := app.CameraPersp()
+	if cam.Near() == near && cam.Far() == far {
+		return
+	}
+	projection := camera.NewPerspective(cam.Fov(), float32(app.Width)/float32(app.Height), near, far)
+	projection.Camera = cam.Camera
+	*cam = *projection
+	// the stereo eye is recreated with the new planes
+	app.stereo.eye = nil
+}
+
+// fitClipping returns near and far planes enclosing a box seen from a position
+func fitClipping(box math32.Box3, position math32.Vector3) (float32, float32) {
+	center := box.Center(nil)
+	radius := box.Size(nil).Length() / 2
+	distance := position.DistanceTo(center)
+	far := (distance + radius) * 1.05
+	if far <= 0 {
+		return defaultNear, defaultFar
+	}
+	near := distance - radius
+	if near < far*autoClipRatio {
+		near = far * autoClipRatio
+	}
+	return near, far
+}
+
+// parseClipping parses near,far
+func parseClipping(value string) (float32, float32, error) {
+	s := strings.Split(value, ",")
+	if len(s) != 2 {
+		return 0, 0, fmt.Errorf("invalid clipping %s, expected near,far", value)
+	}
+	near, err := strconv.ParseFloat(strings.TrimSpace(s[0]), 32)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid number %s", s[0])
+	}
+	far, err := strconv.ParseFloat(strings.TrimSpace(s[1]), 32)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid number %s", s[1])
+	}
+	if near <= 0 || far <= near {
+		return 0, 0, fmt.Errorf("clipping planes need 0 < near < far")
+	}
+	return float32(near), float32(far), nil
+}
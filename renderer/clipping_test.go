@@ -0,0 +1,34 @@
+package renderer
+
+import (
+	"testing"
+
+	"github.com/g3n/engine/math32"
+)
+
+func TestFitClipping(t *testing.T) {
+	box := math32.Box3{Min: math32.Vector3{X: -1, Y: -1, Z: -1}, Max: math32.Vector3{X: 1, Y: 1, Z: 1}}
+	radius := box.Size(nil).Length() / 2
+
+	near, far := fitClipping(box, math32.Vector3{Z: 10})
+	assert(t, near, 10-radius)
+	assert(t, far, (10+radius)*1.05)
+
+	// inside the box the near plane keeps a minimum distance
+	near, far = fitClipping(box, math32.Vector3{})
+	assert(t, far, radius*1.05)
+	assert(t, near, far*autoClipRatio)
+}
+
+func TestParseClipping(t *testing.T) {
+	near, far, err := parseClipping("0.1, 500")
+	assert(t, err, nil)
+	assert(t, near, float32(0.1))
+	assert(t, far, float32(500))
+
+	for _, value := range []string{"1", "0,10", "10,1", "a,1"} {
+		if _, _, err := parseClipping(value); err == nil {
+			t.Error("accepted invalid clipping", value)
+		}
+	}
+}
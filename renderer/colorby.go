@@ -0,0 +1,167 @@
+package renderer
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/graphic"
+	"github.com/g3n/engine/material"
+	"github.com/g3n/engine/math32"
+)
+
+// categoryPalette colors distinct attribute values, repeating for more values than colors
+var categoryPalette = []string{
+	"#1f77b4", "#ff7f0e", "#2ca02c", "#d62728", "#9467bd", "#8c564b",
+	"#e377c2", "#7f7f7f", "#bcbd22", "#17becf", "#aec7e8", "#ffbb78",
+}
+
+// colorByState holds graphics colored by a user data attribute
+type colorByState struct {
+	attribute   string
+	categorical bool
+	originals   map[core.INode][]graphic.GraphicMaterial
+}
+
+// LegendEntry is an attribute value and its color
+type LegendEntry struct {
+	Value string `json:"value"`
+	Color string `json:"color"`
+	Count int    `json:"count"` // number of colored graphics
+}
+
+// Legend maps the values of an attribute to colors, numeric attributes have a range colored by the default ramp
+type Legend struct {
+	Attribute string        `json:"attribute"`
+	Type      string        `json:"type"` // categorical or numeric
+	Min       float32       `json:"min,omitempty"`
+	Max       float32       `json:"max,omitempty"`
+	Entries   []LegendEntry `json:"entries"`
+}
+
+// Colorby colors all graphics by a user data attribute as attribute[,categorical] and sends the legend.
+// Numeric attributes are colored by a ramp from their minimum to maximum unless categorical is given.
+// Graphics inherit the attribute of their parents, graphics without it keep their colors. An empty value restores all colors.
+func (app *RenderingApp) Colorby(cmd Command) {
+	s := strings.Split(cmd.Val, ",")
+	attribute := strings.TrimPrefix(strings.TrimSpace(s[0]), "userdata.")
+	categorical := false
+	if len(s) > 1 {
+		if strings.TrimSpace(s[1]) != "categorical" {
+			app.sendMessageToClient("colorby", "unknown option "+s[1])
+			return
+		}
+		categorical = true
+	}
+	app.resetColorBy()
+	app.colorBy = colorByState{attribute: attribute, categorical: categorical}
+	app.applyColorBy()
+}
+
+// applyColorBy colors the graphics of the model by the active attribute
+func (app *RenderingApp) applyColorBy() {
+	c := &app.colorBy
+	if c.attribute == "" || app.modelRoot == nil {
+		return
+	}
+	values := make(map[core.INode]string)
+	forEachGraphic([]core.INode{app.modelRoot}, func(inode core.INode) {
+		if value, ok := inheritedValue(inode, c.attribute); ok {
+			values[inode] = value
+		}
+	})
+	legend, colors := buildLegend(c.attribute, values, c.categorical)
+	materials := make(map[string]*material.Standard)
+	c.originals = make(map[core.INode][]graphic.GraphicMaterial)
+	for inode, value := range values {
+		mat, ok := materials[value]
+		if !ok {
+			color := colors(value)
+			mat = material.NewStandard(&color)
+			materials[value] = mat
+		}
+		gnode := inode.(graphic.IGraphic)
+		gfx := gnode.GetGraphic()
+		c.originals[inode] = append([]graphic.GraphicMaterial(nil), gfx.Materials()...)
+		gfx.ClearMaterials()
+		gfx.AddMaterial(gnode, mat, 0, 0)
+	}
+	app.sendDataToClient("legend", legend)
+}
+
+// resetColorBy restores the original colors of all graphics colored by an attribute
+func (app *RenderingApp) resetColorBy() {
+	for inode, materials := range app.colorBy.originals {
+		gfx := inode.(graphic.IGraphic).GetGraphic()
+		gfx.ClearMaterials()
+		for _, gm := range materials {
+			gfx.AddMaterial(gm.IGraphic(), gm.IMaterial(), 0, 0)
+		}
+	}
+	app.colorBy = colorByState{}
+}
+
+// inheritedValue returns the attribute of a node or its closest parent having it
+func inheritedValue(inode core.INode, attribute string) (string, bool) {
+	for n := inode; n != nil; n = n.GetNode().Parent() {
+		if value, ok := getUserDataValue(n.GetNode().UserData(), attribute); ok {
+			return value, true
+		}
+	}
+	return "", false
+}
+
+// buildLegend returns the legend of the attribute values and a function coloring a value.
+// Values are numeric if all of them are numbers and categorical is false.
+func buildLegend(attribute string, values map[core.INode]string, categorical bool) (Legend, func(string) math32.Color) {
+	counts := make(map[string]int)
+	numbers := make(map[string]float32)
+	for _, value := range values {
+		counts[value]++
+		if n, err := strconv.ParseFloat(value, 32); err == nil {
+			numbers[value] = float32(n)
+		}
+	}
+	distinct := make([]string, 0, len(counts))
+	for value := range counts {
+		distinct = append(distinct, value)
+	}
+	legend := Legend{Attribute: attribute, Type: "categorical", Entries: []LegendEntry{}}
+
+	if !categorical && len(numbers) == len(distinct) && len(distinct) > 0 {
+		sort.Slice(distinct, func(i, j int) bool { return numbers[distinct[i]] < numbers[distinct[j]] })
+		legend.Type = "numeric"
+		legend.Min, legend.Max = numbers[distinct[0]], numbers[distinct[len(distinct)-1]]
+		colors := func(value string) math32.Color {
+			if legend.Max == legend.Min {
+				return DefaultColorRamp.At(0.5)
+			}
+			return DefaultColorRamp.At((numbers[value] - legend.Min) / (legend.Max - legend.Min))
+		}
+		for _, value := range distinct {
+			legend.Entries = append(legend.Entries, LegendEntry{Value: value, Color: hexColor(colors(value)), Count: counts[value]})
+		}
+		return legend, colors
+	}
+
+	sort.Strings(distinct)
+	index := make(map[string]int)
+	for i, value := range distinct {
+		index[value] = i
+	}
+	colors := func(value string) math32.Color {
+		c, _ := parseColor(categoryPalette[index[value]%len(categoryPalette)])
+		return *c
+	}
+	for _, value := range distinct {
+		legend.Entries = append(legend.Entries, LegendEntry{Value: value, Color: hexColor(colors(value)), Count: counts[value]})
+	}
+	return legend, colors
+}
+
+// hexColor formats a color as #rrggbb
+func hexColor(c math32.Color) string {
+	return fmt.Sprintf("#%02x%02x%02x", uint8(clamp01(c.R)*255+0.5), uint8(clamp01(c.G)*255+0.5), uint8(clamp01(c.B)*255+0.5))
+}
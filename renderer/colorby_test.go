@@ -0,0 +1,49 @@
+package renderer
+
+import (
+	"testing"
+
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/math32"
+)
+
+func TestInheritedValue(t *testing.T) {
+	parent := core.NewNode()
+	parent.SetUserData(map[string]interface{}{"category": "MEP"})
+	child := core.NewNode()
+	parent.Add(child)
+	value, ok := inheritedValue(child, "category")
+	assert(t, ok, true)
+	assert(t, value, "MEP")
+	_, ok = inheritedValue(child, "level")
+	assert(t, ok, false)
+}
+
+func TestBuildLegend(t *testing.T) {
+	a, b, c := core.NewNode(), core.NewNode(), core.NewNode()
+	legend, colors := buildLegend("category", map[core.INode]string{a: "Walls", b: "MEP", c: "Walls"}, false)
+	assert(t, legend.Type, "categorical")
+	assert(t, len(legend.Entries), 2)
+	assert(t, legend.Entries[0].Value, "MEP")
+	assert(t, legend.Entries[1].Count, 2)
+	assert(t, legend.Entries[0].Color, categoryPalette[0])
+	if colors("MEP") == colors("Walls") {
+		t.Error("categories share a color")
+	}
+
+	legend, colors = buildLegend("level", map[core.INode]string{a: "10", b: "2", c: "6"}, false)
+	assert(t, legend.Type, "numeric")
+	assert(t, legend.Min, float32(2))
+	assert(t, legend.Max, float32(10))
+	assert(t, legend.Entries[0].Value, "2")
+	assert(t, colors("2"), DefaultColorRamp.At(0))
+	assert(t, colors("6"), DefaultColorRamp.At(0.5))
+
+	legend, _ = buildLegend("level", map[core.INode]string{a: "10", b: "2"}, true)
+	assert(t, legend.Type, "categorical")
+	assert(t, legend.Entries[0].Value, "10")
+}
+
+func TestHexColor(t *testing.T) {
+	assert(t, hexColor(math32.Color{R: 1, G: 0.5, B: 0}), "#ff8000")
+}
@@ -0,0 +1,139 @@
+package renderer
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/g3n/engine/math32"
+	"github.com/g3n/engine/renderer/shaders"
+)
+
+// LinearWorkflow renders all sessions in linear color space, frames are converted to the output gamma of a session when they get encoded.
+// It has to be set before any session starts as the shaders are compiled once per session.
+var LinearWorkflow bool
+
+// rawOutputDefine is set on materials whose colors are data and must not be linearized, e.g. object ids
+const rawOutputDefine = "RAW_OUTPUT"
+
+// outputTransfer converts linear frame values into the color space of the client
+type outputTransfer struct {
+	gamma  float32 // 0 encodes sRGB
+	encode [256]byte
+	decode [256]byte // inverse of encode, for colors drawn onto linear frames
+}
+
+// newOutputTransfer returns the transfer of the output gamma, 0 for sRGB
+func newOutputTransfer(gamma float32) outputTransfer {
+	t := outputTransfer{gamma: gamma}
+	for i := range t.encode {
+		v := float64(i) / 255
+		if gamma == 0 {
+			t.encode[i] = toByte(srgbEncode(v))
+			t.decode[i] = toByte(srgbDecode(v))
+		} else {
+			t.encode[i] = toByte(math.Pow(v, 1/float64(gamma)))
+			t.decode[i] = toByte(math.Pow(v, float64(gamma)))
+		}
+	}
+	return t
+}
+
+// Gamma sets the output color space frames are encoded in when rendering in linear color space, srgb (default) or a display gamma from 1 to 3
+func (app *RenderingApp) Gamma(cmd Command) {
+	if !LinearWorkflow {
+		app.sendMessageToClient("gamma", "output gamma requires the linear workflow")
+		return
+	}
+	gamma, err := parseGamma(cmd.Val)
+	if err != nil {
+		app.sendMessageToClient("gamma", err.Error())
+		return
+	}
+	app.output = newOutputTransfer(gamma)
+	// the next frame must be sent even if it did not change
+	md5SumBuffer = [16]byte{}
+	app.sendMessageToClient("gamma", cmd.Val)
+}
+
+// useLinearShaders replaces the default shaders of the session so they output linear colors.
+// The physical shader skips its gamma correction, all others decode their output as sRGB unless the material sets RAW_OUTPUT.
+func (app *RenderingApp) useLinearShaders() {
+	for _, name := range shaders.Shaders() {
+		if !strings.HasSuffix(name, "_fragment") {
+			continue
+		}
+		app.Renderer().AddShader(name, linearShader(name, shaders.ShaderSource(name)))
+	}
+}
+
+// linearShader rewrites the source of a fragment shader to output linear colors
+func linearShader(name, source string) string {
+	if name == "physical_fragment" {
+		return strings.Replace(source, "pow(color,vec3(1.0/2.2))", "color", 1)
+	}
+	source = strings.Replace(source, "void main() {", "void displayMain() {", 1)
+	return source + `
+void main() {
+    displayMain();
+#ifndef ` + rawOutputDefine + `
+    FragColor.rgb = pow(FragColor.rgb, vec3(2.2));
+#endif
+}
+`
+}
+
+// apply converts the rgb values of rgba pixels from linear into the output color space
+func (t *outputTransfer) apply(pix []byte) {
+	for i := 0; i+3 < len(pix); i += 4 {
+		pix[i] = t.encode[pix[i]]
+		pix[i+1] = t.encode[pix[i+1]]
+		pix[i+2] = t.encode[pix[i+2]]
+	}
+}
+
+// linearColor returns a color as it has to be blended into a linear frame to appear unchanged in the output
+func (t *outputTransfer) linearColor(c math32.Color) math32.Color {
+	decode := func(v float32) float32 {
+		if t.gamma == 0 {
+			return float32(srgbDecode(float64(v)))
+		}
+		return math32.Pow(v, t.gamma)
+	}
+	return math32.Color{R: decode(c.R), G: decode(c.G), B: decode(c.B)}
+}
+
+// parseGamma parses srgb or a gamma from 1 to 3, srgb returns 0
+func parseGamma(value string) (float32, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if value == "" || value == "srgb" {
+		return 0, nil
+	}
+	gamma, err := strconv.ParseFloat(value, 32)
+	if err != nil || gamma < 1 || gamma > 3 {
+		return 0, fmt.Errorf("invalid gamma %s, expected srgb or 1 to 3", value)
+	}
+	return float32(gamma), nil
+}
+
+// srgbEncode converts a linear value from 0 to 1 to sRGB
+func srgbEncode(v float64) float64 {
+	if v <= 0.0031308 {
+		return 12.92 * v
+	}
+	return 1.055*math.Pow(v, 1/2.4) - 0.055
+}
+
+// srgbDecode converts a sRGB value from 0 to 1 to linear
+func srgbDecode(v float64) float64 {
+	if v <= 0.04045 {
+		return v / 12.92
+	}
+	return math.Pow((v+0.055)/1.055, 2.4)
+}
+
+// toByte rounds a value from 0 to 1 to a byte
+func toByte(v float64) byte {
+	return byte(math.Max(0, math.Min(255, math.Round(v*255))))
+}
@@ -0,0 +1,52 @@
+package renderer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/g3n/engine/math32"
+)
+
+func TestParseGamma(t *testing.T) {
+	gamma, err := parseGamma("")
+	assert(t, err, nil)
+	assert(t, gamma, float32(0))
+	gamma, err = parseGamma(" sRGB ")
+	assert(t, err, nil)
+	assert(t, gamma, float32(0))
+	gamma, err = parseGamma("2.4")
+	assert(t, err, nil)
+	assert(t, gamma, float32(2.4))
+	_, err = parseGamma("0.5")
+	assert(t, err != nil, true)
+	_, err = parseGamma("adobe")
+	assert(t, err != nil, true)
+}
+
+func TestOutputTransfer(t *testing.T) {
+	srgb := newOutputTransfer(0)
+	assert(t, srgb.encode[0], byte(0))
+	assert(t, srgb.encode[255], byte(255))
+	// linear mid grey is brighter in sRGB
+	assert(t, srgb.encode[128], byte(188))
+	assert(t, srgb.decode[188], byte(128))
+
+	pix := []byte{128, 0, 255, 128}
+	srgb.apply(pix)
+	assert(t, string(pix), string([]byte{188, 0, 255, 128}))
+
+	gamma := newOutputTransfer(2)
+	assert(t, gamma.encode[64], byte(128))
+	c := gamma.linearColor(math32.Color{R: 1, G: 0.5})
+	assert(t, c, math32.Color{R: 1, G: 0.25})
+}
+
+func TestLinearShader(t *testing.T) {
+	physical := linearShader("physical_fragment", "FragColor = vec4(pow(color,vec3(1.0/2.2)), baseColor.a);")
+	assert(t, physical, "FragColor = vec4(color, baseColor.a);")
+
+	standard := linearShader("standard_fragment", "void main() {\n    FragColor = vec4(1);\n}\n")
+	assert(t, strings.Count(standard, "void main() {"), 1)
+	assert(t, strings.Contains(standard, "void displayMain() {"), true)
+	assert(t, strings.Contains(standard, "#ifndef RAW_OUTPUT"), true)
+}
@@ -4,8 +4,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
+	"sync/atomic"
 
 	"github.com/g3n/engine/window"
 )
@@ -18,6 +20,32 @@ type Command struct {
 	Val   string
 	Moved bool
 	Ctrl  bool
+	Shift bool
+	Alt   bool
+	Time  int64 // optional client time in unix milliseconds, echoed by frame timestamps
+}
+
+// mods returns the modifier keys held while the command was sent
+func (cmd Command) mods() window.ModifierKey {
+	var mods window.ModifierKey
+	if cmd.Shift {
+		mods |= window.ModShift
+	}
+	if cmd.Ctrl {
+		mods |= window.ModControl
+	}
+	if cmd.Alt {
+		mods |= window.ModAlt
+	}
+	return mods
+}
+
+// navigationButton maps shift with the left button to the right button, panning like desktop viewers
+func navigationButton(button window.MouseButton, mods window.ModifierKey) window.MouseButton {
+	if button == window.MouseButtonLeft && mods&window.ModShift != 0 {
+		return window.MouseButtonRight
+	}
+	return button
 }
 
 // mapMouseButton maps js mouse buttons to window mouse buttons
@@ -52,74 +80,213 @@ func mapKey(value string) window.Key {
 
 // commandLoop listens for incoming commands and forwards them to the rendering app
 func (app *RenderingApp) commandLoop() {
-	t := reflect.TypeOf(app)
-	v := reflect.ValueOf(app)
-	k := reflect.TypeOf(Command{}).Kind()
+	app.withoutCulling(app.replayJournal)
 	for {
-		message := <-app.cCommands
+		// selection changes of the last command are passed on before waiting for the next one
+		app.shareSelection()
+		var message []byte
+		select {
+		case message = <-app.cCommands:
+		case update := <-app.commandUpdates:
+			app.withoutCulling(update)
+			continue
+		case <-app.quit:
+			return
+		}
 
 		// retrieve command data from payload
 		cmd := Command{}
 		err := json.Unmarshal(message, &cmd)
 		if err != nil {
-			app.Log().Error(err.Error())
+			app.log.Error(err.Error())
 		}
 
 		// no command should be directed to orbit control
 		if cmd.Cmd == "" {
 			cmd.Cmd = "Navigate"
 		} else {
-			app.Log().Info("received command: %v", cmd)
+			app.log.Info("received command: %v", cmd)
 		}
+		// commands run by scenarios are restored by replaying the scenario
+		app.journalCommand(cmd)
+		app.withoutCulling(func() { app.runCommand(cmd) })
+	}
+}
 
-		// if a func with a matching command name exists,
-		// call it with two args: the app itself and the command payload
-		m, found := t.MethodByName(cmd.Cmd)
-		if found {
-			// make sure we got the right func with Command argument
-			// otherwise Func.Call will panic
-			if m.Type.NumIn() == 2 {
-				if m.Type.In(1).Kind() == k {
-					args := []reflect.Value{v, reflect.ValueOf(cmd)}
-					m.Func.Call(args)
-				}
+// queueCommandUpdate runs a function on the command goroutine between two commands.
+// It blocks while the queue is full, so the render thread must not call it.
+func (app *RenderingApp) queueCommandUpdate(update func()) {
+	select {
+	case app.commandUpdates <- update:
+	case <-app.quit:
+	}
+}
+
+// renderSync runs a function on the render thread and waits for it, so it may use
+// the state of the command goroutine. Returns false if the session closed.
+func (app *RenderingApp) renderSync(update func()) bool {
+	done := make(chan struct{})
+	select {
+	case app.sceneUpdates <- func() { update(); close(done) }:
+	case <-app.quit:
+		return false
+	}
+	select {
+	case <-done:
+		return true
+	case <-app.quit:
+		return false
+	}
+}
+
+// runCommand calls the custom command handler or the method of the rendering app named by the command
+func (app *RenderingApp) runCommand(cmd Command) {
+	app.auditCommand(cmd)
+	if cmd.Time != 0 {
+		atomic.StoreInt64(&app.timestamps.input, cmd.Time)
+	}
+	// frames cached before the command changed the scene are dropped once it ran
+	if !keepsFrames(cmd) {
+		defer app.frameCache.invalidate()
+	}
+
+	// custom commands registered by downstream projects
+	if handler, found := getCommandHandler(cmd.Cmd); found {
+		handler(app, cmd)
+		return
+	}
+
+	// if a func with a matching command name exists,
+	// call it with two args: the app itself and the command payload
+	m, found := reflect.TypeOf(app).MethodByName(cmd.Cmd)
+	if found {
+		// make sure we got the right func with Command argument
+		// otherwise Func.Call will panic
+		if m.Type.NumIn() == 2 {
+			if m.Type.In(1) == reflect.TypeOf(cmd) {
+				args := []reflect.Value{reflect.ValueOf(app), reflect.ValueOf(cmd)}
+				m.Func.Call(args)
 			}
-		} else {
-			app.Log().Info("Unknown Command: " + cmd.Cmd)
 		}
+	} else {
+		app.log.Warn("unknown command: %s", cmd.Cmd)
 	}
 }
 
 // Navigate orbit navigation
 func (app *RenderingApp) Navigate(cmd Command) {
+	if app.viewportInput(&cmd) {
+		return
+	}
 	cev := window.CursorEvent{Xpos: cmd.X, Ypos: cmd.Y}
+	app.trackCursor(cmd.X, cmd.Y)
+	if app.drag.active {
+		app.dragTo(cmd.X, cmd.Y)
+		return
+	}
 	app.Orbit().OnCursorPos(&cev)
 }
 
 // Mousedown triggers a mousedown event
 func (app *RenderingApp) Mousedown(cmd Command) {
+	if app.viewportInput(&cmd) {
+		return
+	}
 	mev := window.MouseEvent{Xpos: cmd.X, Ypos: cmd.Y,
 		Action: window.Press,
-		Button: mapMouseButton(cmd.Val)}
+		Button: navigationButton(mapMouseButton(cmd.Val), cmd.mods()),
+		Mods:   cmd.mods()}
+	if app.startDrag(cmd) {
+		return
+	}
 	if cmd.Moved {
 		app.imageSettings.isNavigating = true
 	}
+	app.stopTransition()
 	app.Orbit().OnMouse(&mev)
 }
 
+// scrollFactor converts browser wheel deltas to orbit control scroll offsets at sensitivity 1
+const scrollFactor = float32(10.0)
+
+// DefaultZoomSensitivity scales the zoom of new sessions per wheel step
+var DefaultZoomSensitivity float32 = 1
+
+// DefaultNaturalScrolling inverts the zoom direction of new sessions
+var DefaultNaturalScrolling = false
+
+// zoomSettings holds the zoom sensitivity of a session
+type zoomSettings struct {
+	sensitivity float32
+	natural     bool
+}
+
 // Zoom in/out scene
 func (app *RenderingApp) Zoom(cmd Command) {
-	scrollFactor := float32(10.0)
-	mev := window.ScrollEvent{Xoffset: cmd.X, Yoffset: -cmd.Y / scrollFactor}
+	if app.viewportInput(&cmd) {
+		return
+	}
+	mev := window.ScrollEvent{Xoffset: cmd.X, Yoffset: app.zoom.offset(cmd.Y)}
+	app.stopTransition()
 	app.Orbit().OnScroll(&mev)
 }
 
+// Zoomspeed sets the zoom sensitivity as sensitivity[,natural], e.g. 0.2,natural for trackpads sending many small deltas
+// with inverted direction. An empty value restores the server defaults.
+func (app *RenderingApp) Zoomspeed(cmd Command) {
+	zoom, err := parseZoomSettings(cmd.Val)
+	if err != nil {
+		app.sendMessageToClient("zoomspeed", err.Error())
+		return
+	}
+	app.zoom = zoom
+}
+
+// offset returns the orbit control scroll offset of a browser wheel delta
+func (z zoomSettings) offset(delta float32) float32 {
+	offset := -delta / scrollFactor * z.sensitivity
+	if z.natural {
+		return -offset
+	}
+	return offset
+}
+
+// parseZoomSettings parses sensitivity[,natural], an empty value returns the defaults
+func parseZoomSettings(value string) (zoomSettings, error) {
+	zoom := zoomSettings{sensitivity: DefaultZoomSensitivity, natural: DefaultNaturalScrolling}
+	if value == "" {
+		return zoom, nil
+	}
+	s := strings.Split(value, ",")
+	sensitivity, err := strconv.ParseFloat(strings.TrimSpace(s[0]), 32)
+	if err != nil || sensitivity <= 0 {
+		return zoom, fmt.Errorf("invalid zoom sensitivity %s", s[0])
+	}
+	zoom.sensitivity, zoom.natural = float32(sensitivity), false
+	if len(s) > 1 {
+		if len(s) > 2 || strings.TrimSpace(s[1]) != "natural" {
+			return zoom, fmt.Errorf("invalid zoom setting %s, expected sensitivity[,natural]", value)
+		}
+		zoom.natural = true
+	}
+	return zoom, nil
+}
+
 // Mouseup event
 func (app *RenderingApp) Mouseup(cmd Command) {
+	if app.viewportInput(&cmd) {
+		app.imageSettings.isNavigating = false
+		return
+	}
 	mev := window.MouseEvent{Xpos: cmd.X, Ypos: cmd.Y,
 		Action: window.Release,
-		Button: mapMouseButton(cmd.Val)}
+		Button: navigationButton(mapMouseButton(cmd.Val), cmd.mods()),
+		Mods:   cmd.mods()}
 
+	if app.drag.active {
+		app.endDrag()
+		return
+	}
 	app.imageSettings.isNavigating = false
 	app.Orbit().OnMouse(&mev)
 
@@ -131,8 +298,14 @@ func (app *RenderingApp) Mouseup(cmd Command) {
 
 // Hide selected element
 func (app *RenderingApp) Hide(cmd Command) {
+	var names []string
 	for inode := range app.selectionBuffer {
 		inode.GetNode().SetVisible(false)
+		names = append(names, inode.GetNode().Name())
+	}
+	if len(names) > 0 {
+		sort.Strings(names)
+		app.audit("hidden", strings.Join(names, ","), true)
 	}
 	app.resetSelection()
 }
@@ -140,26 +313,27 @@ func (app *RenderingApp) Hide(cmd Command) {
 // Unhide all hidden elements
 func (app *RenderingApp) Unhide(cmd Command) {
 	for _, node := range app.nodeBuffer {
-		node.SetVisible(true)
+		node.GetNode().SetVisible(true)
 	}
+	app.applyFilters()
 }
 
 // Send element userdata to client
 func (app *RenderingApp) Userdata(cmd Command) {
 	if node, ok := app.nodeBuffer[cmd.Val]; ok {
-		app.sendMessageToClient("userdata", fmt.Sprintf("%v", node.UserData()))
+		app.sendMessageToClient("userdata", fmt.Sprintf("%v", node.GetNode().UserData()))
 	}
 }
 
 // Keydown event
 func (app *RenderingApp) Keydown(cmd Command) {
-	kev := window.KeyEvent{Action: window.Press, Mods: 0, Keycode: mapKey(cmd.Val)}
+	kev := window.KeyEvent{Action: window.Press, Mods: cmd.mods(), Keycode: mapKey(cmd.Val)}
 	app.Orbit().OnKey(&kev)
 }
 
 // Keyup event
 func (app *RenderingApp) Keyup(cmd Command) {
-	kev := window.KeyEvent{Action: window.Release, Mods: 0, Keycode: mapKey(cmd.Val)}
+	kev := window.KeyEvent{Action: window.Release, Mods: cmd.mods(), Keycode: mapKey(cmd.Val)}
 	app.Orbit().OnKey(&kev)
 }
 
@@ -229,9 +403,22 @@ func (app *RenderingApp) Quality(cmd Command) {
 	}
 }
 
-// Enocder settings
+// Encoder switches the image encoder, a second encoder is used while navigating, e.g. png,jpeg
 func (app *RenderingApp) Encoder(cmd Command) {
-	app.imageSettings.encoder = cmd.Val
+	s := strings.Split(cmd.Val, ",")
+	still, nav := strings.TrimSpace(s[0]), ""
+	if len(s) > 1 {
+		nav = strings.TrimSpace(s[1])
+	}
+	if len(s) > 2 || !IsEncoder(still) || (nav != "" && !IsEncoder(nav)) {
+		app.sendMessageToClient("encoder", "invalid encoder "+cmd.Val)
+		return
+	}
+	app.imageSettings.encoder = still
+	app.imageSettings.navEncoder = nav
+	// the next frame must be sent even if it did not change
+	md5SumBuffer = [16]byte{}
+	app.sendMessageToClient("encoder", cmd.Val)
 }
 
 // Fov applies field of view
@@ -253,7 +440,7 @@ func (app *RenderingApp) Debugmode(cmd Command) {
 
 // Closes connection
 func (app *RenderingApp) Close(cmd Command) {
-	app.Log().Info("close")
+	app.log.Info("close")
 	app.Window().SetShouldClose(true)
 }
 
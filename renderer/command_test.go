@@ -31,3 +31,56 @@ func TestGetValueInRange(t *testing.T) {
 	assert(t, getValueInRange(3, 1, 5), 3)
 	assert(t, getValueInRange(0, 1, 5), 1)
 }
+
+func TestGetEncoder(t *testing.T) {
+	i := ImageSettings{encoder: "png"}
+	assert(t, i.getEncoder(), "png")
+	i.isNavigating = true
+	assert(t, i.getEncoder(), "png")
+	i.navEncoder = "libjpeg"
+	assert(t, i.getEncoder(), "libjpeg")
+	i.isNavigating = false
+	assert(t, i.getEncoder(), "png")
+
+	assert(t, IsEncoder("png-interlaced"), true)
+	assert(t, IsEncoder("webp"), false)
+}
+
+func TestZoomSettings(t *testing.T) {
+	zoom, err := parseZoomSettings("")
+	assert(t, err, nil)
+	assert(t, zoom, zoomSettings{sensitivity: DefaultZoomSensitivity, natural: DefaultNaturalScrolling})
+	assert(t, zoom.offset(100), float32(-10))
+
+	zoom, err = parseZoomSettings("0.5,natural")
+	assert(t, err, nil)
+	assert(t, zoom.offset(100), float32(5))
+
+	for _, value := range []string{"fast", "0", "1,inverted", "1,natural,x"} {
+		if _, err = parseZoomSettings(value); err == nil {
+			t.Error("invalid zoom setting accepted", value)
+		}
+	}
+}
+
+func TestCommandMods(t *testing.T) {
+	assert(t, Command{}.mods(), window.ModifierKey(0))
+	assert(t, Command{Shift: true}.mods(), window.ModShift)
+	assert(t, Command{Ctrl: true, Alt: true}.mods(), window.ModControl|window.ModAlt)
+
+	// shift drags with the left button pan
+	assert(t, navigationButton(window.MouseButtonLeft, window.ModShift), window.MouseButtonRight)
+	assert(t, navigationButton(window.MouseButtonLeft, window.ModShift|window.ModControl), window.MouseButtonRight)
+	assert(t, navigationButton(window.MouseButtonLeft, window.ModControl), window.MouseButtonLeft)
+	assert(t, navigationButton(window.MouseButtonMiddle, window.ModShift), window.MouseButtonMiddle)
+}
+
+func TestRenderSync(t *testing.T) {
+	app := &RenderingApp{sceneUpdates: make(chan func(), 1), quit: make(chan struct{})}
+	go func() { (<-app.sceneUpdates)() }()
+	ran := false
+	assert(t, app.renderSync(func() { ran = true }), true)
+	assert(t, ran, true)
+	close(app.quit)
+	assert(t, app.renderSync(func() {}), false)
+}
@@ -0,0 +1,142 @@
+package renderer
+
+import (
+	"strconv"
+
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/gls"
+)
+
+// defaultSplit shows half of each scene state
+const defaultSplit = 0.5
+
+// compareState holds the second model of an A/B comparison
+type compareState struct {
+	model string
+	root  core.INode
+	split float32 // horizontal slider position from 0 (all B) to 1 (all A)
+}
+
+// Compare loads a second model, e.g. a design option, shown right of the slider with the same camera.
+// The model is loaded from the directory of the session model or an allowed remote source, an empty value ends the comparison.
+func (app *RenderingApp) Compare(cmd Command) {
+	update := app.removeComparison
+	if cmd.Val != "" {
+		path, err := resolveModel(app.modelpath, cmd.Val)
+		if err != nil {
+			app.sendMessageToClient("compare", err.Error())
+			return
+		}
+		update = func() {
+			if err := app.loadComparison(path); err != nil {
+				app.log.Warn("compare: %v", err)
+				app.sendMessageToClient("compare", err.Error())
+				return
+			}
+			app.sendMessageToClient("compare", path)
+		}
+	}
+	select {
+	case app.sceneUpdates <- update:
+	case <-app.quit:
+	}
+}
+
+// Compareslider sets the slider position between 0 and 1 from the left edge of the frame
+func (app *RenderingApp) Compareslider(cmd Command) {
+	split, err := strconv.ParseFloat(cmd.Val, 32)
+	if err != nil || split < 0 || split > 1 {
+		app.sendMessageToClient("compareslider", "invalid slider position "+cmd.Val)
+		return
+	}
+	app.compare.split = float32(split)
+}
+
+// loadComparison replaces the second model, it stays hidden except for the comparison pass
+func (app *RenderingApp) loadComparison(fpath string) error {
+	g, err := app.parseModel(fpath)
+	if err != nil {
+		return err
+	}
+	defaultSceneIdx := 0
+	if g.Scene != nil {
+		defaultSceneIdx = *g.Scene
+	}
+	downscaleTextures(g, app.loadOptions.MaxTexture)
+	n, err := g.LoadScene(defaultSceneIdx)
+	if err != nil {
+		return err
+	}
+	shareMeshGeometry(g)
+	setUserData(g)
+	decimateScene(n, app.loadOptions)
+	n.GetNode().SetScale(ModelScale, ModelScale, ModelScale)
+	n.GetNode().SetVisible(false)
+
+	app.removeComparison()
+	app.Scene().Add(n)
+	app.compare.model, app.compare.root = fpath, n
+	return nil
+}
+
+// removeComparison disposes the second model
+func (app *RenderingApp) removeComparison() {
+	if app.compare.root == nil {
+		return
+	}
+	app.Scene().Remove(app.compare.root)
+	app.compare.root.GetNode().DisposeChildren(true)
+	app.compare.root.Dispose()
+	app.compare.model, app.compare.root = "", nil
+}
+
+// composeComparison renders the second model with the camera of the frame
+// and copies it into the pixels right of the slider
+func (app *RenderingApp) composeComparison(pix []byte) {
+	w, h := app.Width, app.Height
+	hidden := []core.INode{app.modelRoot}
+	if app.shadows.mesh != nil {
+		hidden = append(hidden, app.shadows.mesh)
+	}
+	if app.ground.node != nil {
+		hidden = append(hidden, app.ground.node)
+	}
+	visible := make([]bool, len(hidden))
+	for i, inode := range hidden {
+		visible[i] = inode.GetNode().Visible()
+		inode.GetNode().SetVisible(false)
+	}
+	app.compare.root.GetNode().SetVisible(true)
+	if _, err := app.Renderer().Render(app.Camera()); err != nil {
+		app.log.Error("comparison pass failed: %v", err)
+	}
+	other := app.Gl().ReadPixels(0, 0, w, h, gls.RGBA, gls.UNSIGNED_BYTE)
+	if app.fog.mode != fogOff {
+		// the depth buffer holds the second model now
+		other = append([]byte(nil), other...)
+		app.applyFog(other)
+	}
+	app.compare.root.GetNode().SetVisible(false)
+	for i, inode := range hidden {
+		inode.GetNode().SetVisible(visible[i])
+	}
+	splitImages(pix, other, w, h, int(app.compare.split*float32(w)))
+}
+
+// splitImages copies the columns from x of src into dst and draws a divider at x
+func splitImages(dst, src []byte, w, h, x int) {
+	if x < 0 {
+		x = 0
+	} else if x > w {
+		x = w
+	}
+	for y := 0; y < h; y++ {
+		row := y * w * 4
+		copy(dst[row+x*4:row+w*4], src[row+x*4:row+w*4])
+		for d := x - 1; d <= x; d++ {
+			if d >= 0 && d < w {
+				copy(dst[row+d*4:row+d*4+4], []byte{64, 64, 64, 255})
+			}
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package renderer
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSplitImages(t *testing.T) {
+	w, h := 8, 2
+	a := bytes.Repeat([]byte{255, 0, 0, 255}, w*h)
+	b := bytes.Repeat([]byte{0, 0, 255, 255}, w*h)
+	splitImages(a, b, w, h, 4)
+	for y := 0; y < h; y++ {
+		row := y * w * 4
+		assert(t, a[row], byte(255))           // left of the slider stays A
+		assert(t, a[row+2*4], byte(255))       // A
+		assert(t, a[row+3*4], byte(64))        // divider
+		assert(t, a[row+4*4], byte(64))        // divider
+		assert(t, a[row+5*4+2], byte(255))     // B
+		assert(t, a[row+(w-1)*4+2], byte(255)) // B
+		assert(t, a[row+(w-1)*4], byte(0))
+	}
+
+	// the slider at the edges shows one state only
+	a = bytes.Repeat([]byte{255, 0, 0, 255}, w*h)
+	splitImages(a, b, w, h, -3)
+	assert(t, a[4*4], byte(0))
+	a = bytes.Repeat([]byte{255, 0, 0, 255}, w*h)
+	splitImages(a, b, w, h, w)
+	assert(t, a[4*4], byte(255))
+}
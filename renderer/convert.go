@@ -0,0 +1,88 @@
+package renderer
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/loader/collada"
+	"github.com/g3n/engine/loader/gltf"
+	"github.com/g3n/engine/loader/obj"
+)
+
+// ConvertFormats lists the file extensions accepted by ConvertModel
+var ConvertFormats = []string{".obj", ".dae", ".gltf", ".glb"}
+
+// ConvertModel reads a model in one of the ConvertFormats and writes it as glTF document.
+// Meshes are simplified according to the options like on load.
+// Models have to be self-contained, references to external files are rejected or ignored.
+func ConvertModel(r io.Reader, ext string, options LoadOptions, w io.Writer) error {
+	var root core.INode
+	switch strings.ToLower(ext) {
+	case ".obj":
+		// an empty material reader keeps the decoder from opening mtllib files
+		dec, err := obj.DecodeReader(r, strings.NewReader(""))
+		if err != nil {
+			return err
+		}
+		group, err := dec.NewGroup()
+		if err != nil {
+			return err
+		}
+		root = group
+	case ".dae":
+		dec, err := collada.DecodeReader(r)
+		if err != nil {
+			return err
+		}
+		root, err = dec.NewScene()
+		if err != nil {
+			return err
+		}
+	case ".gltf", ".glb":
+		var g *gltf.GLTF
+		var err error
+		if strings.ToLower(ext) == ".gltf" {
+			g, err = gltf.ParseJSONReader(r, "")
+		} else {
+			g, err = gltf.ParseBinReader(r, "")
+		}
+		if err != nil {
+			return err
+		}
+		if err := checkEmbedded(g); err != nil {
+			return err
+		}
+		scene := 0
+		if g.Scene != nil {
+			scene = *g.Scene
+		}
+		root, err = g.LoadScene(scene)
+		if err != nil {
+			return err
+		}
+		shareMeshGeometry(g)
+		setUserData(g)
+	default:
+		return fmt.Errorf("unsupported format: %s", ext)
+	}
+
+	decimateScene(root, options)
+	return writeGLTF(w, root)
+}
+
+// checkEmbedded returns an error if a gltf document references external files
+func checkEmbedded(g *gltf.GLTF) error {
+	for _, b := range g.Buffers {
+		if b.Uri != "" && !strings.HasPrefix(b.Uri, "data:") {
+			return fmt.Errorf("external buffer not supported: %s", b.Uri)
+		}
+	}
+	for _, img := range g.Images {
+		if img.Uri != "" && !strings.HasPrefix(img.Uri, "data:") {
+			return fmt.Errorf("external image not supported: %s", img.Uri)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package renderer
+
+import (
+	"time"
+)
+
+// coordinateInterval is the interval for checking an idle cursor
+const coordinateInterval = 500 * time.Millisecond
+
+// cursorState holds the last known cursor position
+type cursorState struct {
+	x, y     float32
+	moved    time.Time
+	reported bool
+}
+
+// Coordinates toggles the world coordinate readout under the idle cursor
+func (app *RenderingApp) Coordinates(cmd Command) {
+	app.showCoordinates = !app.showCoordinates
+}
+
+// trackCursor stores the cursor position for the coordinate readout
+func (app *RenderingApp) trackCursor(x float32, y float32) {
+	app.cursor = cursorState{x: x, y: y, moved: time.Now()}
+}
+
+// reportCoordinates sends the world coordinates under the cursor
+// once the cursor has been idle for a full interval
+func (app *RenderingApp) reportCoordinates(arg interface{}) {
+	if !app.showCoordinates || app.cursor.reported || app.cursor.moved.IsZero() {
+		return
+	}
+	if time.Since(app.cursor.moved) < coordinateInterval {
+		return
+	}
+	app.cursor.reported = true
+	hit := app.pick(app.cursor.x, app.cursor.y)
+	if hit == nil {
+		app.sendDataToClient("coordinates", nil)
+		return
+	}
+	app.sendDataToClient("coordinates", hit)
+}
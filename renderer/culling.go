@@ -0,0 +1,127 @@
+package renderer
+
+import (
+	"sync"
+
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/graphic"
+	"github.com/g3n/engine/math32"
+)
+
+// cullingState holds the world bounding boxes of group nodes for hierarchical frustum culling.
+// Graphics are culled individually by the engine, culling whole groups
+// saves traversing and testing their children on large scenes.
+// The engine skips hidden nodes only, so culled nodes are hidden for the frame and kept apart from the nodes
+// hidden by the user. Commands wait for the frame, so they never see culled nodes as hidden.
+type cullingState struct {
+	disabled bool
+	dragging bool // set while nodes are dragged, their boxes are outdated until the drop
+	boxes    map[core.INode]math32.Box3
+	culled   []*core.Node
+	frame    sync.Mutex // held by the render thread while culled nodes are hidden
+	locked   bool
+	last     int // number of nodes culled in the last frame
+}
+
+// Culling toggles hierarchical frustum culling
+func (app *RenderingApp) Culling(cmd Command) {
+	app.culling.disabled = !app.culling.disabled
+}
+
+// buildCullingBoxes computes the world bounding boxes of all group nodes below root
+func (app *RenderingApp) buildCullingBoxes(root core.INode) {
+	root.GetNode().UpdateMatrixWorld()
+	app.culling.boxes = make(map[core.INode]math32.Box3)
+	getSubtreeBox(root, app.culling.boxes)
+}
+
+// getSubtreeBox returns the world bounding box of a node and its children
+// and stores boxes of nodes having children
+func getSubtreeBox(inode core.INode, boxes map[core.INode]math32.Box3) (math32.Box3, bool) {
+	var bbox math32.Box3
+	bbox.MakeEmpty()
+	found := false
+	if gnode, ok := inode.(graphic.IGraphic); ok && gnode.Renderable() {
+		bbox = gnode.GetGeometry().BoundingBox()
+		matrixWorld := inode.GetNode().MatrixWorld()
+		bbox.ApplyMatrix4(&matrixWorld)
+		found = true
+	}
+	children := inode.GetNode().Children()
+	for _, child := range children {
+		box, ok := getSubtreeBox(child, boxes)
+		if ok {
+			bbox.Union(&box)
+			found = true
+		}
+	}
+	if found && len(children) > 0 {
+		boxes[inode] = bbox
+	}
+	return bbox, found
+}
+
+// cullScene hides group nodes outside the camera frustum before rendering
+func (app *RenderingApp) cullScene(evname string, ev interface{}) {
+	if app.culling.disabled || app.culling.dragging || app.culling.boxes == nil {
+		return
+	}
+	// a running command keeps its view of the scene, the frame is rendered without group culling
+	if !app.culling.frame.TryLock() {
+		return
+	}
+	app.culling.locked = true
+	frustum := app.cameraFrustum()
+	for _, child := range app.Scene().Children() {
+		app.cullNode(child, frustum)
+	}
+}
+
+// cameraFrustum returns the view frustum of the camera
+func (app *RenderingApp) cameraFrustum() *math32.Frustum {
+	var view, proj math32.Matrix4
+	app.Camera().ViewMatrix(&view)
+	app.Camera().ProjMatrix(&proj)
+	proj.Multiply(&view)
+	return math32.NewFrustumFromMatrix(&proj)
+}
+
+// cullNode hides a visible group node if it is outside the frustum, otherwise checks its children
+func (app *RenderingApp) cullNode(inode core.INode, frustum *math32.Frustum) {
+	node := inode.GetNode()
+	if !node.Visible() {
+		return
+	}
+	box, ok := app.culling.boxes[inode]
+	if !ok {
+		return
+	}
+	if !frustum.IntersectsBox(&box) {
+		node.SetVisible(false)
+		app.culling.culled = append(app.culling.culled, node)
+		return
+	}
+	for _, child := range node.Children() {
+		app.cullNode(child, frustum)
+	}
+}
+
+// restoreCulled shows all nodes hidden by culling after rendering and lets commands run again
+func (app *RenderingApp) restoreCulled(evname string, ev interface{}) {
+	for _, node := range app.culling.culled {
+		node.SetVisible(true)
+	}
+	app.culling.last = len(app.culling.culled)
+	app.culling.culled = app.culling.culled[:0]
+	if app.culling.locked {
+		app.culling.locked = false
+		app.culling.frame.Unlock()
+	}
+}
+
+// withoutCulling runs a command while no culled nodes are hidden
+func (app *RenderingApp) withoutCulling(run func()) {
+	app.culling.frame.Lock()
+	defer app.culling.frame.Unlock()
+	run()
+}
@@ -0,0 +1,21 @@
+package renderer
+
+import (
+	"testing"
+
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/math32"
+)
+
+func TestGetSubtreeBox(t *testing.T) {
+	root := core.NewNode()
+	group := core.NewNode()
+	group.Add(core.NewNode())
+	root.Add(group)
+
+	boxes := make(map[core.INode]math32.Box3)
+	_, found := getSubtreeBox(root, boxes)
+	// nodes without graphics have no bounds
+	assert(t, found, false)
+	assert(t, len(boxes), 0)
+}
@@ -0,0 +1,127 @@
+package renderer
+
+import (
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/geometry"
+	"github.com/g3n/engine/graphic"
+	"github.com/g3n/engine/math32"
+)
+
+// decimates returns true if meshes should be simplified at all
+func (o LoadOptions) decimates() bool {
+	return o.MaxTriangles > 0 || o.Tolerance > 0
+}
+
+// number of cell size refinements when searching for a triangle budget
+const decimationSteps = 16
+
+// decimateScene simplifies all oversized meshes below root.
+// Shared geometries are simplified once. It returns the number of removed triangles.
+func decimateScene(root core.INode, options LoadOptions) int {
+	if !options.decimates() {
+		return 0
+	}
+	removed := 0
+	visited := make(map[*geometry.Geometry]bool)
+	forEachGraphic([]core.INode{root}, func(inode core.INode) {
+		mesh, ok := inode.(*graphic.Mesh)
+		if !ok {
+			return
+		}
+		geom := mesh.GetGeometry()
+		if visited[geom] {
+			return
+		}
+		visited[geom] = true
+		removed += decimateGeometry(geom, options)
+	})
+	return removed
+}
+
+// decimateGeometry simplifies a geometry by rewriting its indices.
+// Vertex attributes are kept as they are, so normals and texture coordinates stay valid.
+func decimateGeometry(geom *geometry.Geometry, options LoadOptions) int {
+	var positions []math32.Vector3
+	geom.ReadVertices(func(vertex math32.Vector3) bool {
+		positions = append(positions, vertex)
+		return false
+	})
+	indices := []uint32(geom.Indices())
+	if len(indices) == 0 {
+		indices = make([]uint32, len(positions))
+		for i := range indices {
+			indices[i] = uint32(i)
+		}
+	}
+	triangles := len(indices) / 3
+	if options.MaxTriangles > 0 && triangles <= options.MaxTriangles {
+		return 0
+	}
+
+	var result []uint32
+	if options.Tolerance > 0 {
+		result = clusterVertices(positions, indices, options.Tolerance)
+	} else {
+		result = decimateToBudget(positions, indices, options.MaxTriangles)
+	}
+	if len(result) == len(indices) {
+		return 0
+	}
+	geom.SetIndices(math32.ArrayU32(result))
+	return triangles - len(result)/3
+}
+
+// decimateToBudget searches the smallest cell size keeping the triangle count within budget
+func decimateToBudget(positions []math32.Vector3, indices []uint32, budget int) []uint32 {
+	box := math32.NewBox3(nil, nil)
+	box.SetFromPoints(positions)
+	var size math32.Vector3
+	box.Size(&size)
+	low, high := float32(0), size.Length()
+	result := clusterVertices(positions, indices, high)
+	for i := 0; i < decimationSteps; i++ {
+		cell := (low + high) / 2
+		candidate := clusterVertices(positions, indices, cell)
+		if len(candidate)/3 > budget {
+			low = cell
+		} else {
+			high = cell
+			result = candidate
+		}
+	}
+	return result
+}
+
+// clusterVertices merges all vertices within the same grid cell into the first vertex of the cell
+// and returns the indices of all triangles which did not collapse.
+func clusterVertices(positions []math32.Vector3, indices []uint32, cell float32) []uint32 {
+	if cell <= 0 {
+		return indices
+	}
+	type cellKey struct{ x, y, z int32 }
+	cells := make(map[cellKey]uint32)
+	remap := make([]uint32, len(positions))
+	for i, p := range positions {
+		key := cellKey{
+			int32(math32.Floor(p.X / cell)),
+			int32(math32.Floor(p.Y / cell)),
+			int32(math32.Floor(p.Z / cell)),
+		}
+		rep, found := cells[key]
+		if !found {
+			rep = uint32(i)
+			cells[key] = rep
+		}
+		remap[i] = rep
+	}
+
+	result := make([]uint32, 0, len(indices))
+	for i := 0; i+2 < len(indices); i += 3 {
+		a, b, c := remap[indices[i]], remap[indices[i+1]], remap[indices[i+2]]
+		if a == b || b == c || a == c {
+			continue
+		}
+		result = append(result, a, b, c)
+	}
+	return result
+}
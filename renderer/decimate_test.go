@@ -0,0 +1,43 @@
+package renderer
+
+import (
+	"testing"
+
+	"github.com/g3n/engine/math32"
+)
+
+// getGridMesh returns a flat n x n grid of quads with unit spacing
+func getGridMesh(n int) ([]math32.Vector3, []uint32) {
+	var positions []math32.Vector3
+	for y := 0; y <= n; y++ {
+		for x := 0; x <= n; x++ {
+			positions = append(positions, math32.Vector3{X: float32(x), Y: float32(y)})
+		}
+	}
+	var indices []uint32
+	for y := 0; y < n; y++ {
+		for x := 0; x < n; x++ {
+			i := uint32(y*(n+1) + x)
+			indices = append(indices, i, i+1, i+uint32(n)+2, i, i+uint32(n)+2, i+uint32(n)+1)
+		}
+	}
+	return positions, indices
+}
+
+func TestClusterVertices(t *testing.T) {
+	positions, indices := getGridMesh(4)
+	assert(t, len(clusterVertices(positions, indices, 0)), len(indices))
+	// cells smaller than the grid spacing keep all triangles
+	assert(t, len(clusterVertices(positions, indices, 0.5))/3, 32)
+	// cells of two units merge the grid into 2 x 2 quads
+	assert(t, len(clusterVertices(positions, indices, 2))/3, 8)
+	// a single cell collapses everything
+	assert(t, len(clusterVertices(positions, indices, 100)), 0)
+}
+
+func TestDecimateToBudget(t *testing.T) {
+	positions, indices := getGridMesh(16)
+	result := decimateToBudget(positions, indices, 100)
+	assert(t, len(result)/3 <= 100, true)
+	assert(t, len(result) > 0, true)
+}
@@ -0,0 +1,116 @@
+package renderer
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"image"
+	"image/png"
+	"strconv"
+)
+
+// defaultKeyframeInterval is the number of delta frames after which a keyframe is sent
+const defaultKeyframeInterval = 300
+
+// deltaState holds the lossless delta frame stream
+type deltaState struct {
+	interval int    // frames between keyframes, 0 streams encoded frames
+	previous []byte // pixels of the last frame sent
+	bounds   image.Rectangle
+	frames   int // delta frames since the last keyframe
+}
+
+// deltaFrame is a png keyframe or the pixels of a frame xor the previous frame, run length encoded.
+// The run length data repeats the number of unchanged bytes, the number of changed bytes and the changed bytes as uvarints,
+// bytes following the last run are unchanged.
+type deltaFrame struct {
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+	Key    bool   `json:"key"`
+	Data   string `json:"data"`
+}
+
+// Delta streams lossless frames, a png keyframe followed by run length encoded differences to the previous frame.
+// A number sets the frames between keyframes and on the default, an empty value toggles and off disables the delta stream.
+func (app *RenderingApp) Delta(cmd Command) {
+	interval := defaultKeyframeInterval
+	switch cmd.Val {
+	case "":
+		if app.delta.interval > 0 {
+			interval = 0
+		}
+	case "on":
+	case "off", "0":
+		interval = 0
+	default:
+		var err error
+		interval, err = strconv.Atoi(cmd.Val)
+		if err != nil || interval < 1 {
+			app.sendMessageToClient("delta", "invalid keyframe interval "+cmd.Val)
+			return
+		}
+	}
+	app.delta = deltaState{interval: interval}
+	if interval > 0 {
+		app.tiles = tileState{}
+	}
+	// the next whole frame must be sent even if it did not change
+	md5SumBuffer = [16]byte{}
+}
+
+// streamDelta sends a keyframe or the difference to the last frame sent, unchanged frames are skipped
+func (app *RenderingApp) streamDelta(img *image.RGBA) {
+	d := &app.delta
+	b := img.Bounds()
+	frame := deltaFrame{Width: b.Dx(), Height: b.Dy()}
+	if d.previous == nil || d.bounds != b || d.frames >= d.interval {
+		buf := new(bytes.Buffer)
+		if err := png.Encode(buf, img); err != nil {
+			app.log.Error("encoding keyframe failed: %v", err)
+			return
+		}
+		frame.Key, frame.Data = true, base64.StdEncoding.EncodeToString(buf.Bytes())
+		d.previous, d.bounds, d.frames = append(d.previous[:0], img.Pix...), b, 0
+	} else {
+		data := encodeDelta(d.previous, img.Pix)
+		if data == nil {
+			return
+		}
+		frame.Data = base64.StdEncoding.EncodeToString(data)
+		copy(d.previous, img.Pix)
+		d.frames++
+	}
+	app.stampFrame()
+	app.sendDataToClient("delta", frame)
+}
+
+// encodeDelta returns the run length encoded bytes of previous xor current, nil if they are equal
+func encodeDelta(previous, current []byte) []byte {
+	var out []byte
+	var n [binary.MaxVarintLen64]byte
+	put := func(v int) {
+		out = append(out, n[:binary.PutUvarint(n[:], uint64(v))]...)
+	}
+	i := 0
+	for i < len(current) {
+		start := i
+		for i < len(current) && current[i] == previous[i] {
+			i++
+		}
+		if i == len(current) {
+			break
+		}
+		zeros := i - start
+		start = i
+		// short unchanged gaps cost more as run than as literal bytes
+		for i < len(current) && (current[i] != previous[i] || (i+2 < len(current) && (current[i+1] != previous[i+1] || current[i+2] != previous[i+2]))) {
+			i++
+		}
+		put(zeros)
+		put(i - start)
+		for j := start; j < i; j++ {
+			out = append(out, current[j]^previous[j])
+		}
+	}
+	return out
+}
@@ -0,0 +1,53 @@
+package renderer
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+// decodeDelta applies run length encoded xor data to the previous pixels like the client does
+func decodeDelta(previous, data []byte) []byte {
+	pix := append([]byte(nil), previous...)
+	r := bytes.NewReader(data)
+	p := 0
+	for r.Len() > 0 {
+		zeros, _ := binary.ReadUvarint(r)
+		n, _ := binary.ReadUvarint(r)
+		p += int(zeros)
+		for end := p + int(n); p < end; p++ {
+			b, _ := r.ReadByte()
+			pix[p] ^= b
+		}
+	}
+	return pix
+}
+
+func TestEncodeDelta(t *testing.T) {
+	previous := make([]byte, 4000)
+	for i := range previous {
+		previous[i] = byte(i * 7)
+	}
+	assert(t, encodeDelta(previous, previous) == nil, true)
+
+	current := append([]byte(nil), previous...)
+	current[0] = 1 // change at the start
+	current[500]++ // single changes close to each other form one run
+	current[502]++
+	current[2000] = 0xff // isolated change
+	current[3999]++      // change at the end
+	data := encodeDelta(previous, current)
+	if !bytes.Equal(decodeDelta(previous, data), current) {
+		t.Error("decoded frame differs")
+	}
+	if len(data) > 20 {
+		t.Error("delta of a few changed bytes is too large", len(data))
+	}
+
+	for i := range current {
+		current[i] = ^previous[i]
+	}
+	if !bytes.Equal(decodeDelta(previous, encodeDelta(previous, current)), current) {
+		t.Error("decoded frame of all changed bytes differs")
+	}
+}
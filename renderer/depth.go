@@ -0,0 +1,107 @@
+package renderer
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"math"
+
+	"github.com/g3n/engine/gls"
+)
+
+// depthSettings holds the optional depth buffer stream
+type depthSettings struct {
+	encoder string // png or jpeg, empty disables the stream
+	md5     [16]byte
+}
+
+// depthFrame is a depth buffer sent to the client.
+// Pixels hold the linear camera distance between near and far, from black to white.
+type depthFrame struct {
+	Image  string  `json:"image"`
+	Format string  `json:"format"`
+	Width  int     `json:"width"`
+	Height int     `json:"height"`
+	Near   float32 `json:"near"`
+	Far    float32 `json:"far"`
+}
+
+// Depth streams the depth buffer encoded as 16 bit png or quantized jpeg, an empty value stops the stream
+func (app *RenderingApp) Depth(cmd Command) {
+	switch cmd.Val {
+	case "png", "jpeg":
+		app.depth.encoder = cmd.Val
+	case "", "off":
+		app.depth.encoder = ""
+	default:
+		app.sendMessageToClient("depth", "unknown depth format "+cmd.Val)
+		return
+	}
+	app.depth.md5 = [16]byte{}
+}
+
+// streamDepth reads the depth buffer and sends it to the client if it changed
+func (app *RenderingApp) streamDepth() {
+	w, h := app.Width, app.Height
+	cam := app.CameraPersp()
+	data := app.Gl().ReadPixels(0, 0, w, h, gls.DEPTH_COMPONENT, gls.FLOAT)
+	img := depthImage(data, w, h, cam.Near(), cam.Far())
+
+	buf := new(bytes.Buffer)
+	var err error
+	if app.depth.encoder == "jpeg" {
+		gray := image.NewGray(img.Bounds())
+		for i := range gray.Pix {
+			gray.Pix[i] = img.Pix[i*2]
+		}
+		err = jpeg.Encode(buf, gray, &jpeg.Options{Quality: app.imageSettings.getJpegQuality()})
+	} else {
+		err = png.Encode(buf, img)
+	}
+	if err != nil {
+		app.log.Error("encoding depth buffer failed: %v", err)
+		return
+	}
+	md := md5.Sum(buf.Bytes())
+	if md == app.depth.md5 {
+		return
+	}
+	app.depth.md5 = md
+	app.sendDataToClient("depth", depthFrame{
+		Image:  base64.StdEncoding.EncodeToString(buf.Bytes()),
+		Format: app.depth.encoder,
+		Width:  w,
+		Height: h,
+		Near:   cam.Near(),
+		Far:    cam.Far(),
+	})
+}
+
+// depthImage converts a bottom up float depth buffer into a top down 16 bit image of linear depth
+func depthImage(data []byte, w, h int, near, far float32) *image.Gray16 {
+	img := image.NewGray16(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			i := (y*w + x) * 4
+			d := math.Float32frombits(binary.LittleEndian.Uint32(data[i : i+4]))
+			img.SetGray16(x, h-1-y, color.Gray16{Y: uint16(linearDepth(d, near, far) * 65535)})
+		}
+	}
+	return img
+}
+
+// linearDepth converts a depth buffer value of a perspective projection
+// into the camera distance between near (0) and far (1)
+func linearDepth(d, near, far float32) float32 {
+	if d >= 1 {
+		return 1
+	}
+	z := 2*d - 1
+	distance := 2 * near * far / (far + near - z*(far-near))
+	return (distance - near) / (far - near)
+}
@@ -0,0 +1,26 @@
+package renderer
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+)
+
+func TestLinearDepth(t *testing.T) {
+	assert(t, linearDepth(0, 1, 100), float32(0))
+	assert(t, linearDepth(1, 1, 100), float32(1))
+	// half way between near and far in camera distance
+	near, far := float32(1), float32(3)
+	d := (1/near - 1/float32(2)) / (1/near - 1/far)
+	assert(t, math.Abs(float64(linearDepth(d, near, far)-0.5)) < 1e-5, true)
+}
+
+func TestDepthImage(t *testing.T) {
+	data := make([]byte, 2*2*4)
+	// bottom left pixel is background, all others are at the near plane
+	binary.LittleEndian.PutUint32(data, math.Float32bits(1))
+	img := depthImage(data, 2, 2, 1, 100)
+	assert(t, img.Gray16At(0, 1).Y, uint16(65535))
+	assert(t, img.Gray16At(0, 0).Y, uint16(0))
+	assert(t, img.Gray16At(1, 1).Y, uint16(0))
+}
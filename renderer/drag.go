@@ -0,0 +1,211 @@
+package renderer
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/math32"
+)
+
+// dragState holds the drag mode and a running drag of the selection
+type dragState struct {
+	enabled bool
+	normal  math32.Vector3 // normal of the working plane
+	grid    float32        // snap spacing in model units, 0 disables snapping
+	active  bool
+	origin  math32.Vector3   // picked point the drag started at, on the working plane
+	nodes   []core.INode     // dragged nodes, the one moving the picked node first
+	starts  []math32.Vector3 // world positions of the nodes when the drag started
+}
+
+// Drag enables moving the selection with the left mouse button instead of orbiting. The value is plane[,grid]
+// with the working plane xz, xy or yz through the picked point and an optional grid spacing in model units
+// the picked node snaps to. An empty value drags on the xz plane without snapping, off disables dragging.
+// Dropped nodes are sent as drag message with their transforms in patch format.
+func (app *RenderingApp) Drag(cmd Command) {
+	normal, grid, err := parseDragMode(cmd.Val)
+	if err != nil {
+		app.sendMessageToClient("drag", err.Error())
+		return
+	}
+	if app.drag.active {
+		app.endDrag()
+	}
+	app.drag = dragState{enabled: normal != math32.Vector3{}, normal: normal, grid: grid}
+}
+
+// startDrag starts dragging the selection if the left button is pressed on a selected node
+func (app *RenderingApp) startDrag(cmd Command) bool {
+	d := &app.drag
+	if !d.enabled || cmd.Val != "0" {
+		return false
+	}
+	hits := app.raycast(cmd.X, cmd.Y)
+	if len(hits) == 0 {
+		return false
+	}
+	picked := hits[0].Object
+	if _, ok := app.selectionBuffer[picked]; !ok {
+		return false
+	}
+	d.active, d.origin = true, hits[0].Point
+	selected := []core.INode{picked}
+	for inode := range app.selectionBuffer {
+		if inode != picked {
+			selected = append(selected, inode)
+		}
+	}
+	// children selected together with their parent move with the parent only
+	d.nodes = topmostNodes(selected)
+	// the node moving the picked one comes first, it is snapped to the grid
+	for i, inode := range d.nodes {
+		for n := picked; n != nil; n = n.GetNode().Parent() {
+			if n == inode {
+				d.nodes[0], d.nodes[i] = inode, d.nodes[0]
+			}
+		}
+	}
+	d.starts = make([]math32.Vector3, len(d.nodes))
+	for i, inode := range d.nodes {
+		inode.GetNode().WorldPosition(&d.starts[i])
+	}
+	app.stopTransition()
+	app.imageSettings.isNavigating = true
+	app.queueCulling(true)
+	return true
+}
+
+// dragTo moves the dragged nodes to where the cursor hits the working plane
+func (app *RenderingApp) dragTo(mx, my float32) {
+	d := &app.drag
+	r := app.screenRaycaster(mx, my)
+	p, ok := intersectPlane(r.Origin(), r.Direction(), d.origin, d.normal)
+	if !ok {
+		return
+	}
+	target := d.starts[0]
+	target.Add(p.Sub(&d.origin))
+	target = snapToGrid(target, d.normal, d.grid*ModelScale)
+	delta := target.Sub(&d.starts[0])
+	for i, inode := range d.nodes {
+		position := d.starts[i]
+		setWorldPosition(inode, *position.Add(delta))
+	}
+	app.frameCache.invalidate()
+}
+
+// endDrag drops the dragged nodes and sends their transforms
+func (app *RenderingApp) endDrag() {
+	d := &app.drag
+	patches := make([]NodePatch, len(d.nodes))
+	ids := make([]string, len(d.nodes))
+	for i, inode := range d.nodes {
+		node := inode.GetNode()
+		p, q, s := node.Position(), node.Quaternion(), node.Scale()
+		patches[i] = NodePatch{
+			Node:     node.Name(),
+			Position: &[3]float32{p.X, p.Y, p.Z},
+			Rotation: &[4]float32{q.X, q.Y, q.Z, q.W},
+			Scale:    &[3]float32{s.X, s.Y, s.Z},
+		}
+		ids[i] = node.Name()
+	}
+	d.active, d.nodes, d.starts = false, nil, nil
+	app.imageSettings.isNavigating = false
+	app.queueCulling(false)
+	app.audit("moved", strings.Join(ids, ","), true)
+	app.journal(journalEntry{Patches: patches})
+	app.sendDataToClient("drag", patches)
+}
+
+// queueCulling pauses group culling on the render thread while dragging,
+// the culling boxes are rebuilt once the nodes are dropped
+func (app *RenderingApp) queueCulling(dragging bool) {
+	update := func() {
+		app.culling.dragging = dragging
+		if !dragging && app.modelRoot != nil {
+			app.buildCullingBoxes(app.modelRoot)
+		}
+	}
+	select {
+	case app.sceneUpdates <- update:
+	case <-app.quit:
+	}
+}
+
+// setWorldPosition moves a node to a world position, converted into the coordinates of its parent
+func setWorldPosition(inode core.INode, position math32.Vector3) {
+	node := inode.GetNode()
+	if parent := node.Parent(); parent != nil {
+		var inverse math32.Matrix4
+		matrixWorld := parent.GetNode().MatrixWorld()
+		if inverse.GetInverse(&matrixWorld) == nil {
+			position.ApplyMatrix4(&inverse)
+		}
+	}
+	node.SetPositionVec(&position)
+}
+
+// intersectPlane returns where a ray hits the plane through a point, false if it is parallel or behind the ray origin
+func intersectPlane(origin, direction, point, normal math32.Vector3) (math32.Vector3, bool) {
+	denominator := normal.Dot(&direction)
+	if math32.Abs(denominator) < 1e-6 {
+		return math32.Vector3{}, false
+	}
+	t := point.Sub(&origin).Dot(&normal) / denominator
+	if t < 0 {
+		return math32.Vector3{}, false
+	}
+	return *origin.Add(direction.MultiplyScalar(t)), true
+}
+
+// snapToGrid rounds the coordinates of a point within an axis aligned plane to multiples of the spacing,
+// the coordinate along the plane normal is kept
+func snapToGrid(p, normal math32.Vector3, spacing float32) math32.Vector3 {
+	if spacing <= 0 {
+		return p
+	}
+	snap := func(v float32) float32 { return math32.Round(v/spacing) * spacing }
+	if normal.X == 0 {
+		p.X = snap(p.X)
+	}
+	if normal.Y == 0 {
+		p.Y = snap(p.Y)
+	}
+	if normal.Z == 0 {
+		p.Z = snap(p.Z)
+	}
+	return p
+}
+
+// parseDragMode parses plane[,grid] into the plane normal and the grid spacing, off returns a zero normal
+func parseDragMode(value string) (math32.Vector3, float32, error) {
+	s := strings.Split(value, ",")
+	var normal math32.Vector3
+	switch strings.TrimSpace(s[0]) {
+	case "", "xz":
+		normal = math32.Vector3{Y: 1}
+	case "xy":
+		normal = math32.Vector3{Z: 1}
+	case "yz":
+		normal = math32.Vector3{X: 1}
+	case "off":
+		return normal, 0, nil
+	default:
+		return normal, 0, fmt.Errorf("invalid working plane %s, expected xz, xy or yz", s[0])
+	}
+	if len(s) > 2 {
+		return math32.Vector3{}, 0, fmt.Errorf("invalid drag mode %s, expected plane[,grid]", value)
+	}
+	var grid float64
+	if len(s) == 2 {
+		var err error
+		grid, err = strconv.ParseFloat(strings.TrimSpace(s[1]), 32)
+		if err != nil || grid < 0 {
+			return math32.Vector3{}, 0, fmt.Errorf("invalid grid spacing %s", s[1])
+		}
+	}
+	return normal, float32(grid), nil
+}
@@ -0,0 +1,58 @@
+package renderer
+
+import (
+	"testing"
+
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/math32"
+)
+
+func TestParseDragMode(t *testing.T) {
+	normal, grid, err := parseDragMode("")
+	assert(t, err, nil)
+	assert(t, normal, math32.Vector3{Y: 1})
+	assert(t, grid, float32(0))
+	normal, grid, err = parseDragMode("xy, 0.5")
+	assert(t, err, nil)
+	assert(t, normal, math32.Vector3{Z: 1})
+	assert(t, grid, float32(0.5))
+	normal, _, err = parseDragMode("off")
+	assert(t, err, nil)
+	assert(t, normal, math32.Vector3{})
+	for _, value := range []string{"ab", "xz,-1", "xz,grid", "xz,1,2"} {
+		if _, _, err := parseDragMode(value); err == nil {
+			t.Error("expected error for", value)
+		}
+	}
+}
+
+func TestIntersectPlane(t *testing.T) {
+	p, ok := intersectPlane(math32.Vector3{Y: 10}, math32.Vector3{Y: -1}, math32.Vector3{X: 5, Y: 2}, math32.Vector3{Y: 1})
+	assert(t, ok, true)
+	assert(t, p, math32.Vector3{Y: 2})
+	_, ok = intersectPlane(math32.Vector3{Y: 10}, math32.Vector3{X: 1}, math32.Vector3{}, math32.Vector3{Y: 1})
+	assert(t, ok, false)
+	_, ok = intersectPlane(math32.Vector3{Y: 10}, math32.Vector3{Y: 1}, math32.Vector3{}, math32.Vector3{Y: 1})
+	assert(t, ok, false)
+}
+
+func TestSnapToGrid(t *testing.T) {
+	p := math32.Vector3{X: 1.2, Y: 0.7, Z: -2.6}
+	assert(t, snapToGrid(p, math32.Vector3{Y: 1}, 0.5), math32.Vector3{X: 1, Y: 0.7, Z: -2.5})
+	assert(t, snapToGrid(p, math32.Vector3{X: 1}, 1), math32.Vector3{X: 1.2, Y: 1, Z: -3})
+	assert(t, snapToGrid(p, math32.Vector3{Y: 1}, 0), p)
+}
+
+func TestSetWorldPosition(t *testing.T) {
+	parent := core.NewNode()
+	parent.SetPosition(1, 0, 0)
+	parent.SetScale(2, 2, 2)
+	child := core.NewNode()
+	parent.Add(child)
+	parent.UpdateMatrixWorld()
+	setWorldPosition(child, math32.Vector3{X: 5, Y: 4})
+	assert(t, child.Position(), math32.Vector3{X: 2, Y: 2})
+	var world math32.Vector3
+	child.WorldPosition(&world)
+	assert(t, world, math32.Vector3{X: 5, Y: 4})
+}
@@ -0,0 +1,173 @@
+package renderer
+
+import (
+	"encoding/binary"
+	"fmt"
+	"image"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/math32"
+	"github.com/moethu/imaging"
+)
+
+// defaultBloomThreshold is the luminance from 0 to 1 above which pixels glow
+const defaultBloomThreshold = 0.8
+
+// effectSettings are post-processing effects of presentation stills
+type effectSettings struct {
+	blurRadius float32 // maximum depth of field blur in pixels, 0 disables depth of field
+	focus      float32 // focus distance in model units, 0 focuses on the camera target
+	vignette   float32 // darkening of the corners from 0 to 1
+	bloom      float32 // strength of the glow around bright pixels, 0 disables bloom
+	threshold  float32 // luminance above which pixels glow
+}
+
+// Depthoffield blurs the frame away from the focus distance, the value is radius[,focus] with the maximum blur radius
+// in pixels and the focus distance in model units. Without focus the camera target is in focus, an empty value disables the effect.
+func (app *RenderingApp) Depthoffield(cmd Command) {
+	params, err := parseEffect(cmd.Val, 2)
+	if err != nil {
+		app.sendMessageToClient("depthoffield", err.Error())
+		return
+	}
+	app.effects.blurRadius, app.effects.focus = params[0], params[1]
+}
+
+// Vignette darkens the corners of the frame by a strength from 0 to 1, an empty value disables the effect
+func (app *RenderingApp) Vignette(cmd Command) {
+	params, err := parseEffect(cmd.Val, 1)
+	if err == nil && params[0] > 1 {
+		err = fmt.Errorf("vignette strength must be within 0 to 1")
+	}
+	if err != nil {
+		app.sendMessageToClient("vignette", err.Error())
+		return
+	}
+	app.effects.vignette = params[0]
+}
+
+// Bloom lets bright pixels glow, the value is strength[,threshold] with the luminance threshold from 0 to 1, 0.8 by default.
+// An empty value disables the effect.
+func (app *RenderingApp) Bloom(cmd Command) {
+	params, err := parseEffect(cmd.Val, 2)
+	if err == nil && !strings.Contains(cmd.Val, ",") {
+		params[1] = defaultBloomThreshold
+	}
+	if err == nil && params[1] >= 1 {
+		err = fmt.Errorf("bloom threshold must be below 1")
+	}
+	if err != nil {
+		app.sendMessageToClient("bloom", err.Error())
+		return
+	}
+	app.effects.bloom, app.effects.threshold = params[0], params[1]
+}
+
+// applyDepthOfField blends the bottom up frame with a blurred copy by the distance of each pixel to the focus
+func (app *RenderingApp) applyDepthOfField(pix []byte) {
+	w, h := app.Width, app.Height
+	cam := app.CameraPersp()
+	near, far := cam.Near(), cam.Far()
+	focus := app.effects.focus
+	if focus <= 0 {
+		position, target := cam.Position(), cam.Target()
+		focus = toModelUnits(position.DistanceTo(&target))
+	}
+	blurred := imaging.Blur(rgbaImage(pix, w, h), float64(app.effects.blurRadius)/2)
+	depth := app.Gl().ReadPixels(0, 0, w, h, gls.DEPTH_COMPONENT, gls.FLOAT)
+	for i := 0; i < w*h; i++ {
+		d := math.Float32frombits(binary.LittleEndian.Uint32(depth[i*4 : i*4+4]))
+		f := blurFactor(toModelUnits(near+linearDepth(d, near, far)*(far-near)), focus)
+		if f == 0 {
+			continue
+		}
+		for c := 0; c < 3; c++ {
+			p := &pix[i*4+c]
+			*p = byte(float32(*p) + (float32(blurred.Pix[i*4+c])-float32(*p))*f + 0.5)
+		}
+	}
+}
+
+// blurFactor returns how much a pixel at a distance is blurred, from 0 at the focus distance to 1
+// at half or infinitely far beyond it, like the circle of confusion of a lens
+func blurFactor(distance, focus float32) float32 {
+	if distance <= 0 {
+		return 1
+	}
+	return clamp01(math32.Abs(distance-focus) / distance)
+}
+
+// applyVignette darkens the image towards its corners
+func applyVignette(img *image.RGBA, strength float32) {
+	b := img.Bounds()
+	cx, cy := float32(b.Dx())/2, float32(b.Dy())/2
+	corner := cx*cx + cy*cy
+	for y := 0; y < b.Dy(); y++ {
+		dy := float32(y) + 0.5 - cy
+		for x := 0; x < b.Dx(); x++ {
+			dx := float32(x) + 0.5 - cx
+			f := 1 - strength*(dx*dx+dy*dy)/corner
+			i := img.PixOffset(b.Min.X+x, b.Min.Y+y)
+			for c := 0; c < 3; c++ {
+				img.Pix[i+c] = byte(float32(img.Pix[i+c])*f + 0.5)
+			}
+		}
+	}
+}
+
+// applyBloom adds a blurred copy of the pixels brighter than the threshold to the image
+func applyBloom(img *image.RGBA, strength, threshold float32) {
+	b := img.Bounds()
+	bright := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	for y := 0; y < b.Dy(); y++ {
+		for x := 0; x < b.Dx(); x++ {
+			i, j := img.PixOffset(b.Min.X+x, b.Min.Y+y), bright.PixOffset(x, y)
+			p := img.Pix[i : i+3]
+			l := (0.2126*float32(p[0]) + 0.7152*float32(p[1]) + 0.0722*float32(p[2])) / 255
+			if l <= threshold {
+				continue
+			}
+			k := (l - threshold) / (1 - threshold)
+			for c := 0; c < 3; c++ {
+				bright.Pix[j+c] = byte(float32(p[c]) * k)
+			}
+			bright.Pix[j+3] = 255
+		}
+	}
+	glow := imaging.Blur(bright, math.Max(1, float64(b.Dy())/100))
+	for y := 0; y < b.Dy(); y++ {
+		for x := 0; x < b.Dx(); x++ {
+			i, j := img.PixOffset(b.Min.X+x, b.Min.Y+y), glow.PixOffset(x, y)
+			for c := 0; c < 3; c++ {
+				v := float32(img.Pix[i+c]) + strength*float32(glow.Pix[j+c])
+				if v > 255 {
+					v = 255
+				}
+				img.Pix[i+c] = byte(v + 0.5)
+			}
+		}
+	}
+}
+
+// parseEffect parses up to n comma separated positive numbers, missing numbers are 0
+func parseEffect(value string, n int) ([]float32, error) {
+	params := make([]float32, n)
+	if strings.TrimSpace(value) == "" || value == "off" {
+		return params, nil
+	}
+	s := strings.Split(value, ",")
+	if len(s) > n {
+		return nil, fmt.Errorf("invalid effect %s, expected at most %d numbers", value, n)
+	}
+	for i, v := range s {
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 32)
+		if err != nil || f < 0 {
+			return nil, fmt.Errorf("invalid number %s", v)
+		}
+		params[i] = float32(f)
+	}
+	return params, nil
+}
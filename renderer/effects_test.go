@@ -0,0 +1,63 @@
+package renderer
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestParseEffect(t *testing.T) {
+	params, err := parseEffect("6,25", 2)
+	assert(t, err, nil)
+	assert(t, params[0], float32(6))
+	assert(t, params[1], float32(25))
+
+	params, err = parseEffect("0.4", 2)
+	assert(t, err, nil)
+	assert(t, params[1], float32(0))
+
+	params, _ = parseEffect("", 1)
+	assert(t, params[0], float32(0))
+
+	for _, v := range []string{"1,2,3", "-1", "strong"} {
+		if _, err := parseEffect(v, 2); err == nil {
+			t.Errorf("invalid effect %s accepted", v)
+		}
+	}
+}
+
+func TestBlurFactor(t *testing.T) {
+	assert(t, blurFactor(10, 10), float32(0))
+	assert(t, blurFactor(20, 10), float32(0.5))
+	assert(t, blurFactor(5, 10), float32(1))
+	assert(t, blurFactor(0, 10), float32(1))
+}
+
+func TestApplyVignette(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 10, 10))
+	for i := range img.Pix {
+		img.Pix[i] = 200
+	}
+	applyVignette(img, 0.5)
+	center, corner := img.RGBAAt(5, 5), img.RGBAAt(0, 0)
+	if center.R < 195 || corner.R > 120 || corner.R >= center.R {
+		t.Errorf("center %v, corner %v", center, corner)
+	}
+	assert(t, corner.A, uint8(200))
+}
+
+func TestApplyBloom(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 20, 20))
+	for y := 0; y < 20; y++ {
+		for x := 0; x < 20; x++ {
+			img.SetRGBA(x, y, color.RGBA{A: 255})
+		}
+	}
+	img.SetRGBA(10, 10, color.RGBA{R: 255, G: 255, B: 255, A: 255})
+	applyBloom(img, 1, 0.5)
+	if img.RGBAAt(11, 10).R == 0 {
+		t.Error("bright pixel does not glow")
+	}
+	assert(t, img.RGBAAt(0, 0).R, uint8(0))
+	assert(t, img.RGBAAt(10, 10).R, uint8(255))
+}
@@ -0,0 +1,142 @@
+package renderer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/g3n/engine/core"
+)
+
+// visibilityFilter hides the nodes matching its conditions (hide) or all other nodes (isolate)
+type visibilityFilter struct {
+	expression string
+	isolate    bool
+	conditions []scriptFilter
+}
+
+// filterState holds the active visibility filters and the graphics they have hidden
+type filterState struct {
+	filters []visibilityFilter
+	hidden  []core.INode
+}
+
+// filterList lists the active filters sent to the client
+type filterList struct {
+	Filters []string `json:"filters"`
+	Hidden  int      `json:"hidden"` // graphics hidden by the filters
+}
+
+// Filter adds a visibility rule against names or user data, e.g. hide userdata.category == MEP
+// or isolate userdata.level == 2 and userdata.category != Furniture. Conditions are those of scene scripts,
+// a node is matched if it or one of its parents fulfills all conditions. An empty value sends the active filters.
+func (app *RenderingApp) Filter(cmd Command) {
+	if strings.TrimSpace(cmd.Val) != "" {
+		filter, err := parseVisibilityFilter(cmd.Val)
+		if err != nil {
+			app.sendMessageToClient("filter", err.Error())
+			return
+		}
+		app.filters.filters = append(app.filters.filters, filter)
+		app.applyFilters()
+	}
+	app.sendFilters()
+}
+
+// Unfilter removes a visibility rule by its expression, an empty value removes all rules
+func (app *RenderingApp) Unfilter(cmd Command) {
+	expression := normalizeFilter(cmd.Val)
+	var filters []visibilityFilter
+	for _, f := range app.filters.filters {
+		if expression != "" && f.expression != expression {
+			filters = append(filters, f)
+		}
+	}
+	if expression != "" && len(filters) == len(app.filters.filters) {
+		app.sendMessageToClient("unfilter", "unknown filter "+cmd.Val)
+		return
+	}
+	app.filters.filters = filters
+	app.applyFilters()
+	app.sendFilters()
+}
+
+// applyFilters shows all graphics hidden by filters before and hides those matched by the active filters
+func (app *RenderingApp) applyFilters() {
+	for _, inode := range app.filters.hidden {
+		inode.GetNode().SetVisible(true)
+	}
+	app.filters.hidden = nil
+	if len(app.filters.filters) == 0 || app.modelRoot == nil {
+		return
+	}
+	forEachGraphic([]core.INode{app.modelRoot}, func(inode core.INode) {
+		if inode.GetNode().Visible() && app.filters.hides(inode) {
+			inode.GetNode().SetVisible(false)
+			app.filters.hidden = append(app.filters.hidden, inode)
+		}
+	})
+}
+
+// hides returns true if a graphic is hidden by any filter
+func (s *filterState) hides(inode core.INode) bool {
+	for _, f := range s.filters {
+		if f.matches(inode) != f.isolate {
+			return true
+		}
+	}
+	return false
+}
+
+// matches returns true if a node or one of its parents fulfills all conditions
+func (f visibilityFilter) matches(inode core.INode) bool {
+	for n := inode; n != nil; n = n.GetNode().Parent() {
+		match := true
+		for _, c := range f.conditions {
+			if !c.matches(n.GetNode()) {
+				match = false
+				break
+			}
+		}
+		if match {
+			return true
+		}
+	}
+	return false
+}
+
+// sendFilters sends the active filters to the client
+func (app *RenderingApp) sendFilters() {
+	list := filterList{Filters: []string{}, Hidden: len(app.filters.hidden)}
+	for _, f := range app.filters.filters {
+		list.Filters = append(list.Filters, f.expression)
+	}
+	app.sendDataToClient("filters", list)
+}
+
+// parseVisibilityFilter parses hide|isolate <field> <op> <value> [and ...]
+func parseVisibilityFilter(value string) (visibilityFilter, error) {
+	tokens, err := tokenizeScriptLine(strings.TrimSpace(value))
+	if err != nil {
+		return visibilityFilter{}, err
+	}
+	if len(tokens) < 4 {
+		return visibilityFilter{}, fmt.Errorf("invalid filter %s, expected hide|isolate <field> <op> <value>", value)
+	}
+	f := visibilityFilter{expression: normalizeFilter(value)}
+	switch strings.ToLower(tokens[0]) {
+	case "hide":
+	case "isolate":
+		f.isolate = true
+	default:
+		return f, fmt.Errorf("unknown filter action %s", tokens[0])
+	}
+	if f.conditions, err = parseScriptFilters(tokens[1:]); err != nil {
+		return f, err
+	}
+	return f, nil
+}
+
+// normalizeFilter collapses whitespace, so expressions can be removed as they were added
+func normalizeFilter(value string) string {
+	return strings.Join(strings.Fields(value), " ")
+}
@@ -0,0 +1,51 @@
+package renderer
+
+import (
+	"testing"
+
+	"github.com/g3n/engine/core"
+)
+
+func TestParseVisibilityFilter(t *testing.T) {
+	f, err := parseVisibilityFilter(`hide  userdata.category == "MEP"`)
+	assert(t, err, nil)
+	assert(t, f.expression, `hide userdata.category == "MEP"`)
+	assert(t, f.isolate, false)
+	assert(t, len(f.conditions), 1)
+
+	f, err = parseVisibilityFilter("isolate userdata.level == 2 and name ~ /0/")
+	assert(t, err, nil)
+	assert(t, f.isolate, true)
+	assert(t, len(f.conditions), 2)
+
+	if _, err = parseVisibilityFilter("show name == x"); err == nil {
+		t.Error("unknown action accepted")
+	}
+	if _, err = parseVisibilityFilter("hide name"); err == nil {
+		t.Error("incomplete filter accepted")
+	}
+}
+
+func TestFilterHides(t *testing.T) {
+	mep := core.NewNode()
+	mep.SetUserData(map[string]interface{}{"category": "MEP", "level": 1.0})
+	duct := core.NewNode()
+	mep.Add(duct)
+	wall := core.NewNode()
+	wall.SetUserData(map[string]interface{}{"category": "Walls", "level": 2.0})
+
+	hide, _ := parseVisibilityFilter("hide userdata.category == MEP")
+	s := filterState{filters: []visibilityFilter{hide}}
+	assert(t, s.hides(mep), true)
+	assert(t, s.hides(duct), true) // inherited from the parent
+	assert(t, s.hides(wall), false)
+
+	isolate, _ := parseVisibilityFilter("isolate userdata.level == 2")
+	s = filterState{filters: []visibilityFilter{isolate}}
+	assert(t, s.hides(duct), true)
+	assert(t, s.hides(wall), false)
+
+	s = filterState{filters: []visibilityFilter{hide, isolate}}
+	assert(t, s.hides(wall), false)
+	assert(t, s.hides(mep), true)
+}
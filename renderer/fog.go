@@ -0,0 +1,128 @@
+package renderer
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/math32"
+)
+
+// fog modes
+const (
+	fogOff = iota
+	fogLinear
+	fogExp
+	fogExp2
+)
+
+// fogSettings blend distant pixels into the fog color
+type fogSettings struct {
+	mode    int
+	color   math32.Color
+	start   float32 // linear fog begins at this distance from the camera
+	end     float32 // linear fog is opaque beyond this distance
+	density float32 // density of exponential fog
+}
+
+// Fog fades distant parts of the model into a fog color, the value is
+// linear,start,end[,color] or exp,density[,color] or exp2,density[,color]
+// with distances in model units and colors as web color name or #rrggbb. An empty value disables fog.
+func (app *RenderingApp) Fog(cmd Command) {
+	if cmd.Val == "" || cmd.Val == "off" {
+		app.fog.mode = fogOff
+		return
+	}
+	fog, err := parseFog(cmd.Val)
+	if err != nil {
+		app.sendMessageToClient("fog", err.Error())
+		return
+	}
+	app.fog = fog
+}
+
+// applyFog blends the bottom up frame with the fog color by the distance of each pixel
+func (app *RenderingApp) applyFog(pix []byte) {
+	w, h := app.Width, app.Height
+	cam := app.CameraPersp()
+	near, far := cam.Near(), cam.Far()
+	depth := app.Gl().ReadPixels(0, 0, w, h, gls.DEPTH_COMPONENT, gls.FLOAT)
+	color := app.fog.color
+	if LinearWorkflow {
+		color = app.output.linearColor(color)
+	}
+	fog := [3]float32{color.R * 255, color.G * 255, color.B * 255}
+	for i := 0; i < w*h; i++ {
+		d := math.Float32frombits(binary.LittleEndian.Uint32(depth[i*4 : i*4+4]))
+		distance := toModelUnits(near + linearDepth(d, near, far)*(far-near))
+		f := app.fog.factor(distance)
+		if f == 0 {
+			continue
+		}
+		for c := 0; c < 3; c++ {
+			p := &pix[i*4+c]
+			*p = byte(float32(*p) + (fog[c]-float32(*p))*f + 0.5)
+		}
+	}
+}
+
+// factor returns how much fog covers a pixel at a distance, from 0 to 1
+func (fog fogSettings) factor(distance float32) float32 {
+	switch fog.mode {
+	case fogLinear:
+		if fog.end <= fog.start {
+			return clamp01(distance - fog.start)
+		}
+		return clamp01((distance - fog.start) / (fog.end - fog.start))
+	case fogExp:
+		return clamp01(1 - float32(math.Exp(-float64(fog.density*distance))))
+	case fogExp2:
+		dd := float64(fog.density * distance)
+		return clamp01(1 - float32(math.Exp(-dd*dd)))
+	}
+	return 0
+}
+
+// parseFog parses linear,start,end[,color] or exp,density[,color] or exp2,density[,color]
+func parseFog(value string) (fogSettings, error) {
+	fog := fogSettings{color: math32.Color{R: 1, G: 1, B: 1}}
+	s := strings.Split(value, ",")
+	numbers := 1
+	switch s[0] {
+	case "linear":
+		fog.mode, numbers = fogLinear, 2
+	case "exp":
+		fog.mode = fogExp
+	case "exp2":
+		fog.mode = fogExp2
+	default:
+		return fog, fmt.Errorf("unknown fog mode %s", s[0])
+	}
+	if len(s) != numbers+1 && len(s) != numbers+2 {
+		return fog, fmt.Errorf("invalid fog %s", value)
+	}
+	params := make([]float32, numbers)
+	for i := range params {
+		v, err := strconv.ParseFloat(strings.TrimSpace(s[i+1]), 32)
+		if err != nil || v < 0 {
+			return fog, fmt.Errorf("invalid number %s", s[i+1])
+		}
+		params[i] = float32(v)
+	}
+	if fog.mode == fogLinear {
+		fog.start, fog.end = params[0], params[1]
+	} else {
+		fog.density = params[0]
+	}
+	if len(s) == numbers+2 {
+		color, err := parseColor(strings.TrimSpace(s[numbers+1]))
+		if err != nil {
+			return fog, err
+		}
+		fog.color = *color
+	}
+	return fog, nil
+}
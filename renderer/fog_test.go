@@ -0,0 +1,42 @@
+package renderer
+
+import (
+	"testing"
+
+	"github.com/g3n/engine/math32"
+)
+
+func TestParseFog(t *testing.T) {
+	fog, err := parseFog("linear,10,100,#ff0000")
+	assert(t, err, nil)
+	assert(t, fog.mode, fogLinear)
+	assert(t, fog.start, float32(10))
+	assert(t, fog.end, float32(100))
+	assert(t, fog.color, math32.Color{R: 1, G: 0, B: 0})
+
+	fog, err = parseFog("exp2,0.05")
+	assert(t, err, nil)
+	assert(t, fog.mode, fogExp2)
+	assert(t, fog.density, float32(0.05))
+	assert(t, fog.color, math32.Color{R: 1, G: 1, B: 1})
+
+	for _, value := range []string{"mist,1", "linear,10", "exp,-1", "exp,1,nocolor", "exp,1,white,2"} {
+		if _, err := parseFog(value); err == nil {
+			t.Error("accepted invalid fog", value)
+		}
+	}
+}
+
+func TestFogFactor(t *testing.T) {
+	linear := fogSettings{mode: fogLinear, start: 10, end: 20}
+	assert(t, linear.factor(5), float32(0))
+	assert(t, linear.factor(15), float32(0.5))
+	assert(t, linear.factor(30), float32(1))
+
+	exp := fogSettings{mode: fogExp, density: 0.1}
+	assert(t, exp.factor(0), float32(0))
+	if f := exp.factor(10); f < 0.63 || f > 0.64 {
+		t.Error("wrong exponential fog", f)
+	}
+	assert(t, fogSettings{}.factor(100), float32(0))
+}
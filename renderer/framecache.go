@@ -0,0 +1,120 @@
+package renderer
+
+import (
+	"sync/atomic"
+
+	"github.com/g3n/engine/math32"
+)
+
+// frameCacheSize is the number of encoded frames a session keeps for revisited views
+const frameCacheSize = 32
+
+// cameraCommands only move the camera or query state, the scene of cached frames stays valid
+var cameraCommands = map[string]bool{
+	"Navigate": true, "Mousedown": true, "Zoom": true, "Pan": true, "Keydown": true, "Keyup": true,
+	"View": true, "Zoomextent": true, "Focus": true, "Views": true, "Stats": true, "Audit": true,
+	"Clash": true, "Timestamps": true, "Measurenodes": true, "Material": true,
+}
+
+// frameKey identifies a frame by everything besides the scene it is rendered from
+type frameKey struct {
+	position, target, up math32.Vector3
+	fov                  float32
+	width, height        int
+	settings             ImageSettings
+}
+
+// cachedFrame is an encoded frame ready to be sent
+type cachedFrame struct {
+	sum  [16]byte // md5 of the encoded image
+	data []byte   // base64 encoded image
+}
+
+// frameCache keeps encoded frames of an unchanged scene, so revisited views are sent without reading and encoding them again.
+// Least recently used frames are dropped.
+type frameCache struct {
+	version int64 // scene version, increased atomically by any goroutine changing the scene
+	cached  int64 // scene version of the cached frames
+	frames  map[frameKey]cachedFrame
+	order   []frameKey // least recently used first
+}
+
+// keepsFrames returns true if a command leaves the scene of cached frames unchanged
+func keepsFrames(cmd Command) bool {
+	// clicks select, drags only navigate
+	if cmd.Cmd == "Mouseup" {
+		return cmd.Moved || cmd.Val != "0"
+	}
+	return cameraCommands[cmd.Cmd]
+}
+
+// invalidate drops all cached frames before the next frame
+func (c *frameCache) invalidate() {
+	atomic.AddInt64(&c.version, 1)
+}
+
+// get returns the frame cached for a key
+func (c *frameCache) get(key frameKey) (cachedFrame, bool) {
+	c.sync()
+	f, ok := c.frames[key]
+	if ok {
+		c.touch(key)
+	}
+	return f, ok
+}
+
+// put caches a frame, dropping the least recently used one if the cache is full
+func (c *frameCache) put(key frameKey, f cachedFrame) {
+	c.sync()
+	if c.frames == nil {
+		c.frames = make(map[frameKey]cachedFrame)
+	}
+	if _, ok := c.frames[key]; ok {
+		c.touch(key)
+	} else {
+		if len(c.order) >= frameCacheSize {
+			delete(c.frames, c.order[0])
+			c.order = c.order[1:]
+		}
+		c.order = append(c.order, key)
+	}
+	c.frames[key] = f
+}
+
+// sync drops the cached frames if the scene changed since they were cached
+func (c *frameCache) sync() {
+	if v := atomic.LoadInt64(&c.version); v != c.cached {
+		c.frames, c.order, c.cached = nil, nil, v
+	}
+}
+
+// touch moves a key to the end of the usage order
+func (c *frameCache) touch(key frameKey) {
+	for i, k := range c.order {
+		if k == key {
+			c.order = append(append(c.order[:i:i], c.order[i+1:]...), key)
+			return
+		}
+	}
+}
+
+// frameKey returns the key of the next frame and whether it may be cached.
+// Frames changing over time, consumed as pixels or streamed otherwise are not cached.
+func (app *RenderingApp) frameKey() (frameKey, bool) {
+	cacheable := !app.Debug && app.delta.interval == 0 && app.tiles.size == 0 && app.singleView() &&
+		!app.recording.active && !app.turntable.active && !app.transition.active && len(app.study.times) == 0 && len(app.frameCaptures) == 0 &&
+		!app.hud.active()
+	if !cacheable {
+		return frameKey{}, false
+	}
+	cam := app.Camera().GetCamera()
+	return frameKey{
+		position: cam.Position(),
+		target:   cam.Target(),
+		up:       cam.Up(),
+		fov:      app.CameraPersp().Fov(),
+		width:    app.Width,
+		height:   app.Height,
+		settings: app.imageSettings,
+	}, true
+}
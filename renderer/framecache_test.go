@@ -0,0 +1,51 @@
+package renderer
+
+import (
+	"testing"
+
+	"github.com/g3n/engine/math32"
+)
+
+func TestFrameCache(t *testing.T) {
+	var c frameCache
+	key := func(x float32) frameKey {
+		return frameKey{position: math32.Vector3{X: x}, width: 800, height: 600}
+	}
+	c.put(key(1), cachedFrame{data: []byte("1")})
+	f, ok := c.get(key(1))
+	assert(t, ok, true)
+	assert(t, string(f.data), "1")
+	_, ok = c.get(key(2))
+	assert(t, ok, false)
+
+	// image settings are part of the key
+	k := key(1)
+	k.settings.isNavigating = true
+	_, ok = c.get(k)
+	assert(t, ok, false)
+
+	// a changed scene drops all frames
+	c.invalidate()
+	_, ok = c.get(key(1))
+	assert(t, ok, false)
+
+	// the least recently used frame is dropped
+	for i := 0; i < frameCacheSize; i++ {
+		c.put(key(float32(i)), cachedFrame{})
+	}
+	c.get(key(0))
+	c.put(key(-1), cachedFrame{})
+	_, ok = c.get(key(0))
+	assert(t, ok, true)
+	_, ok = c.get(key(1))
+	assert(t, ok, false)
+	assert(t, len(c.frames), frameCacheSize)
+	assert(t, len(c.order), frameCacheSize)
+}
+
+func TestKeepsFrames(t *testing.T) {
+	assert(t, keepsFrames(Command{Cmd: "View", Val: "top"}), true)
+	assert(t, keepsFrames(Command{Cmd: "Mouseup", Val: "0", Moved: true}), true)
+	assert(t, keepsFrames(Command{Cmd: "Mouseup", Val: "0"}), false)
+	assert(t, keepsFrames(Command{Cmd: "Hide"}), false)
+}
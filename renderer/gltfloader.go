@@ -8,11 +8,25 @@ import (
 	"strconv"
 )
 
+// DefaultLoadOptions are used for sessions not requesting own import settings
+var DefaultLoadOptions LoadOptions
+
+// LoadOptions holds per session model import settings
+type LoadOptions struct {
+	MaxTriangles int     // meshes with more triangles get simplified, 0 disables the limit
+	Tolerance    float32 // simplification error in model units, 0 derives it from MaxTriangles
+	MaxTexture   int     // larger textures get downscaled to this width and height, 0 disables the limit
+	Progressive  bool    // load the scene without textures and stream them afterwards
+	Checksum     string  // expected sha256 of remote models, empty skips verification
+	User         string  // name of the session user shown in watermark captions
+	Journal      string  // key of the command journal replayed at start, empty disables journaling
+}
+
 // nameChildren names all gltf nodes by path
 func (app *RenderingApp) nameChildren(p string, n core.INode) {
 	node := n.GetNode()
 	node.SetName(p)
-	app.nodeBuffer[p] = node
+	app.nodeBuffer[p] = n
 	for _, child := range node.Children() {
 		idx := node.ChildIndex(child)
 		title := p + "/" + strconv.Itoa(idx)
@@ -20,23 +34,50 @@ func (app *RenderingApp) nameChildren(p string, n core.INode) {
 	}
 }
 
-// loadScene loads a gltf file
-func (app *RenderingApp) loadScene(fpath string) error {
-	app.sendMessageToClient("loading", fpath)
+// setUserData keeps gltf extras as node user data
+func setUserData(g *gltf.GLTF) {
+	for i, nodeData := range g.Nodes {
+		if nodeData.Extras == nil {
+			continue
+		}
+		if node, err := g.LoadNode(i); err == nil {
+			node.GetNode().SetUserData(nodeData.Extras)
+		}
+	}
+}
+
+// parseModel reads and parses a local or remote gltf file
+func (app *RenderingApp) parseModel(fpath string) (*gltf.GLTF, error) {
 	// Checks file extension
-	ext := filepath.Ext(fpath)
-	var g *gltf.GLTF
+	ext := modelExt(fpath)
 	var err error
 
-	// Parses file
-	if ext == ".gltf" {
-		g, err = gltf.ParseJSON(fpath)
-	} else if ext == ".glb" {
-		g, err = gltf.ParseBin(fpath)
+	if ext != ".gltf" && ext != ".glb" {
+		return nil, fmt.Errorf("unrecognized file extension:%s", ext)
+	}
+	var data []byte
+	var hash string
+	if IsRemoteModel(fpath) {
+		data, hash, err = models.fetch(fpath, app.loadOptions.Checksum)
 	} else {
-		return fmt.Errorf("unrecognized file extension:%s", ext)
+		data, hash, err = models.read(fpath)
+	}
+	if err != nil {
+		return nil, err
 	}
+	app.log.Debug("model %s hash %s", fpath, hash)
 
+	// Parses file, remote models can't reference files next to them
+	if IsRemoteModel(fpath) {
+		return models.parse(hash, data, ext, "")
+	}
+	return models.parse(hash, data, ext, filepath.Dir(fpath))
+}
+
+// loadScene loads a gltf file
+func (app *RenderingApp) loadScene(fpath string) error {
+	app.sendMessageToClient("loading", fpath)
+	g, err := app.parseModel(fpath)
 	if err != nil {
 		return err
 	}
@@ -46,15 +87,78 @@ func (app *RenderingApp) loadScene(fpath string) error {
 		defaultSceneIdx = *g.Scene
 	}
 
+	// textures are either streamed after the scene has been loaded or downscaled right away
+	var deferred []deferredTexture
+	if app.loadOptions.Progressive {
+		deferred = deferTextures(g)
+	} else if count := downscaleTextures(g, app.loadOptions.MaxTexture); count > 0 {
+		app.log.Info("downscaled %d textures to %d pixels", count, app.loadOptions.MaxTexture)
+	}
+
 	// Create default scene
 	n, err := g.LoadScene(defaultSceneIdx)
 	if err != nil {
 		return err
 	}
 
-	app.Scene().Add(n)
+	if shared := shareMeshGeometry(g); shared > 0 {
+		app.log.Info("sharing geometry of %d repeated mesh primitives", shared)
+	}
+
+	setUserData(g)
+	app.materialInfos = getMaterialInfos(g, deferred)
+
+	if removed := decimateScene(n, app.loadOptions); removed > 0 {
+		app.log.Info("simplified meshes by %d triangles", removed)
+	}
+
+	app.modelConfig = loadModelConfig(fpath)
+	if len(deferred) > 0 {
+		app.textureUpdates = make(chan func(), 2*len(deferred)+1)
+		go app.streamTextures(g, deferred, app.loadOptions.MaxTexture, app.textureUpdates)
+	} else {
+		app.textureMemory = estimateTextureMemory(g)
+	}
+	n.GetNode().SetScale(ModelScale, ModelScale, ModelScale)
+
+	// a reloaded model takes the place of the previous one, so node ids stay the same
+	if app.modelRoot != nil {
+		idx := app.Scene().ChildIndex(app.modelRoot)
+		app.Scene().RemoveAt(idx)
+		app.Scene().AddAt(idx, n)
+		app.modelRoot.GetNode().DisposeChildren(true)
+		app.modelRoot.Dispose()
+	} else {
+		app.Scene().Add(n)
+	}
+	app.modelRoot = n
+	app.frameCache.invalidate()
+	app.orbitBounds = nil
+	app.nodeBuffer = make(map[string]core.INode)
+	app.binding = bindingState{ramp: app.binding.ramp}
+	app.heatmap.overlays = nil
+	app.idPass.materials = nil
+	app.filters.hidden = nil
+	app.applyFilters()
+	app.colorBy.originals = nil
+	app.applyColorBy()
+	app.clashes.originals = nil
+	app.memory.close()
+	app.pickCycle = pickCycleState{}
+	app.drag = dragState{enabled: app.drag.enabled, normal: app.drag.normal, grid: app.drag.grid}
+	app.importLights(g)
+	app.removeShadows()
+	app.updateShadows()
+	app.removeGround()
+	app.updateGround()
+
+	app.buildCullingBoxes(n)
 	root := app.Scene().ChildIndex(n)
 	app.nameChildren("/"+strconv.Itoa(root), n)
+	app.applyClashes()
+	if app.heatmap.active != "" {
+		app.showHeatmap()
+	}
 	app.sendMessageToClient("loaded", fpath)
 	return nil
 }
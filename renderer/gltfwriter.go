@@ -0,0 +1,368 @@
+package renderer
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/geometry"
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/graphic"
+	"github.com/g3n/engine/material"
+	"github.com/g3n/engine/math32"
+)
+
+// gltf component and buffer target constants
+const (
+	gltfFloat        = 5126
+	gltfUnsignedInt  = 5125
+	gltfArrayBuffer  = 34962
+	gltfElementArray = 34963
+)
+
+// binary glTF header and chunk types
+const (
+	glbMagic = 0x46546C67 // glTF
+	glbJSON  = 0x4E4F534A
+	glbBIN   = 0x004E4942
+)
+
+// gltfDoc is the subset of a glTF 2.0 document written by gltfWriter
+type gltfDoc struct {
+	Asset       gltfAsset        `json:"asset"`
+	Scene       int              `json:"scene"`
+	Scenes      []gltfScene      `json:"scenes"`
+	Nodes       []gltfNode       `json:"nodes"`
+	Meshes      []gltfMesh       `json:"meshes,omitempty"`
+	Materials   []gltfMaterial   `json:"materials,omitempty"`
+	Accessors   []gltfAccessor   `json:"accessors,omitempty"`
+	BufferViews []gltfBufferView `json:"bufferViews,omitempty"`
+	Buffers     []gltfBuffer     `json:"buffers,omitempty"`
+}
+
+type gltfAsset struct {
+	Version   string `json:"version"`
+	Generator string `json:"generator"`
+}
+
+type gltfScene struct {
+	Nodes []int `json:"nodes"`
+}
+
+type gltfNode struct {
+	Name        string      `json:"name,omitempty"`
+	Mesh        *int        `json:"mesh,omitempty"`
+	Children    []int       `json:"children,omitempty"`
+	Translation *[3]float32 `json:"translation,omitempty"`
+	Rotation    *[4]float32 `json:"rotation,omitempty"`
+	Scale       *[3]float32 `json:"scale,omitempty"`
+	Extras      interface{} `json:"extras,omitempty"`
+}
+
+type gltfMesh struct {
+	Primitives []gltfPrimitive `json:"primitives"`
+}
+
+type gltfPrimitive struct {
+	Attributes map[string]int `json:"attributes"`
+	Indices    *int           `json:"indices,omitempty"`
+	Material   *int           `json:"material,omitempty"`
+}
+
+type gltfMaterial struct {
+	PbrMetallicRoughness gltfPbr `json:"pbrMetallicRoughness"`
+	DoubleSided          bool    `json:"doubleSided,omitempty"`
+}
+
+type gltfPbr struct {
+	BaseColorFactor [4]float32 `json:"baseColorFactor"`
+	MetallicFactor  float32    `json:"metallicFactor"`
+	RoughnessFactor float32    `json:"roughnessFactor"`
+}
+
+type gltfAccessor struct {
+	BufferView    int       `json:"bufferView"`
+	ComponentType int       `json:"componentType"`
+	Count         int       `json:"count"`
+	Type          string    `json:"type"`
+	Min           []float32 `json:"min,omitempty"`
+	Max           []float32 `json:"max,omitempty"`
+}
+
+type gltfBufferView struct {
+	Buffer     int `json:"buffer"`
+	ByteOffset int `json:"byteOffset"`
+	ByteLength int `json:"byteLength"`
+	Target     int `json:"target,omitempty"`
+}
+
+type gltfBuffer struct {
+	ByteLength int    `json:"byteLength"`
+	URI        string `json:"uri,omitempty"`
+}
+
+// gltfMeshKey identifies meshes sharing geometry and material
+type gltfMeshKey struct {
+	geom *geometry.Geometry
+	mat  material.IMaterial
+}
+
+// gltfWriter converts a scene graph into a glTF document with an embedded buffer.
+// Geometry, node hierarchy, transforms, names, user data and material colors are kept,
+// textures are not written.
+type gltfWriter struct {
+	doc         gltfDoc
+	data        bytes.Buffer
+	materials   map[material.IMaterial]int
+	meshes      map[gltfMeshKey]int
+	visibleOnly bool                          // hidden nodes and their children are left out
+	transforms  map[core.INode]math32.Matrix4 // replace the local transforms of root nodes
+}
+
+// newGLTFWriter creates an empty glTF document
+func newGLTFWriter(visibleOnly bool) *gltfWriter {
+	return &gltfWriter{
+		doc: gltfDoc{
+			Asset:  gltfAsset{Version: "2.0", Generator: "webg3n"},
+			Scenes: []gltfScene{{Nodes: []int{}}},
+			Nodes:  []gltfNode{},
+		},
+		materials:   make(map[material.IMaterial]int),
+		meshes:      make(map[gltfMeshKey]int),
+		visibleOnly: visibleOnly,
+	}
+}
+
+// writeGLTF writes the given nodes and their children as glTF document
+func writeGLTF(w io.Writer, nodes ...core.INode) error {
+	return newGLTFWriter(false).write(w, nodes...)
+}
+
+// write writes the given nodes as glTF document with the buffer embedded as data uri
+func (gw *gltfWriter) write(w io.Writer, nodes ...core.INode) error {
+	gw.addScene(nodes)
+	if gw.data.Len() > 0 {
+		gw.doc.Buffers = []gltfBuffer{{
+			ByteLength: gw.data.Len(),
+			URI:        "data:application/octet-stream;base64," + base64.StdEncoding.EncodeToString(gw.data.Bytes()),
+		}}
+	}
+	enc := json.NewEncoder(w)
+	return enc.Encode(gw.doc)
+}
+
+// writeBinary writes the given nodes as binary glTF with the buffer in a second chunk
+func (gw *gltfWriter) writeBinary(w io.Writer, nodes ...core.INode) error {
+	gw.addScene(nodes)
+	for gw.data.Len()%4 != 0 {
+		gw.data.WriteByte(0)
+	}
+	if gw.data.Len() > 0 {
+		gw.doc.Buffers = []gltfBuffer{{ByteLength: gw.data.Len()}}
+	}
+	doc, err := json.Marshal(gw.doc)
+	if err != nil {
+		return err
+	}
+	for len(doc)%4 != 0 {
+		doc = append(doc, ' ')
+	}
+	length := 12 + 8 + len(doc)
+	if gw.data.Len() > 0 {
+		length += 8 + gw.data.Len()
+	}
+	header := []uint32{glbMagic, 2, uint32(length), uint32(len(doc)), glbJSON}
+	if err := binary.Write(w, binary.LittleEndian, header); err != nil {
+		return err
+	}
+	if _, err := w.Write(doc); err != nil {
+		return err
+	}
+	if gw.data.Len() == 0 {
+		return nil
+	}
+	if err := binary.Write(w, binary.LittleEndian, []uint32{uint32(gw.data.Len()), glbBIN}); err != nil {
+		return err
+	}
+	_, err = w.Write(gw.data.Bytes())
+	return err
+}
+
+// addScene adds the given nodes as roots of the scene
+func (gw *gltfWriter) addScene(nodes []core.INode) {
+	for _, n := range nodes {
+		if gw.visibleOnly && !n.GetNode().Visible() {
+			continue
+		}
+		gw.doc.Scenes[0].Nodes = append(gw.doc.Scenes[0].Nodes, gw.addNode(n))
+	}
+}
+
+// addNode adds a node with all its children and returns its index
+func (gw *gltfWriter) addNode(inode core.INode) int {
+	node := inode.GetNode()
+	idx := len(gw.doc.Nodes)
+	gw.doc.Nodes = append(gw.doc.Nodes, gltfNode{Name: node.Name(), Extras: node.UserData()})
+
+	p, q, s := node.Position(), node.Quaternion(), node.Scale()
+	if m, ok := gw.transforms[inode]; ok {
+		m.Decompose(&p, &q, &s)
+	}
+	if p != (math32.Vector3{}) {
+		gw.doc.Nodes[idx].Translation = &[3]float32{p.X, p.Y, p.Z}
+	}
+	if q != (math32.Quaternion{W: 1}) {
+		gw.doc.Nodes[idx].Rotation = &[4]float32{q.X, q.Y, q.Z, q.W}
+	}
+	if s != (math32.Vector3{X: 1, Y: 1, Z: 1}) {
+		gw.doc.Nodes[idx].Scale = &[3]float32{s.X, s.Y, s.Z}
+	}
+	if mesh, ok := inode.(*graphic.Mesh); ok {
+		if m, ok := gw.addMesh(mesh); ok {
+			gw.doc.Nodes[idx].Mesh = &m
+		}
+	}
+
+	var children []int
+	for _, child := range node.Children() {
+		if gw.visibleOnly && !child.GetNode().Visible() {
+			continue
+		}
+		children = append(children, gw.addNode(child))
+	}
+	gw.doc.Nodes[idx].Children = children
+	return idx
+}
+
+// addMesh adds the geometry of a mesh with one primitive per material group
+func (gw *gltfWriter) addMesh(mesh *graphic.Mesh) (int, bool) {
+	geom := mesh.GetGeometry()
+	var firstMaterial material.IMaterial
+	if materials := mesh.Materials(); len(materials) > 0 {
+		firstMaterial = materials[0].IMaterial()
+	}
+	key := gltfMeshKey{geom, firstMaterial}
+	if idx, found := gw.meshes[key]; found {
+		return idx, true
+	}
+
+	positions := readAttribute(geom, gls.VertexPosition, 3)
+	if len(positions) == 0 {
+		return 0, false
+	}
+	attributes := map[string]int{"POSITION": gw.addPositions(positions)}
+	if normals := readAttribute(geom, gls.VertexNormal, 3); len(normals) == len(positions) {
+		attributes["NORMAL"] = gw.addAccessor(normals, "VEC3", len(normals)/3, nil, nil)
+	}
+	if uvs := readAttribute(geom, gls.VertexTexcoord, 2); len(uvs) == len(positions)/3*2 {
+		attributes["TEXCOORD_0"] = gw.addAccessor(uvs, "VEC2", len(uvs)/2, nil, nil)
+	}
+
+	indices := []uint32(geom.Indices())
+	if len(indices) == 0 {
+		indices = make([]uint32, len(positions)/3)
+		for i := range indices {
+			indices[i] = uint32(i)
+		}
+	}
+
+	materials := mesh.Materials()
+	var prims []gltfPrimitive
+	if geom.GroupCount() > 1 && len(materials) > 1 {
+		for i := 0; i < geom.GroupCount(); i++ {
+			group := geom.GroupAt(i)
+			end := group.Start + group.Count
+			if group.Start < 0 || end > len(indices) || group.Matindex >= len(materials) {
+				continue
+			}
+			prims = append(prims, gw.addPrimitive(attributes, indices[group.Start:end], materials[group.Matindex].IMaterial()))
+		}
+	} else {
+		prims = append(prims, gw.addPrimitive(attributes, indices, firstMaterial))
+	}
+
+	idx := len(gw.doc.Meshes)
+	gw.doc.Meshes = append(gw.doc.Meshes, gltfMesh{Primitives: prims})
+	gw.meshes[key] = idx
+	return idx, true
+}
+
+// addPrimitive adds the indices and material of a primitive
+func (gw *gltfWriter) addPrimitive(attributes map[string]int, indices []uint32, imat material.IMaterial) gltfPrimitive {
+	view := gw.addBufferView(indices, gltfElementArray)
+	acc := len(gw.doc.Accessors)
+	gw.doc.Accessors = append(gw.doc.Accessors, gltfAccessor{BufferView: view, ComponentType: gltfUnsignedInt, Count: len(indices), Type: "SCALAR"})
+	prim := gltfPrimitive{Attributes: attributes, Indices: &acc}
+	if imat != nil {
+		m := gw.addMaterial(imat)
+		prim.Material = &m
+	}
+	return prim
+}
+
+// addPositions adds vertex positions including their bounds, which glTF requires
+func (gw *gltfWriter) addPositions(positions []float32) int {
+	min := []float32{positions[0], positions[1], positions[2]}
+	max := []float32{positions[0], positions[1], positions[2]}
+	for i := 0; i < len(positions); i += 3 {
+		for k := 0; k < 3; k++ {
+			min[k] = math32.Min(min[k], positions[i+k])
+			max[k] = math32.Max(max[k], positions[i+k])
+		}
+	}
+	return gw.addAccessor(positions, "VEC3", len(positions)/3, min, max)
+}
+
+// addAccessor adds a float accessor
+func (gw *gltfWriter) addAccessor(values []float32, typ string, count int, min []float32, max []float32) int {
+	view := gw.addBufferView(values, gltfArrayBuffer)
+	gw.doc.Accessors = append(gw.doc.Accessors, gltfAccessor{BufferView: view, ComponentType: gltfFloat, Count: count, Type: typ, Min: min, Max: max})
+	return len(gw.doc.Accessors) - 1
+}
+
+// addBufferView appends little endian data to the buffer
+func (gw *gltfWriter) addBufferView(data interface{}, target int) int {
+	offset := gw.data.Len()
+	binary.Write(&gw.data, binary.LittleEndian, data)
+	gw.doc.BufferViews = append(gw.doc.BufferViews, gltfBufferView{ByteOffset: offset, ByteLength: gw.data.Len() - offset, Target: target})
+	return len(gw.doc.BufferViews) - 1
+}
+
+// addMaterial adds a material once, only colors of standard materials are known,
+// all other materials are written light gray
+func (gw *gltfWriter) addMaterial(imat material.IMaterial) int {
+	if idx, found := gw.materials[imat]; found {
+		return idx
+	}
+	m := gltfMaterial{PbrMetallicRoughness: gltfPbr{BaseColorFactor: [4]float32{0.8, 0.8, 0.8, 1}, RoughnessFactor: 1}}
+	if std, ok := imat.(*material.Standard); ok {
+		c := std.AmbientColor()
+		m.PbrMetallicRoughness.BaseColorFactor = [4]float32{c.R, c.G, c.B, 1}
+	}
+	if mat := imat.GetMaterial(); mat != nil {
+		m.DoubleSided = mat.Side() == material.SideDouble
+	}
+	idx := len(gw.doc.Materials)
+	gw.doc.Materials = append(gw.doc.Materials, m)
+	gw.materials[imat] = idx
+	return idx
+}
+
+// readAttribute returns a vertex attribute as flat list, regardless of interleaving
+func readAttribute(geom *geometry.Geometry, atype gls.AttribType, size int) []float32 {
+	vbo := geom.VBO(atype)
+	if vbo == nil {
+		return nil
+	}
+	stride := vbo.Stride()
+	offset := vbo.AttribOffset(atype)
+	buffer := *vbo.Buffer()
+	var values []float32
+	for i := offset; i+size <= len(buffer); i += stride {
+		values = append(values, buffer[i:i+size]...)
+	}
+	return values
+}
@@ -0,0 +1,82 @@
+package renderer
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/geometry"
+	"github.com/g3n/engine/graphic"
+	"github.com/g3n/engine/loader/gltf"
+	"github.com/g3n/engine/material"
+	"github.com/g3n/engine/math32"
+)
+
+func TestWriteGLTF(t *testing.T) {
+	root := core.NewNode()
+	root.SetName("root")
+	root.SetUserData(map[string]interface{}{"category": "wall"})
+	box := geometry.NewBox(1, 2, 3)
+	mat := material.NewStandard(math32.NewColor("red"))
+	first := graphic.NewMesh(box, mat)
+	first.SetPosition(1, 0, 0)
+	root.Add(first)
+	root.Add(graphic.NewMesh(box, mat))
+
+	var buf bytes.Buffer
+	assert(t, writeGLTF(&buf, root), nil)
+
+	g, err := gltf.ParseJSONReader(&buf, "")
+	assert(t, err, nil)
+	assert(t, len(g.Nodes), 3)
+	// meshes sharing geometry and material are written once
+	assert(t, len(g.Meshes), 1)
+	assert(t, len(g.Materials), 1)
+	assert(t, g.Nodes[0].Name, "root")
+	assert(t, g.Nodes[1].Translation[0], float32(1))
+
+	n, err := g.LoadScene(0)
+	assert(t, err, nil)
+	value, _ := getUserDataValue(g.Nodes[0].Extras, "category")
+	assert(t, value, "wall")
+	assert(t, len(n.GetNode().Children()), 2)
+}
+
+func TestConvertModel(t *testing.T) {
+	src := "v 0 0 0\nv 1 0 0\nv 0 1 0\nf 1 2 3\n"
+	var buf bytes.Buffer
+	assert(t, ConvertModel(strings.NewReader(src), ".obj", LoadOptions{}, &buf), nil)
+	g, err := gltf.ParseJSONReader(&buf, "")
+	assert(t, err, nil)
+	assert(t, len(g.Meshes), 1)
+
+	err = ConvertModel(strings.NewReader(src), ".stl", LoadOptions{}, &buf)
+	assert(t, err != nil, true)
+}
+
+func TestCheckEmbedded(t *testing.T) {
+	g := &gltf.GLTF{Buffers: []gltf.Buffer{{Uri: "data:application/octet-stream;base64,AAAA"}}}
+	assert(t, checkEmbedded(g), nil)
+	g.Images = []gltf.Image{{Uri: "../secret.png"}}
+	assert(t, checkEmbedded(g) != nil, true)
+}
+
+func TestWriteGLB(t *testing.T) {
+	root := core.NewNode()
+	mat := material.NewStandard(math32.NewColor("red"))
+	root.Add(graphic.NewMesh(geometry.NewBox(1, 2, 3), mat))
+	hidden := graphic.NewMesh(geometry.NewBox(1, 1, 1), mat)
+	hidden.SetVisible(false)
+	root.Add(hidden)
+
+	var buf bytes.Buffer
+	assert(t, newGLTFWriter(true).writeBinary(&buf, root), nil)
+	assert(t, buf.Len()%4, 0)
+	g, err := gltf.ParseBinReader(bytes.NewReader(buf.Bytes()), "")
+	assert(t, err, nil)
+	// the hidden mesh is left out
+	assert(t, len(g.Nodes), 2)
+	assert(t, len(g.Meshes), 1)
+	assert(t, len(g.Buffers), 1)
+}
@@ -0,0 +1,178 @@
+package renderer
+
+import (
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/geometry"
+	"github.com/g3n/engine/graphic"
+	"github.com/g3n/engine/material"
+	"github.com/g3n/engine/math32"
+)
+
+// size of the ground plane relative to the diagonal of the model
+const groundSize = 4
+
+// opacity of the ground plane, the rest is reflection
+const groundOpacity = 0.8
+
+// rings of the contact shadow, each darkens the ground a bit more towards the model center
+const contactShadowRings = 8
+
+// groundState holds the reflective ground plane below the model
+type groundState struct {
+	enabled bool
+	color   math32.Color
+	node    *core.Node // plane, contact shadow and mirrored model
+}
+
+// Ground toggles a reflective ground plane with a soft contact shadow below the model.
+// A color value as web color name or #rrggbb shows the ground in that color. The ground is changed by the render loop.
+func (app *RenderingApp) Ground(cmd Command) {
+	var color *math32.Color
+	if cmd.Val != "" && cmd.Val != "off" {
+		var err error
+		if color, err = parseColor(cmd.Val); err != nil {
+			app.sendMessageToClient("ground", err.Error())
+			return
+		}
+	}
+	update := func() {
+		switch {
+		case color != nil:
+			app.ground.color = *color
+			app.ground.enabled = true
+		case cmd.Val == "off":
+			app.ground.enabled = false
+		default:
+			app.ground.enabled = !app.ground.enabled
+		}
+		app.removeGround()
+		app.updateGround()
+	}
+	select {
+	case app.sceneUpdates <- update:
+	case <-app.quit:
+	}
+}
+
+// updateGround adds the ground below the current model if it is enabled
+func (app *RenderingApp) updateGround() {
+	g := &app.ground
+	if !g.enabled || g.node != nil || app.modelRoot == nil {
+		return
+	}
+	app.modelRoot.GetNode().UpdateMatrixWorld()
+	box, ok := getWorldBoundingBox([]core.INode{app.modelRoot})
+	if !ok {
+		return
+	}
+	center := box.Center(nil)
+	size := box.Size(nil)
+	diagonal := size.Length()
+	g.node = core.NewNode()
+	g.node.SetName("ground")
+
+	// the model mirrored at the ground, seen through the translucent plane
+	mirror := core.NewNode()
+	mirror.SetScale(1, -1, 1)
+	mirror.SetPositionY(2 * box.Min.Y)
+	reflection := app.modelRoot.Clone()
+	mirrorMaterials(reflection)
+	mirror.Add(reflection)
+	g.node.Add(mirror)
+
+	color := g.color
+	if color == (math32.Color{}) {
+		color = math32.Color{R: 0.85, G: 0.85, B: 0.85}
+	}
+	mat := material.NewStandard(&color)
+	mat.SetOpacity(groundOpacity)
+	mat.SetTransparent(true)
+	mat.SetSide(material.SideDouble)
+	plane := graphic.NewMesh(geometry.NewPlane(diagonal*groundSize, diagonal*groundSize, 1, 1), mat)
+	plane.SetRotationX(-math32.Pi / 2)
+	plane.SetPosition(center.X, box.Min.Y, center.Z)
+	g.node.Add(plane)
+
+	// stacked discs of falling size fade from the model footprint to the darker center
+	for i := 0; i < contactShadowRings; i++ {
+		shadow := material.NewStandard(&math32.Color{})
+		shadow.SetSpecularColor(&math32.Color{})
+		shadow.SetUseLights(material.UseLightNone)
+		shadow.SetOpacity(0.06)
+		shadow.SetTransparent(true)
+		shadow.SetSide(material.SideDouble)
+		shadow.SetDepthMask(false)
+		f := 1 - float32(i)/contactShadowRings*0.6
+		disc := graphic.NewMesh(geometry.NewCircle(0.5, 48), shadow)
+		disc.SetRotationX(-math32.Pi / 2)
+		disc.SetScale(size.X*f*1.1, size.Z*f*1.1, 1)
+		disc.SetPosition(center.X, box.Min.Y+diagonal*0.0002, center.Z)
+		g.node.Add(disc)
+	}
+	app.Scene().Add(g.node)
+}
+
+// removeGround removes the ground and the mirrored model, which shares geometries with the model
+func (app *RenderingApp) removeGround() {
+	if app.ground.node == nil {
+		return
+	}
+	app.Scene().Remove(app.ground.node)
+	app.ground.node = nil
+}
+
+// isGround returns true for the ground and its reflection, which are not picked
+func (app *RenderingApp) isGround(inode core.INode) bool {
+	for n := inode; n != nil && app.ground.node != nil; n = n.GetNode().Parent() {
+		if n == core.INode(app.ground.node) {
+			return true
+		}
+	}
+	return false
+}
+
+// mirrorMaterials gives all graphics of a mirrored tree copies of their materials showing the opposite side,
+// as mirroring turns the winding of all faces
+func mirrorMaterials(root core.INode) {
+	mirrored := make(map[material.IMaterial]material.IMaterial)
+	forEachGraphic([]core.INode{root}, func(inode core.INode) {
+		gnode := inode.(graphic.IGraphic)
+		gfx := gnode.GetGraphic()
+		materials := append([]graphic.GraphicMaterial(nil), gfx.Materials()...)
+		gfx.ClearMaterials()
+		for _, gm := range materials {
+			mat, ok := mirrored[gm.IMaterial()]
+			if !ok {
+				mat = mirrorMaterial(gm.IMaterial())
+				mirrored[gm.IMaterial()] = mat
+			}
+			gfx.AddMaterial(gnode, mat, 0, 0)
+		}
+	})
+}
+
+// mirrorMaterial returns a copy of a material with front and back side swapped
+func mirrorMaterial(imat material.IMaterial) material.IMaterial {
+	switch m := imat.(type) {
+	case *material.Standard:
+		c := *m
+		c.SetSide(oppositeSide(m.Side()))
+		return &c
+	case *material.Physical:
+		c := *m
+		c.SetSide(oppositeSide(m.Side()))
+		return &c
+	}
+	return imat
+}
+
+// oppositeSide swaps front and back side
+func oppositeSide(side material.Side) material.Side {
+	switch side {
+	case material.SideFront:
+		return material.SideBack
+	case material.SideBack:
+		return material.SideFront
+	}
+	return side
+}
@@ -0,0 +1,22 @@
+package renderer
+
+import (
+	"testing"
+
+	"github.com/g3n/engine/material"
+	"github.com/g3n/engine/math32"
+)
+
+func TestMirrorMaterial(t *testing.T) {
+	mat := material.NewStandard(&math32.Color{R: 1})
+	mirrored := mirrorMaterial(mat).(*material.Standard)
+	if mirrored == mat {
+		t.Error("material was not copied")
+	}
+	assert(t, mirrored.Side(), material.SideBack)
+	assert(t, mat.Side(), material.SideFront)
+
+	mat.SetSide(material.SideDouble)
+	assert(t, mirrorMaterial(mat).(*material.Standard).Side(), material.SideDouble)
+	assert(t, oppositeSide(material.SideBack), material.SideFront)
+}
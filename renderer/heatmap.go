@@ -0,0 +1,241 @@
+package renderer
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"strconv"
+	"strings"
+
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/geometry"
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/graphic"
+	"github.com/g3n/engine/material"
+	"github.com/g3n/engine/math32"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+// heatmapState holds per-vertex scalar fields and the meshes showing the active field
+type heatmapState struct {
+	fields     map[string]map[string][]float32 // field name, node id, values per vertex
+	active     string
+	min, max   float32
+	fixedRange bool
+	overlays   map[core.INode]*graphic.Mesh // original mesh, overlay
+}
+
+// SetField queues per-vertex values of a scalar field for a mesh node by id.
+// The node gets recolored before the next frame if the field is shown.
+func (app *RenderingApp) SetField(name string, node string, values []float32) {
+	if app.Window() == nil {
+		return
+	}
+	update := func() {
+		h := &app.heatmap
+		if h.fields == nil {
+			h.fields = make(map[string]map[string][]float32)
+		}
+		if h.fields[name] == nil {
+			h.fields[name] = make(map[string][]float32)
+		}
+		h.fields[name][node] = values
+		if h.active == name {
+			app.showHeatmap()
+		}
+	}
+	select {
+	case app.sceneUpdates <- update:
+	case <-app.quit:
+	}
+}
+
+// Heatmap shows a scalar field by name as false colors, an empty value hides the heatmap
+func (app *RenderingApp) Heatmap(cmd Command) {
+	update := func() {
+		// fields are added on the render thread
+		if _, ok := app.heatmap.fields[cmd.Val]; !ok && cmd.Val != "" {
+			app.sendMessageToClient("heatmap", "unknown field "+cmd.Val)
+			return
+		}
+		app.heatmap.active = cmd.Val
+		app.showHeatmap()
+	}
+	select {
+	case app.sceneUpdates <- update:
+	case <-app.quit:
+	}
+}
+
+// Heatmaprange sets the value range of the heatmap as min:max, an empty value fits the range to the field
+func (app *RenderingApp) Heatmaprange(cmd Command) {
+	var min, max float32
+	if cmd.Val != "" {
+		var err error
+		if min, max, err = parseRange(cmd.Val); err != nil {
+			app.sendMessageToClient("heatmap", err.Error())
+			return
+		}
+	}
+	update := func() {
+		app.heatmap.fixedRange = cmd.Val != ""
+		if app.heatmap.fixedRange {
+			app.heatmap.min, app.heatmap.max = min, max
+		}
+		app.showHeatmap()
+	}
+	select {
+	case app.sceneUpdates <- update:
+	case <-app.quit:
+	}
+}
+
+// showHeatmap replaces all meshes having values of the active field by colored overlays
+func (app *RenderingApp) showHeatmap() {
+	app.hideHeatmap()
+	h := &app.heatmap
+	field, ok := h.fields[h.active]
+	if !ok {
+		return
+	}
+	if !h.fixedRange {
+		h.min, h.max = fieldRange(field)
+	}
+	h.overlays = make(map[core.INode]*graphic.Mesh)
+	for id, values := range field {
+		mesh, ok := app.nodeBuffer[id].(*graphic.Mesh)
+		if !ok || mesh.Parent() == nil {
+			app.log.Warn("heatmap: node %s is not a mesh", id)
+			continue
+		}
+		app.loadGeometries([]core.INode{mesh})
+		overlay, err := newHeatmapMesh(mesh, values, h.min, h.max)
+		if err != nil {
+			app.log.Warn("heatmap: node %s: %v", id, err)
+			continue
+		}
+		mesh.Parent().GetNode().Add(overlay)
+		mesh.SetVisible(false)
+		h.overlays[mesh] = overlay
+	}
+	if app.modelRoot != nil {
+		app.buildCullingBoxes(app.modelRoot)
+	}
+	app.sendDataToClient("heatmap", map[string]interface{}{"field": h.active, "min": h.min, "max": h.max})
+}
+
+// hideHeatmap removes all overlays and shows the original meshes again
+func (app *RenderingApp) hideHeatmap() {
+	for inode, overlay := range app.heatmap.overlays {
+		inode.GetNode().SetVisible(overlay.Visible())
+		overlay.Parent().GetNode().Remove(overlay)
+		overlay.Dispose()
+	}
+	app.heatmap.overlays = nil
+}
+
+// newHeatmapMesh returns an unlit copy of a mesh colored by per-vertex values.
+// A new geometry is needed because vertex attributes are fixed once a geometry got rendered.
+func newHeatmapMesh(mesh *graphic.Mesh, values []float32, min, max float32) (*graphic.Mesh, error) {
+	geom := mesh.GetGeometry()
+	positions := readAttribute(geom, gls.VertexPosition, 3)
+	if len(values) != len(positions)/3 {
+		return nil, fmt.Errorf("got %d values for %d vertices", len(values), len(positions)/3)
+	}
+	colors := make([]float32, 0, len(positions))
+	for _, v := range values {
+		c := heatmapColor(v, min, max)
+		colors = append(colors, c.R, c.G, c.B)
+	}
+	overlayGeom := geometry.NewGeometry()
+	overlayGeom.AddVBO(gls.NewVBO(math32.ArrayF32(positions)).AddAttrib(gls.VertexPosition))
+	overlayGeom.AddVBO(gls.NewVBO(math32.ArrayF32(colors)).AddAttrib(gls.VertexColor))
+	if indices := geom.Indices(); len(indices) > 0 {
+		overlayGeom.SetIndices(append(math32.ArrayU32(nil), indices...))
+	}
+	mat := material.NewBasic()
+	mat.SetSide(material.SideDouble)
+	overlay := graphic.NewMesh(overlayGeom, mat)
+	position, quaternion, scale := mesh.Position(), mesh.Quaternion(), mesh.Scale()
+	overlay.SetPositionVec(&position)
+	overlay.SetQuaternionQuat(&quaternion)
+	overlay.SetScaleVec(&scale)
+	overlay.SetName(mesh.Name())
+	overlay.SetUserData(mesh.UserData())
+	overlay.SetVisible(mesh.Visible())
+	return overlay, nil
+}
+
+// heatmapColor maps a value within min and max onto the default color ramp
+func heatmapColor(value, min, max float32) math32.Color {
+	if max <= min {
+		return DefaultColorRamp.At(0)
+	}
+	return DefaultColorRamp.At((value - min) / (max - min))
+}
+
+// fieldRange returns the smallest and largest value of a field
+func fieldRange(field map[string][]float32) (float32, float32) {
+	first := true
+	var min, max float32
+	for _, values := range field {
+		for _, v := range values {
+			if first {
+				min, max, first = v, v, false
+				continue
+			}
+			min = math32.Min(min, v)
+			max = math32.Max(max, v)
+		}
+	}
+	return min, max
+}
+
+// parseRange parses a min:max range
+func parseRange(value string) (float32, float32, error) {
+	s := strings.Split(value, ":")
+	if len(s) != 2 {
+		return 0, 0, fmt.Errorf("invalid range %s, expected min:max", value)
+	}
+	min, err := strconv.ParseFloat(s[0], 32)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid range minimum %s", s[0])
+	}
+	max, err := strconv.ParseFloat(s[1], 32)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid range maximum %s", s[1])
+	}
+	if max <= min {
+		return 0, 0, fmt.Errorf("range maximum must be larger than minimum")
+	}
+	return float32(min), float32(max), nil
+}
+
+// DrawLegend draws a color bar with the value range of the heatmap at the right edge of the image
+func DrawLegend(img *image.RGBA, name string, min, max float32) *image.RGBA {
+	b := img.Bounds()
+	height := b.Dy() / 3
+	if height < 40 {
+		return img
+	}
+	x0, x1 := b.Max.X-60, b.Max.X-44
+	y0 := b.Max.Y - 20 - height
+	for y := 0; y < height; y++ {
+		c := DefaultColorRamp.At(1 - float32(y)/float32(height-1))
+		rgba := color.RGBA{R: uint8(c.R * 255), G: uint8(c.G * 255), B: uint8(c.B * 255), A: 255}
+		for x := x0; x < x1; x++ {
+			img.SetRGBA(x, y0+y, rgba)
+		}
+	}
+	d := font.Drawer{Dst: img, Src: image.Black, Face: basicfont.Face7x13}
+	drawText := func(x, y int, text string) {
+		d.Dot = fixed.P(x, y)
+		d.DrawString(text)
+	}
+	drawText(x1+4, y0+10, strconv.FormatFloat(float64(max), 'g', 4, 32))
+	drawText(x1+4, y0+height, strconv.FormatFloat(float64(min), 'g', 4, 32))
+	drawText(x0, y0-6, name)
+	return img
+}
@@ -0,0 +1,41 @@
+package renderer
+
+import (
+	"image"
+	"testing"
+)
+
+func TestFieldRange(t *testing.T) {
+	min, max := fieldRange(map[string][]float32{"/0/1": {2, -1, 5}, "/0/2": {7, 0}})
+	assert(t, min, float32(-1))
+	assert(t, max, float32(7))
+	min, max = fieldRange(nil)
+	assert(t, min, float32(0))
+	assert(t, max, float32(0))
+}
+
+func TestHeatmapColor(t *testing.T) {
+	assert(t, heatmapColor(10, 10, 20), DefaultColorRamp[0].Color)
+	assert(t, heatmapColor(15, 10, 20), DefaultColorRamp[1].Color)
+	assert(t, heatmapColor(30, 10, 20), DefaultColorRamp[2].Color)
+	assert(t, heatmapColor(5, 5, 5), DefaultColorRamp[0].Color)
+}
+
+func TestParseRange(t *testing.T) {
+	min, max, err := parseRange("-2.5:10")
+	assert(t, err, nil)
+	assert(t, min, float32(-2.5))
+	assert(t, max, float32(10))
+	_, _, err = parseRange("10:2")
+	assert(t, err != nil, true)
+	_, _, err = parseRange("10")
+	assert(t, err != nil, true)
+}
+
+func TestDrawLegend(t *testing.T) {
+	img := DrawLegend(image.NewRGBA(image.Rect(0, 0, 200, 150)), "stress", 0, 1)
+	top := img.RGBAAt(145, 150-20-50)
+	bottom := img.RGBAAt(145, 150-21)
+	assert(t, top.R, uint8(255))
+	assert(t, bottom.B, uint8(255))
+}
@@ -0,0 +1,115 @@
+package renderer
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"sync"
+	"time"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+// hudDuration is the time a HUD text sent by the client is shown
+const hudDuration = 5 * time.Second
+
+// hudLineHeight is the distance between HUD lines in pixels
+const hudLineHeight = 20
+
+// HudMessage is a status text drawn into the frames of a session
+type HudMessage struct {
+	Text    string
+	Warning bool
+}
+
+// hudEntry is a HUD message by key, a zero expiry keeps it until it is replaced or removed
+type hudEntry struct {
+	key     string
+	message HudMessage
+	expires time.Time
+}
+
+// hudState holds the HUD messages of a session, they can be shown from any goroutine
+type hudState struct {
+	sync.Mutex
+	entries []hudEntry // oldest first
+}
+
+// Hud shows a text at the top of the frames for a few seconds, so thin clients only displaying the image stream see it.
+// An empty value removes the text.
+func (app *RenderingApp) Hud(cmd Command) {
+	app.ShowHud("client", cmd.Val, false, hudDuration)
+}
+
+// ShowHud shows a text in the frames of the session, a message with the same key is replaced and an empty text removes it.
+// A zero duration shows it until it is replaced or removed, warnings are highlighted.
+func (app *RenderingApp) ShowHud(key, text string, warning bool, duration time.Duration) {
+	app.hud.show(key, HudMessage{Text: text, Warning: warning}, duration, time.Now())
+}
+
+// show adds, replaces or removes a message by key
+func (h *hudState) show(key string, message HudMessage, duration time.Duration, now time.Time) {
+	h.Lock()
+	defer h.Unlock()
+	entries := h.entries[:0]
+	for _, e := range h.entries {
+		if e.key != key {
+			entries = append(entries, e)
+		}
+	}
+	h.entries = entries
+	if message.Text == "" {
+		return
+	}
+	e := hudEntry{key: key, message: message}
+	if duration > 0 {
+		e.expires = now.Add(duration)
+	}
+	h.entries = append(h.entries, e)
+}
+
+// messages drops expired messages and returns the remaining ones
+func (h *hudState) messages(now time.Time) []HudMessage {
+	h.Lock()
+	defer h.Unlock()
+	var messages []HudMessage
+	entries := h.entries[:0]
+	for _, e := range h.entries {
+		if !e.expires.IsZero() && now.After(e.expires) {
+			continue
+		}
+		entries = append(entries, e)
+		messages = append(messages, e.message)
+	}
+	h.entries = entries
+	return messages
+}
+
+// active returns true if any message is shown, frames are not cached meanwhile
+func (h *hudState) active() bool {
+	h.Lock()
+	defer h.Unlock()
+	return len(h.entries) > 0
+}
+
+// DrawHud writes messages centered at the top of the image on a dark box, warnings in amber
+func DrawHud(img *image.RGBA, messages []HudMessage) *image.RGBA {
+	b := img.Bounds()
+	face := basicfont.Face7x13
+	for i, m := range messages {
+		width := font.MeasureString(face, m.Text).Ceil()
+		x := b.Min.X + (b.Dx()-width)/2
+		y := b.Min.Y + watermarkMargin + i*hudLineHeight
+		box := image.Rect(x-6, y, x+width+6, y+hudLineHeight-2)
+		draw.Draw(img, box, image.NewUniform(color.RGBA{A: 160}), image.Point{}, draw.Over)
+		src := image.White
+		if m.Warning {
+			src = image.NewUniform(color.RGBA{R: 255, G: 176, A: 255})
+		}
+		d := font.Drawer{Dst: img, Src: src, Face: face, Dot: fixed.P(x, y+hudLineHeight-7)}
+		d.DrawString(m.Text)
+	}
+	return img
+}
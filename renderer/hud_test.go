@@ -0,0 +1,41 @@
+package renderer
+
+import (
+	"image"
+	"image/color"
+	"testing"
+	"time"
+)
+
+func TestHudMessages(t *testing.T) {
+	var h hudState
+	now := time.Now()
+	assert(t, h.active(), false)
+	h.show("textures", HudMessage{Text: "Loading textures... 0%"}, 0, now)
+	h.show("client", HudMessage{Text: "Hello"}, time.Second, now)
+	h.show("textures", HudMessage{Text: "Loading textures... 43%"}, 0, now)
+	messages := h.messages(now)
+	assert(t, len(messages), 2)
+	assert(t, messages[0].Text, "Hello")
+	assert(t, messages[1].Text, "Loading textures... 43%")
+
+	messages = h.messages(now.Add(2 * time.Second))
+	assert(t, len(messages), 1)
+	assert(t, messages[0].Text, "Loading textures... 43%")
+
+	h.show("textures", HudMessage{}, 0, now)
+	assert(t, h.active(), false)
+}
+
+func TestDrawHud(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 200, 100))
+	for i := range img.Pix {
+		img.Pix[i] = 255
+	}
+	DrawHud(img, []HudMessage{{Text: "Loading"}, {Text: "Warning", Warning: true}})
+	// dark box behind the first line at the top center, the bottom stays untouched
+	assert(t, img.RGBAAt(100, watermarkMargin+1).R < 128, true)
+	assert(t, img.RGBAAt(100, watermarkMargin+hudLineHeight+1).R < 128, true)
+	assert(t, img.RGBAAt(100, 90), color.RGBA{R: 255, G: 255, B: 255, A: 255})
+	assert(t, img.RGBAAt(5, watermarkMargin+1), color.RGBA{R: 255, G: 255, B: 255, A: 255})
+}
@@ -0,0 +1,185 @@
+package renderer
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/base64"
+	"image"
+	"image/png"
+
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/graphic"
+	"github.com/g3n/engine/material"
+	"github.com/g3n/engine/math32"
+	"github.com/moethu/imaging"
+)
+
+// number of frames between two object id passes
+const idPassInterval = 10
+
+// GPUPicking selects nodes by the object id pass instead of raycasting by default
+var GPUPicking = false
+
+// idPassState holds the object id render pass
+type idPassState struct {
+	enabled   bool
+	materials map[core.INode]*material.Standard
+	nodes     []string // node ids by object id - 1
+	md5       [16]byte
+}
+
+// idFrame is an object id image sent to the client.
+// Each pixel holds the object id as 24 bit rgb color, 0 is the background.
+// Nodes lists the node ids by object id - 1 and is only sent if it changed.
+type idFrame struct {
+	Image string   `json:"image"`
+	Nodes []string `json:"nodes,omitempty"`
+}
+
+// Idbuffer toggles the object id stream used for picking on the client
+func (app *RenderingApp) Idbuffer(cmd Command) {
+	app.idPass.enabled = !app.idPass.enabled
+	app.idPass.nodes = nil
+	app.idPass.md5 = [16]byte{}
+}
+
+// streamIDs sends the object id image to the client if it changed
+func (app *RenderingApp) streamIDs() {
+	if app.modelRoot == nil {
+		return
+	}
+	p := &app.idPass
+	data, inodes := app.renderIDs(app.Width, app.Height)
+	img := image.NewRGBA(image.Rect(0, 0, app.Width, app.Height))
+	img.Pix = data
+	buf := new(bytes.Buffer)
+	if err := png.Encode(buf, imaging.FlipV(img)); err != nil {
+		app.log.Error("encoding id buffer failed: %v", err)
+		return
+	}
+	nodes := make([]string, len(inodes))
+	for i, inode := range inodes {
+		nodes[i] = inode.GetNode().Name()
+	}
+	frame := idFrame{}
+	if !equalStrings(nodes, p.nodes) {
+		frame.Nodes = nodes
+		p.nodes = nodes
+	} else {
+		md := md5.Sum(buf.Bytes())
+		if md == p.md5 {
+			return
+		}
+		p.md5 = md
+	}
+	frame.Image = base64.StdEncoding.EncodeToString(buf.Bytes())
+	app.sendDataToClient("ids", frame)
+}
+
+// pickID renders the object id pass and returns the graphic at the given screen coordinates
+func (app *RenderingApp) pickID(mx float32, my float32) (core.INode, bool) {
+	x, y := int(mx), app.Height-1-int(my)
+	if app.modelRoot == nil || x < 0 || y < 0 || x >= app.Width || y >= app.Height {
+		return nil, false
+	}
+	// the pixel at x, y is the last one of the read area
+	data, inodes := app.renderIDs(x+1, y+1)
+	i := len(data) - 4
+	id := idFromPixel(data[i], data[i+1], data[i+2])
+	if id == 0 || id > len(inodes) {
+		return nil, false
+	}
+	return inodes[id-1], true
+}
+
+// renderIDs renders every graphic in a flat color derived from its object id.
+// It returns the pixels of the lower left area of the given size and the graphics by object id - 1.
+func (app *RenderingApp) renderIDs(width, height int) ([]byte, []core.INode) {
+	p := &app.idPass
+	if p.materials == nil {
+		p.materials = make(map[core.INode]*material.Standard)
+	}
+	var nodes []core.INode
+	originals := make(map[core.INode][]graphic.GraphicMaterial)
+	forEachGraphic([]core.INode{app.modelRoot}, func(inode core.INode) {
+		nodes = append(nodes, inode)
+		color := idColor(len(nodes))
+		mat, ok := p.materials[inode]
+		if !ok {
+			mat = material.NewStandard(&math32.Color{})
+			mat.SetSpecularColor(&math32.Color{})
+			mat.SetUseLights(material.UseLightNone)
+			mat.SetSide(material.SideDouble)
+			// object ids must not be linearized
+			mat.ShaderDefines.Set(rawOutputDefine, "")
+			p.materials[inode] = mat
+		}
+		mat.SetEmissiveColor(&color)
+		gnode := inode.(graphic.IGraphic)
+		gfx := gnode.GetGraphic()
+		originals[inode] = append([]graphic.GraphicMaterial(nil), gfx.Materials()...)
+		gfx.ClearMaterials()
+		gfx.AddMaterial(gnode, mat, 0, 0)
+	})
+
+	// the mirrored model keeps its materials
+	if app.ground.node != nil {
+		app.ground.node.SetVisible(false)
+		defer app.ground.node.SetVisible(true)
+	}
+	gl := app.Gl()
+	gl.ClearColor(0, 0, 0, 1)
+	if _, err := app.Renderer().Render(app.Camera()); err != nil {
+		app.log.Error("id pass failed: %v", err)
+	}
+	data := gl.ReadPixels(0, 0, width, height, gls.RGBA, gls.UNSIGNED_BYTE)
+	gl.ClearColor(1, 1, 1, 1)
+	for inode, materials := range originals {
+		gfx := inode.(graphic.IGraphic).GetGraphic()
+		gfx.ClearMaterials()
+		for _, gm := range materials {
+			gfx.AddMaterial(gm.IGraphic(), gm.IMaterial(), 0, 0)
+		}
+	}
+	return data, nodes
+}
+
+// idColor returns the flat color of an object id
+func idColor(id int) math32.Color {
+	return math32.Color{
+		R: float32(id>>16&0xff) / 255,
+		G: float32(id>>8&0xff) / 255,
+		B: float32(id&0xff) / 255,
+	}
+}
+
+// idFromPixel returns the object id of an id buffer pixel
+func idFromPixel(r, g, b uint8) int {
+	return int(r)<<16 | int(g)<<8 | int(b)
+}
+
+// equalStrings returns true if both lists hold the same strings
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Picking switches between gpu and raycast picking of clicked nodes
+func (app *RenderingApp) Picking(cmd Command) {
+	switch cmd.Val {
+	case "gpu":
+		app.gpuPicking = true
+	case "raycast":
+		app.gpuPicking = false
+	default:
+		app.sendMessageToClient("picking", "unknown picking mode "+cmd.Val)
+	}
+}
@@ -0,0 +1,26 @@
+package renderer
+
+import (
+	"testing"
+)
+
+func TestIDColor(t *testing.T) {
+	for _, id := range []int{1, 255, 256, 70000, 0xffffff} {
+		c := idColor(id)
+		assert(t, idFromPixel(uint8(c.R*255+0.5), uint8(c.G*255+0.5), uint8(c.B*255+0.5)), id)
+	}
+}
+
+func TestEqualStrings(t *testing.T) {
+	assert(t, equalStrings([]string{"/0/1"}, []string{"/0/1"}), true)
+	assert(t, equalStrings([]string{"/0/1"}, []string{"/0/2"}), false)
+	assert(t, equalStrings(nil, []string{"/0/2"}), false)
+}
+
+func TestPicking(t *testing.T) {
+	app := RenderingApp{}
+	app.Picking(Command{Val: "gpu"})
+	assert(t, app.gpuPicking, true)
+	app.Picking(Command{Val: "raycast"})
+	assert(t, app.gpuPicking, false)
+}
@@ -3,10 +3,10 @@ package renderer
 import (
 	"bytes"
 	"crypto/md5"
-	"encoding/base64"
 	"image"
 	"image/jpeg"
 	"image/png"
+	"time"
 
 	"github.com/moethu/imaging"
 	libjpeg "github.com/pixiv/go-libjpeg/jpeg"
@@ -14,7 +14,14 @@ import (
 
 // onRender event handler for onRender event
 func (app *RenderingApp) onRender(evname string, ev interface{}) {
+	if app.depth.encoder != "" {
+		app.streamDepth()
+	}
 	app.makeScreenShot()
+	// the id pass overwrites the frame, so it runs after the frame has been read
+	if app.idPass.enabled && app.FrameCount()%idPassInterval == 0 {
+		app.streamIDs()
+	}
 }
 
 var md5SumBuffer [16]byte
@@ -23,11 +30,57 @@ var md5SumBuffer [16]byte
 func (app *RenderingApp) makeScreenShot() {
 	w := app.Width
 	h := app.Height
-	data := app.Gl().ReadPixels(0, 0, w, h, 6408, 5121)
-	img := image.NewRGBA(image.Rect(0, 0, w, h))
-	img.Pix = data
+	if key, ok := app.frameKey(); ok {
+		if frame, ok := app.frameCache.get(key); ok {
+			if app.throttleFrame(time.Now()) {
+				app.sendFrame(frame)
+			}
+			return
+		}
+	}
+	var data []byte
+	if app.quad.enabled {
+		data = app.renderQuad()
+	} else if app.stereo.mode != stereoOff {
+		data = app.renderStereo()
+	} else {
+		data = app.Gl().ReadPixels(0, 0, w, h, 6408, 5121)
+	}
+	if app.fog.mode != fogOff && app.singleView() {
+		// the depth buffer is read into the same buffer, before the outline pass renders again
+		data = app.buffers.copyPixels(data)
+		app.applyFog(data)
+	}
+	if app.effects.blurRadius > 0 && app.singleView() {
+		// the depth buffer is read into the same buffer
+		data = app.buffers.copyPixels(data)
+		app.applyDepthOfField(data)
+	}
+	if app.outlinesSelection() {
+		// the outline pass reads pixels into the same buffer
+		data = app.buffers.copyPixels(data)
+		app.drawSelectionOutline(data)
+	}
+	if app.compare.root != nil && app.singleView() {
+		// the comparison pass reads pixels into the same buffer
+		data = app.buffers.copyPixels(data)
+		app.composeComparison(data)
+	}
+	minimap := app.minimap.enabled && app.singleView()
+	if minimap {
+		// the minimap pass reads pixels into the same buffer
+		data = app.buffers.copyPixels(data)
+		app.renderMinimap()
+	}
+	if LinearWorkflow {
+		// converted after all passes blended their pixels in linear color space
+		app.output.apply(data)
+	}
+	img := rgbaImage(data, w, h)
 
-	if app.imageSettings.getPixelation() > 1.0 {
+	roi := app.imageSettings.roi != image.Rectangle{}
+	// with a region of interest only the periphery gets pixelated
+	if app.imageSettings.getPixelation() > 1.0 && !roi {
 		img = imaging.Fit(img, int(float64(w)/app.imageSettings.getPixelation()), int(float64(h)/app.imageSettings.getPixelation()), imaging.NearestNeighbor)
 	}
 	if app.imageSettings.brightness != 0 {
@@ -45,18 +98,95 @@ func (app *RenderingApp) makeScreenShot() {
 	if app.imageSettings.invert {
 		img = imaging.Invert(img)
 	}
+	if app.effects.bloom > 0 {
+		applyBloom(img, app.effects.bloom, app.effects.threshold)
+	}
+	if app.effects.vignette > 0 {
+		applyVignette(img, app.effects.vignette)
+	}
 
-	img = imaging.FlipV(img)
+	img = app.buffers.flipV(img)
 
+	if app.quad.enabled {
+		img = DrawViewportLabels(img)
+	}
+	if app.heatmap.active != "" {
+		img = DrawLegend(img, app.heatmap.active, app.heatmap.min, app.heatmap.max)
+	}
+	if app.scaleBar {
+		img = DrawScaleBar(img, app.viewHeight(), app.modelConfig.Unit)
+	}
+	if minimap && app.minimap.frame != nil {
+		img = DrawMinimap(img, app.minimap.frame)
+	}
+	app.captureFrame(img)
+	app.recordFrame(img)
+	app.turntableFrame(img)
+	if roi {
+		pixelateOutside(img, app.imageSettings.roi, app.imageSettings.getRoiPixelation())
+	}
+	if messages := app.hud.messages(time.Now()); len(messages) > 0 {
+		img = DrawHud(img, messages)
+	}
 	if app.Debug {
 		img = DrawByteGraph(img)
 	}
 
-	buf := new(bytes.Buffer)
-	var err interface{}
-	switch app.imageSettings.encoder {
+	if !app.throttleFrame(time.Now()) {
+		return
+	}
+	if app.delta.interval > 0 {
+		app.streamDelta(img)
+		return
+	}
+	if app.tiles.size > 0 {
+		app.streamTiles(img)
+		return
+	}
+
+	buf := encodePool.Get().(*bytes.Buffer)
+	defer encodePool.Put(buf)
+	if err := app.encodeImage(buf, img); err != nil {
+		panic(err)
+	}
+
+	// get md5 checksum from image to check if image changed
+	// only send a new image to the client if there has been any change.
+	md := md5.Sum(buf.Bytes())
+	key, cacheable := app.frameKey()
+	if md5SumBuffer == md && !cacheable {
+		return
+	}
+	frame := cachedFrame{sum: md, data: encodeBase64(buf.Bytes())}
+	if cacheable {
+		app.frameCache.put(key, frame)
+	}
+	app.sendFrame(frame)
+}
+
+// sendFrame streams an encoded frame unless it is the frame sent last
+func (app *RenderingApp) sendFrame(frame cachedFrame) {
+	if md5SumBuffer == frame.sum {
+		return
+	}
+	if app.Debug {
+		AddToByteBuffer(len(frame.data))
+	}
+	app.stampFrame()
+	app.stream(frame.data)
+	md5SumBuffer = frame.sum
+}
+
+// encodeImage encodes an image with the encoder and quality of the session into a reset buffer
+func (app *RenderingApp) encodeImage(buf *bytes.Buffer, img image.Image) error {
+	buf.Reset()
+	var err error
+	encoder := app.imageSettings.getEncoder()
+	switch encoder {
 	case "png":
 		err = png.Encode(buf, img)
+	case "png-interlaced":
+		err = encodeInterlacedPNG(buf, img)
 	case "jpeg":
 		var opt jpeg.Options
 		opt.Quality = app.imageSettings.getJpegQuality()
@@ -64,23 +194,8 @@ func (app *RenderingApp) makeScreenShot() {
 	default:
 		var opt libjpeg.EncoderOptions
 		opt.Quality = app.imageSettings.getJpegQuality()
+		opt.ProgressiveMode = encoder == "progressive"
 		err = libjpeg.Encode(buf, img, &opt)
 	}
-
-	if err != nil {
-		panic(err)
-	}
-	imageBit := buf.Bytes()
-
-	// get md5 checksum from image to check if image changed
-	// only send a new image to the client if there has been any change.
-	md := md5.Sum(imageBit)
-	if md5SumBuffer != md {
-		imgBase64Str := base64.StdEncoding.EncodeToString([]byte(imageBit))
-		if app.Debug {
-			AddToByteBuffer(len(imgBase64Str))
-		}
-		app.cImagestream <- []byte(imgBase64Str)
-		md5SumBuffer = md
-	}
+	return err
 }
@@ -0,0 +1,85 @@
+package renderer
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/draw"
+	"io"
+)
+
+// adam7 lists x, y offsets and steps of the seven passes of an interlaced png
+var adam7 = [7][4]int{
+	{0, 0, 8, 8}, {4, 0, 8, 8}, {0, 4, 4, 8}, {2, 0, 4, 4}, {0, 2, 2, 4}, {1, 0, 2, 2}, {0, 1, 1, 2},
+}
+
+// encodeInterlacedPNG writes an image as 8 bit RGBA png with Adam7 interlacing,
+// so browsers can show a coarse preview before the whole frame has been received.
+// The standard library decodes but does not write interlaced images.
+func encodeInterlacedPNG(w io.Writer, img image.Image) error {
+	b := img.Bounds()
+	nrgba, ok := img.(*image.NRGBA)
+	if !ok || b.Min != (image.Point{}) {
+		nrgba = image.NewNRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+		draw.Draw(nrgba, nrgba.Bounds(), img, b.Min, draw.Src)
+	}
+	width, height := b.Dx(), b.Dy()
+
+	var idat bytes.Buffer
+	z, err := zlib.NewWriterLevel(&idat, zlib.BestSpeed)
+	if err != nil {
+		return err
+	}
+	for _, pass := range adam7 {
+		x0, y0, dx, dy := pass[0], pass[1], pass[2], pass[3]
+		if x0 >= width || y0 >= height {
+			continue
+		}
+		row := make([]byte, 1+4*((width-x0+dx-1)/dx))
+		row[0] = 1 // sub filter, each byte minus the byte of the pixel before
+		for y := y0; y < height; y += dy {
+			i := 1
+			for x := x0; x < width; x += dx {
+				copy(row[i:i+4], nrgba.Pix[nrgba.PixOffset(x, y):])
+				i += 4
+			}
+			for i := len(row) - 1; i > 4; i-- {
+				row[i] -= row[i-4]
+			}
+			if _, err := z.Write(row); err != nil {
+				return err
+			}
+		}
+	}
+	if err := z.Close(); err != nil {
+		return err
+	}
+
+	bw := bufio.NewWriter(w)
+	bw.WriteString("\x89PNG\r\n\x1a\n")
+	header := make([]byte, 13)
+	binary.BigEndian.PutUint32(header[0:], uint32(width))
+	binary.BigEndian.PutUint32(header[4:], uint32(height))
+	header[8], header[9], header[12] = 8, 6, 1 // bit depth, color type RGBA, Adam7
+	writePNGChunk(bw, "IHDR", header)
+	writePNGChunk(bw, "IDAT", idat.Bytes())
+	writePNGChunk(bw, "IEND", nil)
+	return bw.Flush()
+}
+
+// writePNGChunk writes the length, type, data and checksum of a png chunk
+func writePNGChunk(w *bufio.Writer, name string, data []byte) {
+	var n [4]byte
+	binary.BigEndian.PutUint32(n[:], uint32(len(data)))
+	w.Write(n[:])
+	crc := crc32.NewIEEE()
+	crc.Write([]byte(name))
+	crc.Write(data)
+	w.WriteString(name)
+	w.Write(data)
+	binary.BigEndian.PutUint32(n[:], crc.Sum32())
+	w.Write(n[:])
+}
@@ -0,0 +1,45 @@
+package renderer
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+func TestEncodeInterlacedPNG(t *testing.T) {
+	// odd sizes leave some passes empty or short
+	for _, size := range []image.Point{{1, 1}, {3, 2}, {13, 9}} {
+		img := image.NewNRGBA(image.Rect(0, 0, size.X, size.Y))
+		for y := 0; y < size.Y; y++ {
+			for x := 0; x < size.X; x++ {
+				img.SetNRGBA(x, y, color.NRGBA{R: uint8(x * 19), G: uint8(y * 23), B: uint8(x * y), A: 255 - uint8(x)})
+			}
+		}
+		var buf bytes.Buffer
+		assert(t, encodeInterlacedPNG(&buf, img), nil)
+		assert(t, buf.Bytes()[28], byte(1)) // interlace method of the header
+
+		decoded, err := png.Decode(&buf)
+		assert(t, err, nil)
+		assert(t, decoded.Bounds(), img.Bounds())
+		for y := 0; y < size.Y; y++ {
+			for x := 0; x < size.X; x++ {
+				if color.NRGBAModel.Convert(decoded.At(x, y)) != img.NRGBAAt(x, y) {
+					t.Error("pixel differs at", x, y, size)
+				}
+			}
+		}
+	}
+
+	// other image types are converted
+	rgba := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	rgba.Set(1, 2, color.RGBA{R: 255, A: 255})
+	var buf bytes.Buffer
+	assert(t, encodeInterlacedPNG(&buf, rgba), nil)
+	decoded, err := png.Decode(&buf)
+	assert(t, err, nil)
+	r, _, _, _ := decoded.At(1, 2).RGBA()
+	assert(t, r, uint32(0xffff))
+}
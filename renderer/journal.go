@@ -0,0 +1,250 @@
+package renderer
+
+import (
+	"bufio"
+	"crypto/hmac"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// JournalDir is the directory of the command journals of sessions, empty disables journaling.
+// A session started with the key of an existing journal replays it on top of the loaded model,
+// so the state of a user survives a server crash or a reconnect.
+var JournalDir = ""
+
+// journalSecret signs the journal tokens of clients, so they can only reconnect to journals issued to them
+var journalSecret []byte
+
+// journalEntry is a single line of a journal, either a scene command, nodes moved by dragging or an annotation
+type journalEntry struct {
+	Command    *Command    `json:"command,omitempty"`
+	Patches    []NodePatch `json:"patches,omitempty"`
+	Annotation *Annotation `json:"annotation,omitempty"`
+}
+
+// JournalInfo is sent as journal message, clients reconnect with the token to restore their state
+type JournalInfo struct {
+	Token    string `json:"token"`
+	Replayed int    `json:"replayed"`
+}
+
+// LoadJournalSecret reads the secret signing journal tokens from the journal directory.
+// It is created on first use, so tokens stay valid when the server restarts.
+func LoadJournalSecret() error {
+	if JournalDir == "" {
+		return nil
+	}
+	path := filepath.Join(JournalDir, "secret")
+	secret, err := ioutil.ReadFile(path)
+	if err == nil && len(secret) > 0 {
+		journalSecret = secret
+		return nil
+	}
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	secret = make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(JournalDir, 0755); err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(path, secret, 0600); err != nil {
+		return err
+	}
+	journalSecret = secret
+	return nil
+}
+
+// JournalToken returns the token of a journal key, the key with its signature
+func JournalToken(key string) string {
+	return key + "." + base64.RawURLEncoding.EncodeToString(hmacSHA256(journalSecret, key))
+}
+
+// JournalKey returns the journal key of a token, false if the token was not issued by this server
+func JournalKey(token string) (string, bool) {
+	i := strings.LastIndex(token, ".")
+	if i < 0 || len(journalSecret) == 0 {
+		return "", false
+	}
+	key := token[:i]
+	return key, hmac.Equal([]byte(JournalToken(key)), []byte(token))
+}
+
+// Journal sends the journal token of the session, clear drops the journaled state so a reconnect starts from the plain model
+func (app *RenderingApp) Journal(cmd Command) {
+	key := app.loadOptions.Journal
+	if JournalDir == "" || key == "" {
+		app.sendMessageToClient("journal", "journaling is disabled")
+		return
+	}
+	switch cmd.Val {
+	case "":
+		app.sendDataToClient("journal", JournalInfo{Token: JournalToken(key)})
+	case "clear":
+		if err := app.journalLog.clear(journalPath(key)); err != nil {
+			app.log.Error("clearing journal failed: %v", err)
+		}
+		app.sendDataToClient("journal", JournalInfo{Token: JournalToken(key)})
+	default:
+		app.sendMessageToClient("journal", "invalid value "+cmd.Val)
+	}
+}
+
+// replayJournal runs the journaled commands of a previous session with the same key, before any command of the client
+func (app *RenderingApp) replayJournal() {
+	key := app.loadOptions.Journal
+	if JournalDir == "" || key == "" {
+		return
+	}
+	path := journalPath(key)
+	entries, err := readJournal(path)
+	if err != nil && !os.IsNotExist(err) {
+		app.log.Error("reading journal failed: %v", err)
+	}
+	for _, e := range entries {
+		switch {
+		case e.Command != nil:
+			app.runCommand(*e.Command)
+		case len(e.Patches) > 0:
+			app.patch(e.Patches)
+		case e.Annotation != nil:
+			app.annotations = append(app.annotations, *e.Annotation)
+		}
+	}
+	if err := endLine(path); err != nil && !os.IsNotExist(err) {
+		app.log.Error("repairing journal failed: %v", err)
+	}
+	if len(entries) > 0 {
+		app.log.Info("replayed %d journaled commands", len(entries))
+	}
+	// entries are appended only after the replay, so they are not journaled twice
+	app.journalLog.replayed = true
+	app.sendDataToClient("journal", JournalInfo{Token: JournalToken(key), Replayed: len(entries)})
+}
+
+// journalCommand appends a command changing the scene to the journal, it runs before the command
+func (app *RenderingApp) journalCommand(cmd Command) {
+	switch {
+	case cmd.Cmd == "Hide":
+		// the selection is not journaled, so the hidden nodes are
+		names := app.selectedNames()
+		if len(names) == 0 {
+			return
+		}
+		ids, _ := json.Marshal(names)
+		cmd = Command{Cmd: "Hidenodes", Val: string(ids)}
+	case cmd.Cmd == "Annotate":
+		// annotations are journaled with their camera and snapshot once the frame is captured
+		return
+	case !sceneActions[cmd.Cmd]:
+		return
+	}
+	app.journal(journalEntry{Command: &cmd})
+}
+
+// journal appends an entry once the journal has been replayed
+func (app *RenderingApp) journal(entry journalEntry) {
+	key := app.loadOptions.Journal
+	if JournalDir == "" || key == "" || !app.journalLog.replayed {
+		return
+	}
+	if err := app.journalLog.write(journalPath(key), entry); err != nil {
+		app.log.Error("writing journal failed: %v", err)
+	}
+}
+
+// journalState appends to the journal file of a session
+type journalState struct {
+	auditLog
+	replayed bool
+}
+
+// clear closes and removes the journal file, later entries start a new one
+func (j *journalState) clear(path string) error {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+	if j.file != nil {
+		j.file.Close()
+		j.file = nil
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// PruneJournals removes journals not written within the given time, it returns the number of removed journals
+func PruneJournals(ttl time.Duration) (int, error) {
+	if JournalDir == "" {
+		return 0, nil
+	}
+	paths, err := filepath.Glob(filepath.Join(JournalDir, "*.jsonl"))
+	if err != nil {
+		return 0, err
+	}
+	removed := 0
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil || time.Since(info.ModTime()) < ttl {
+			continue
+		}
+		if err := os.Remove(path); err == nil {
+			removed++
+		}
+	}
+	return removed, nil
+}
+
+// endLine terminates a line cut off by a crash, so the next entry starts on a line of its own
+func endLine(path string) error {
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil || info.Size() == 0 {
+		return err
+	}
+	last := make([]byte, 1)
+	if _, err := f.ReadAt(last, info.Size()-1); err != nil {
+		return err
+	}
+	if last[0] != '\n' {
+		_, err = f.WriteAt([]byte{'\n'}, info.Size())
+	}
+	return err
+}
+
+// readJournal reads all entries of a journal file, lines cut off by a crash are skipped
+func readJournal(path string) ([]journalEntry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var entries []journalEntry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 16<<20)
+	for scanner.Scan() {
+		var e journalEntry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			continue
+		}
+		entries = append(entries, e)
+	}
+	return entries, scanner.Err()
+}
+
+// journalPath returns the journal file of a key
+func journalPath(key string) string {
+	return filepath.Join(JournalDir, filepath.Base(strings.TrimSpace(key))+".jsonl")
+}
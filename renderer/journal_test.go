@@ -0,0 +1,147 @@
+package renderer
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/graphic"
+)
+
+func TestJournalFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "journal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	defer func(dir string) { JournalDir = dir }(JournalDir)
+	JournalDir = dir
+
+	path := journalPath("key")
+	assert(t, path, filepath.Join(dir, "key.jsonl"))
+	assert(t, journalPath("../key"), path)
+
+	var j journalState
+	j.write(path, journalEntry{Command: &Command{Cmd: "Hidenodes", Val: "a,b"}})
+	j.write(path, journalEntry{Patches: []NodePatch{{Node: "a"}}})
+	j.close()
+	// a line cut off by a crash is terminated and skipped
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(`{"command": {"Cmd": "Col`)
+	f.Close()
+	if err := endLine(path); err != nil {
+		t.Fatal(err)
+	}
+	var k journalState
+	k.write(path, journalEntry{Annotation: &Annotation{Title: "crack"}})
+	k.close()
+
+	entries, err := readJournal(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert(t, len(entries), 3)
+	assert(t, entries[0].Command.Cmd, "Hidenodes")
+	assert(t, entries[0].Command.Val, "a,b")
+	assert(t, entries[1].Patches[0].Node, "a")
+	assert(t, entries[2].Annotation.Title, "crack")
+
+	assert(t, k.clear(path), nil)
+	_, err = os.Stat(path)
+	assert(t, os.IsNotExist(err), true)
+	assert(t, k.clear(path), nil)
+}
+
+func TestJournalCommand(t *testing.T) {
+	dir, err := ioutil.TempDir("", "journal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	defer func(dir string) { JournalDir = dir }(JournalDir)
+	JournalDir = dir
+
+	app := RenderingApp{selectionBuffer: map[core.INode][]graphic.GraphicMaterial{}}
+	app.loadOptions.Journal = "key"
+	// nothing is journaled before the replay
+	app.journalCommand(Command{Cmd: "Hidenodes", Val: "a"})
+	app.journalLog.replayed = true
+	app.journalCommand(Command{Cmd: "Zoom", Val: "1"})
+	app.journalCommand(Command{Cmd: "Hide"})
+	node := core.NewNode()
+	node.SetName("root/1")
+	app.selectionBuffer[node] = nil
+	app.journalCommand(Command{Cmd: "Hide"})
+	app.journalCommand(Command{Cmd: "Annotate", Val: "crack"})
+	app.journalCommand(Command{Cmd: "Colorby", Val: "level"})
+	app.journalLog.close()
+
+	entries, err := readJournal(journalPath("key"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert(t, len(entries), 2)
+	assert(t, *entries[0].Command, Command{Cmd: "Hidenodes", Val: `["root/1"]`})
+	assert(t, *entries[1].Command, Command{Cmd: "Colorby", Val: "level"})
+}
+
+func TestJournalToken(t *testing.T) {
+	dir, err := ioutil.TempDir("", "journal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	defer func(dir string) { JournalDir = dir }(JournalDir)
+	JournalDir = dir
+	defer func(secret []byte) { journalSecret = secret }(journalSecret)
+	journalSecret = nil
+
+	_, ok := JournalKey("key")
+	assert(t, ok, false)
+	if err := LoadJournalSecret(); err != nil {
+		t.Fatal(err)
+	}
+	token := JournalToken("key")
+	key, ok := JournalKey(token)
+	assert(t, key, "key")
+	assert(t, ok, true)
+	// keys without or with a forged signature are rejected
+	_, ok = JournalKey("key")
+	assert(t, ok, false)
+	_, ok = JournalKey("other" + token[len("key"):])
+	assert(t, ok, false)
+	// the secret is kept for restarts
+	journalSecret = nil
+	if err := LoadJournalSecret(); err != nil {
+		t.Fatal(err)
+	}
+	assert(t, JournalToken("key"), token)
+}
+
+func TestPruneJournals(t *testing.T) {
+	dir, err := ioutil.TempDir("", "journal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	defer func(dir string) { JournalDir = dir }(JournalDir)
+	JournalDir = dir
+
+	old, recent := journalPath("old"), journalPath("recent")
+	ioutil.WriteFile(old, []byte("{}\n"), 0640)
+	ioutil.WriteFile(recent, []byte("{}\n"), 0640)
+	past := time.Now().Add(-48 * time.Hour)
+	os.Chtimes(old, past, past)
+
+	removed, err := PruneJournals(24 * time.Hour)
+	assert(t, err, nil)
+	assert(t, removed, 1)
+	_, err = os.Stat(recent)
+	assert(t, err, nil)
+}
@@ -0,0 +1,175 @@
+package renderer
+
+import (
+	"encoding/json"
+
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/light"
+	"github.com/g3n/engine/loader/gltf"
+	"github.com/g3n/engine/math32"
+)
+
+// khrLightsPunctual is the glTF extension defining point, spot and directional lights
+const khrLightsPunctual = "KHR_lights_punctual"
+
+// gltfLight is a light of the KHR_lights_punctual extension
+type gltfLight struct {
+	Name      string      `json:"name"`
+	Type      string      `json:"type"`
+	Color     *[3]float32 `json:"color"`
+	Intensity *float32    `json:"intensity"`
+	Spot      struct {
+		OuterConeAngle *float32 `json:"outerConeAngle"`
+	} `json:"spot"`
+}
+
+// lightState holds the lights imported from the model
+type lightState struct {
+	lights  []core.INode
+	enabled bool // imported lights replace the default light
+}
+
+// Lighting switches between the lights of the model (imported) and the default light (default), an empty value toggles.
+// The lights are switched by the render loop.
+func (app *RenderingApp) Lighting(cmd Command) {
+	if cmd.Val != "" && cmd.Val != "imported" && cmd.Val != "default" {
+		app.sendMessageToClient("lighting", "invalid lighting "+cmd.Val)
+		return
+	}
+	update := func() {
+		switch cmd.Val {
+		case "":
+			app.lighting.enabled = !app.lighting.enabled
+		case "imported":
+			app.lighting.enabled = true
+		case "default":
+			app.lighting.enabled = false
+		}
+		if app.lighting.enabled && len(app.lighting.lights) == 0 {
+			app.lighting.enabled = false
+			app.sendMessageToClient("lighting", "model has no lights")
+		}
+		app.applyLighting()
+	}
+	select {
+	case app.sceneUpdates <- update:
+	case <-app.quit:
+	}
+}
+
+// importLights replaces the imported lights by the lights of a loaded model, they are used if there are any
+func (app *RenderingApp) importLights(g *gltf.GLTF) {
+	for _, l := range app.lighting.lights {
+		app.Scene().Remove(l)
+	}
+	app.lighting.lights = nil
+	defs := parseLights(g.Extensions)
+	if len(defs) > 0 {
+		app.modelRoot.GetNode().UpdateMatrixWorld()
+	}
+	for i := range g.Nodes {
+		index, ok := nodeLight(g.Nodes[i].Extensions)
+		if !ok || index >= len(defs) {
+			continue
+		}
+		node, err := g.LoadNode(i)
+		if err != nil {
+			continue
+		}
+		// lights are placed once in world space of the scaled model, the model does not move after loading
+		if l := newLight(defs[index], node.GetNode()); l != nil {
+			app.Scene().Add(l)
+			app.lighting.lights = append(app.lighting.lights, l)
+		}
+	}
+	if len(app.lighting.lights) > 0 {
+		app.log.Info("imported %d lights", len(app.lighting.lights))
+	}
+	app.lighting.enabled = len(app.lighting.lights) > 0
+	app.applyLighting()
+}
+
+// applyLighting shows either the imported lights or the default light, the sun replaces the default light
+func (app *RenderingApp) applyLighting() {
+	for _, l := range app.lighting.lights {
+		l.GetNode().SetVisible(app.lighting.enabled)
+	}
+	if app.defaultLight != nil {
+		app.defaultLight.SetVisible(!app.lighting.enabled && app.sun.light == nil)
+	}
+}
+
+// newLight creates a light at the world transform of its node, lights shine along the -z axis of their node
+func newLight(def gltfLight, node *core.Node) core.INode {
+	color := math32.Color{R: 1, G: 1, B: 1}
+	if def.Color != nil {
+		color = math32.Color{R: def.Color[0], G: def.Color[1], B: def.Color[2]}
+	}
+	intensity := float32(1)
+	if def.Intensity != nil {
+		intensity = *def.Intensity
+	}
+	var position math32.Vector3
+	var q math32.Quaternion
+	node.WorldPosition(&position)
+	node.WorldQuaternion(&q)
+	direction := *math32.NewVector3(0, 0, -1).ApplyQuaternion(&q)
+
+	switch def.Type {
+	case "directional":
+		l := light.NewDirectional(&color, intensity)
+		// directional lights shine from their position towards the origin
+		l.SetPositionVec(direction.Negate())
+		return l
+	case "point":
+		l := light.NewPoint(&color, intensity)
+		l.SetPositionVec(&position)
+		l.SetLinearDecay(0)
+		l.SetQuadraticDecay(1)
+		return l
+	case "spot":
+		l := light.NewSpot(&color, intensity)
+		l.SetPositionVec(&position)
+		l.SetDirectionVec(&direction)
+		l.SetLinearDecay(0)
+		l.SetQuadraticDecay(1)
+		if def.Spot.OuterConeAngle != nil {
+			l.SetCutoffAngle(math32.RadToDeg(*def.Spot.OuterConeAngle))
+		}
+		return l
+	}
+	return nil
+}
+
+// parseLights returns the lights defined by the extension of a glTF document
+func parseLights(extensions map[string]interface{}) []gltfLight {
+	var ext struct {
+		Lights []gltfLight `json:"lights"`
+	}
+	decodeExtension(extensions, &ext)
+	return ext.Lights
+}
+
+// nodeLight returns the index of the light of a node
+func nodeLight(extensions map[string]interface{}) (int, bool) {
+	var ext struct {
+		Light *int `json:"light"`
+	}
+	if !decodeExtension(extensions, &ext) || ext.Light == nil || *ext.Light < 0 {
+		return 0, false
+	}
+	return *ext.Light, true
+}
+
+// decodeExtension decodes the KHR_lights_punctual object of extensions into v
+func decodeExtension(extensions map[string]interface{}, v interface{}) bool {
+	data, ok := extensions[khrLightsPunctual]
+	if !ok {
+		return false
+	}
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return false
+	}
+	return json.Unmarshal(raw, v) == nil
+}
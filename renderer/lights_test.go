@@ -0,0 +1,80 @@
+package renderer
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/light"
+	"github.com/g3n/engine/math32"
+)
+
+func TestParseLights(t *testing.T) {
+	var doc struct {
+		Extensions map[string]interface{} `json:"extensions"`
+		Nodes      []struct {
+			Extensions map[string]interface{} `json:"extensions"`
+		} `json:"nodes"`
+	}
+	err := json.Unmarshal([]byte(`{
+		"extensions": {"KHR_lights_punctual": {"lights": [
+			{"type": "spot", "color": [1, 0.5, 0], "intensity": 20, "spot": {"outerConeAngle": 0.5}},
+			{"type": "directional"}
+		]}},
+		"nodes": [{"extensions": {"KHR_lights_punctual": {"light": 1}}}, {}, {"extensions": {"KHR_lights_punctual": {}}}]
+	}`), &doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lights := parseLights(doc.Extensions)
+	assert(t, len(lights), 2)
+	assert(t, lights[0].Type, "spot")
+	assert(t, *lights[0].Intensity, float32(20))
+	assert(t, lights[0].Color[1], float32(0.5))
+	assert(t, *lights[0].Spot.OuterConeAngle, float32(0.5))
+	assert(t, lights[1].Intensity == nil, true)
+
+	index, ok := nodeLight(doc.Nodes[0].Extensions)
+	assert(t, ok, true)
+	assert(t, index, 1)
+	_, ok = nodeLight(doc.Nodes[1].Extensions)
+	assert(t, ok, false)
+	_, ok = nodeLight(doc.Nodes[2].Extensions)
+	assert(t, ok, false)
+	assert(t, len(parseLights(nil)), 0)
+}
+
+func TestNewLight(t *testing.T) {
+	node := core.NewNode()
+	node.SetPosition(1, 2, 3)
+	// turned to shine along -x
+	node.SetRotationY(math32.Pi / 2)
+
+	l := newLight(gltfLight{Type: "point"}, node)
+	p := l.GetNode().Position()
+	assert(t, p, math32.Vector3{X: 1, Y: 2, Z: 3})
+	assert(t, l.(*light.Point).Intensity(), float32(1))
+
+	// directional lights are placed in the direction the light comes from
+	l = newLight(gltfLight{Type: "directional"}, node)
+	p = l.GetNode().Position()
+	expected := math32.Vector3{X: 1}
+	if p.DistanceTo(&expected) > 1e-4 {
+		t.Error("directional light not facing its node direction", p)
+	}
+
+	angle := float32(math32.Pi / 4)
+	l = newLight(gltfLight{Type: "spot", Spot: struct {
+		OuterConeAngle *float32 `json:"outerConeAngle"`
+	}{&angle}}, node)
+	d := l.GetNode().Direction()
+	expected = math32.Vector3{X: -1}
+	if d.DistanceTo(&expected) > 1e-4 {
+		t.Error("spot light not facing its node direction", d)
+	}
+	if math32.Abs(l.(*light.Spot).CutoffAngle()-45) > 1e-3 {
+		t.Error("spot cone not converted to degrees", l.(*light.Spot).CutoffAngle())
+	}
+
+	assert(t, newLight(gltfLight{Type: "area"}, node), nil)
+}
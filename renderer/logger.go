@@ -0,0 +1,108 @@
+package renderer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/g3n/engine/util/logger"
+)
+
+// LogJSON switches session logs to one JSON object per line
+var LogJSON = false
+
+// LogLevel is the minimum level of emitted session log entries
+var LogLevel = logger.DEBUG
+
+// list of level names, indexed by g3n logger levels
+var levelNames = [...]string{"debug", "info", "warn", "error", "fatal"}
+
+// serializes writes of all session loggers
+var logMutex sync.Mutex
+
+// ParseLogLevel returns the g3n logger level for a level name
+func ParseLogLevel(name string) (int, error) {
+	for level, n := range levelNames {
+		if strings.EqualFold(name, n) {
+			return level, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid log level: %s", name)
+}
+
+// Logger writes leveled log entries tagged with session id and client address
+type Logger struct {
+	session string
+	client  string
+	out     io.Writer
+}
+
+// logEntry is a single log line in JSON output
+type logEntry struct {
+	Time    string `json:"time"`
+	Level   string `json:"level"`
+	Session string `json:"session"`
+	Client  string `json:"client"`
+	Msg     string `json:"msg"`
+}
+
+// NewLogger creates a logger for a session writing to stdout
+func NewLogger(session string, client string) *Logger {
+	return &Logger{session: session, client: client, out: os.Stdout}
+}
+
+// Session returns the session id of the logger
+func (l *Logger) Session() string {
+	return l.session
+}
+
+// Debug emits a debug entry
+func (l *Logger) Debug(format string, v ...interface{}) {
+	l.log(logger.DEBUG, format, v...)
+}
+
+// Info emits an info entry
+func (l *Logger) Info(format string, v ...interface{}) {
+	l.log(logger.INFO, format, v...)
+}
+
+// Warn emits a warning entry
+func (l *Logger) Warn(format string, v ...interface{}) {
+	l.log(logger.WARN, format, v...)
+}
+
+// Error emits an error entry
+func (l *Logger) Error(format string, v ...interface{}) {
+	l.log(logger.ERROR, format, v...)
+}
+
+// log formats an entry and writes it if its level is enabled
+func (l *Logger) log(level int, format string, v ...interface{}) {
+	if level < logLevel() {
+		return
+	}
+	entry := logEntry{
+		Time:    time.Now().UTC().Format(time.RFC3339Nano),
+		Level:   levelNames[level],
+		Session: l.session,
+		Client:  l.client,
+		Msg:     fmt.Sprintf(format, v...),
+	}
+
+	var line []byte
+	if LogJSON {
+		line, _ = json.Marshal(entry)
+		line = append(line, '\n')
+	} else {
+		line = []byte(fmt.Sprintf("%s %-5s session=%s client=%s %s\n",
+			entry.Time, strings.ToUpper(entry.Level), entry.Session, entry.Client, entry.Msg))
+	}
+
+	logMutex.Lock()
+	defer logMutex.Unlock()
+	l.out.Write(line)
+}
@@ -0,0 +1,43 @@
+package renderer
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/g3n/engine/util/logger"
+)
+
+func TestParseLogLevel(t *testing.T) {
+	level, err := ParseLogLevel("WARN")
+	assert(t, err, nil)
+	assert(t, level, logger.WARN)
+	_, err = ParseLogLevel("verbose")
+	if err == nil {
+		t.Error("invalid level accepted")
+	}
+}
+
+func TestLoggerJSON(t *testing.T) {
+	buf := new(bytes.Buffer)
+	l := &Logger{session: "abc", client: "127.0.0.1", out: buf}
+	LogJSON = true
+	LogLevel = logger.INFO
+	defer func() {
+		LogJSON = false
+		LogLevel = logger.DEBUG
+	}()
+
+	l.Debug("hidden")
+	assert(t, buf.Len(), 0)
+
+	l.Info("hello %d", 1)
+	entry := logEntry{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatal(err)
+	}
+	assert(t, entry.Level, "info")
+	assert(t, entry.Session, "abc")
+	assert(t, entry.Client, "127.0.0.1")
+	assert(t, entry.Msg, "hello 1")
+}
@@ -0,0 +1,230 @@
+package renderer
+
+import (
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/graphic"
+	"github.com/g3n/engine/loader/gltf"
+	"github.com/g3n/engine/material"
+)
+
+// MaterialInfo describes a material for the material inspector of the client.
+// Properties unknown for the type of material are omitted.
+type MaterialInfo struct {
+	Name        string            `json:"name,omitempty"`
+	Type        string            `json:"type"`            // physical, standard or other
+	Color       *[4]float32       `json:"color,omitempty"` // base or diffuse color with alpha
+	Emissive    *[3]float32       `json:"emissive,omitempty"`
+	Specular    *[3]float32       `json:"specular,omitempty"`
+	Shininess   *float32          `json:"shininess,omitempty"`
+	Metallic    *float32          `json:"metallic,omitempty"`
+	Roughness   *float32          `json:"roughness,omitempty"`
+	Opacity     *float32          `json:"opacity,omitempty"`
+	AlphaMode   string            `json:"alphaMode,omitempty"` // OPAQUE, MASK or BLEND
+	Transparent bool              `json:"transparent"`
+	DoubleSided bool              `json:"doubleSided"`
+	Textures    map[string]string `json:"textures,omitempty"` // image name or uri by slot, e.g. baseColor or normal
+}
+
+// NodeMaterials is sent as material message with the materials of a node and its children
+type NodeMaterials struct {
+	ID        string         `json:"id"`
+	Materials []MaterialInfo `json:"materials"`
+}
+
+// Material sends the materials of a node by id and its children, as they are defined by the model.
+// Colors of selections, color coding or clash highlighting are not reported.
+func (app *RenderingApp) Material(cmd Command) {
+	node, ok := app.nodeBuffer[cmd.Val]
+	if !ok {
+		app.sendMessageToClient("material", "unknown node "+cmd.Val)
+		return
+	}
+	result := NodeMaterials{ID: cmd.Val, Materials: []MaterialInfo{}}
+	seen := make(map[material.IMaterial]bool)
+	// bound values and clashes are colored on the render thread
+	ok = app.renderSync(func() {
+		forEachGraphic([]core.INode{node}, func(inode core.INode) {
+			for _, gm := range app.originalMaterials(inode) {
+				imat := gm.IMaterial()
+				if seen[imat] {
+					continue
+				}
+				seen[imat] = true
+				info, found := app.materialInfos[imat]
+				if !found {
+					info = engineMaterialInfo(imat)
+				}
+				result.Materials = append(result.Materials, info)
+			}
+		})
+	})
+	if ok {
+		app.sendDataToClient("material", result)
+	}
+}
+
+// originalMaterials returns the materials of a graphic node before they got replaced
+// by a selection, color coding or clash highlighting
+func (app *RenderingApp) originalMaterials(inode core.INode) []graphic.GraphicMaterial {
+	gnode, ok := inode.(graphic.IGraphic)
+	if !ok {
+		return nil
+	}
+	for _, originals := range []map[core.INode][]graphic.GraphicMaterial{app.selectionBuffer, app.colorBy.originals, app.binding.originals, app.clashes.originals} {
+		if m, ok := originals[inode]; ok {
+			return m
+		}
+	}
+	return gnode.GetGraphic().Materials()
+}
+
+// getMaterialInfos describes all gltf materials by their loaded material,
+// textures deferred for streaming are listed as well
+func getMaterialInfos(g *gltf.GLTF, deferred []deferredTexture) map[material.IMaterial]MaterialInfo {
+	infos := make(map[material.IMaterial]MaterialInfo)
+	imats := make([]material.IMaterial, len(g.Materials))
+	for i := range g.Materials {
+		imat, err := g.LoadMaterial(i)
+		if err != nil || imat == nil {
+			continue
+		}
+		imats[i] = imat
+		infos[imat] = gltfMaterialInfo(g, &g.Materials[i])
+	}
+	for _, d := range deferred {
+		if d.material < len(imats) && imats[d.material] != nil {
+			infos[imats[d.material]].Textures[d.slot] = textureName(g, d.texture)
+		}
+	}
+	return infos
+}
+
+// gltfMaterialInfo describes a gltf material, pbr by default or by the KHR_materials_common extension
+func gltfMaterialInfo(g *gltf.GLTF, m *gltf.Material) MaterialInfo {
+	info := MaterialInfo{Name: m.Name, Type: "other", DoubleSided: m.DoubleSided, Textures: map[string]string{}}
+	if ext, ok := m.Extensions[gltf.KhrMaterialsCommon].(map[string]interface{}); ok {
+		commonMaterialInfo(g, ext, &info)
+		return info
+	}
+	if len(m.Extensions) > 0 {
+		return info
+	}
+	info.Type, info.AlphaMode = "physical", "OPAQUE"
+	if m.AlphaMode != "" {
+		info.AlphaMode = m.AlphaMode
+	}
+	info.Transparent = info.AlphaMode == "BLEND"
+	color, metallic, roughness := [4]float32{1, 1, 1, 1}, float32(1), float32(1)
+	if pbr := m.PbrMetallicRoughness; pbr != nil {
+		if pbr.BaseColorFactor != nil {
+			color = *pbr.BaseColorFactor
+		}
+		if pbr.MetallicFactor != nil {
+			metallic = *pbr.MetallicFactor
+		}
+		if pbr.RoughnessFactor != nil {
+			roughness = *pbr.RoughnessFactor
+		}
+		if pbr.BaseColorTexture != nil {
+			info.Textures["baseColor"] = textureName(g, pbr.BaseColorTexture.Index)
+		}
+		if pbr.MetallicRoughnessTexture != nil {
+			info.Textures["metallicRoughness"] = textureName(g, pbr.MetallicRoughnessTexture.Index)
+		}
+	}
+	emissive := [3]float32{}
+	if m.EmissiveFactor != nil {
+		emissive = *m.EmissiveFactor
+	}
+	if m.NormalTexture != nil {
+		info.Textures["normal"] = textureName(g, m.NormalTexture.Index)
+	}
+	if m.OcclusionTexture != nil {
+		info.Textures["occlusion"] = textureName(g, m.OcclusionTexture.Index)
+	}
+	if m.EmissiveTexture != nil {
+		info.Textures["emissive"] = textureName(g, m.EmissiveTexture.Index)
+	}
+	info.Color, info.Emissive, info.Metallic, info.Roughness, info.Opacity = &color, &emissive, &metallic, &roughness, &color[3]
+	return info
+}
+
+// commonMaterialInfo reads the values of a KHR_materials_common material, the diffuse value is either a color or a texture index
+func commonMaterialInfo(g *gltf.GLTF, ext map[string]interface{}, info *MaterialInfo) {
+	info.Type = "standard"
+	info.DoubleSided, _ = ext["doubleSided"].(bool)
+	info.Transparent, _ = ext["transparent"].(bool)
+	values, _ := ext["values"].(map[string]interface{})
+	if v := commonValue(values, "diffuse"); len(v) == 1 {
+		info.Textures["diffuse"] = textureName(g, int(v[0]))
+	} else if len(v) >= 3 {
+		info.Color = &[4]float32{v[0], v[1], v[2], 1}
+	}
+	if v := commonValue(values, "emission"); len(v) >= 3 {
+		info.Emissive = &[3]float32{v[0], v[1], v[2]}
+	}
+	if v := commonValue(values, "specular"); len(v) >= 3 {
+		info.Specular = &[3]float32{v[0], v[1], v[2]}
+	}
+	if v := commonValue(values, "shininess"); len(v) == 1 {
+		info.Shininess = &v[0]
+	}
+	if v := commonValue(values, "transparency"); len(v) == 1 {
+		info.Opacity = &v[0]
+	}
+}
+
+// commonValue returns a value of a KHR_materials_common material as numbers, nil if missing or invalid
+func commonValue(values map[string]interface{}, key string) []float32 {
+	list, _ := values[key].([]interface{})
+	var v []float32
+	for _, item := range list {
+		f, ok := item.(float64)
+		if !ok {
+			return nil
+		}
+		v = append(v, float32(f))
+	}
+	return v
+}
+
+// engineMaterialInfo describes a material not loaded from the model, only colors of standard materials are known
+func engineMaterialInfo(imat material.IMaterial) MaterialInfo {
+	info := MaterialInfo{Type: "other"}
+	if std, ok := imat.(*material.Standard); ok {
+		c, e := std.AmbientColor(), std.EmissiveColor()
+		info.Type, info.Color, info.Emissive = "standard", &[4]float32{c.R, c.G, c.B, 1}, &[3]float32{e.R, e.G, e.B}
+	} else if _, ok := imat.(*material.Physical); ok {
+		info.Type = "physical"
+	}
+	if mat := imat.GetMaterial(); mat != nil {
+		info.DoubleSided = mat.Side() == material.SideDouble
+		info.Transparent = mat.Transparent()
+	}
+	return info
+}
+
+// textureName returns the name of the image of a gltf texture, its file name or its index
+func textureName(g *gltf.GLTF, idx int) string {
+	if idx < 0 || idx >= len(g.Textures) {
+		return ""
+	}
+	tex := g.Textures[idx]
+	if tex.Source >= 0 && tex.Source < len(g.Images) {
+		img := g.Images[tex.Source]
+		if img.Name != "" {
+			return img.Name
+		}
+		if img.Uri != "" && img.BufferView == nil && !strings.HasPrefix(img.Uri, "data:") {
+			return path.Base(img.Uri)
+		}
+	}
+	if tex.Name != "" {
+		return tex.Name
+	}
+	return "texture " + strconv.Itoa(idx)
+}
@@ -0,0 +1,98 @@
+package renderer
+
+import (
+	"testing"
+
+	"github.com/g3n/engine/loader/gltf"
+	"github.com/g3n/engine/material"
+	"github.com/g3n/engine/math32"
+)
+
+func materialTestModel() *gltf.GLTF {
+	bufferView := 0
+	roughness := float32(0.5)
+	return &gltf.GLTF{
+		Images:   []gltf.Image{{Name: "Brick"}, {Uri: "textures/normal.png"}, {Uri: "data:image/png;base64,AAAA"}, {BufferView: &bufferView}},
+		Textures: []gltf.Texture{{Source: 0}, {Source: 1}, {Source: 2, Name: "inline"}, {Source: 3}},
+		Materials: []gltf.Material{{
+			Name:                 "Wall",
+			PbrMetallicRoughness: &gltf.PbrMetallicRoughness{BaseColorFactor: &[4]float32{1, 0, 0, 0.5}, RoughnessFactor: &roughness},
+			AlphaMode:            "BLEND",
+		}},
+	}
+}
+
+func TestTextureName(t *testing.T) {
+	g := materialTestModel()
+	assert(t, textureName(g, 0), "Brick")
+	assert(t, textureName(g, 1), "normal.png")
+	assert(t, textureName(g, 2), "inline")
+	assert(t, textureName(g, 3), "texture 3")
+	assert(t, textureName(g, 4), "")
+}
+
+func TestGltfMaterialInfo(t *testing.T) {
+	g := materialTestModel()
+	m := g.Materials[0]
+	m.NormalTexture = &gltf.NormalTextureInfo{Index: 1}
+	info := gltfMaterialInfo(g, &m)
+	assert(t, info.Name, "Wall")
+	assert(t, info.Type, "physical")
+	assert(t, *info.Color, [4]float32{1, 0, 0, 0.5})
+	assert(t, *info.Metallic, float32(1))
+	assert(t, *info.Roughness, float32(0.5))
+	assert(t, *info.Opacity, float32(0.5))
+	assert(t, *info.Emissive, [3]float32{})
+	assert(t, info.AlphaMode, "BLEND")
+	assert(t, info.Transparent, true)
+	assert(t, len(info.Textures), 1)
+	assert(t, info.Textures["normal"], "normal.png")
+
+	info = gltfMaterialInfo(g, &gltf.Material{})
+	assert(t, *info.Color, [4]float32{1, 1, 1, 1})
+	assert(t, info.AlphaMode, "OPAQUE")
+	assert(t, info.Transparent, false)
+}
+
+func TestCommonMaterialInfo(t *testing.T) {
+	g := materialTestModel()
+	m := gltf.Material{Extensions: map[string]interface{}{gltf.KhrMaterialsCommon: map[string]interface{}{
+		"doubleSided": true,
+		"values": map[string]interface{}{
+			"diffuse":   []interface{}{0.0},
+			"specular":  []interface{}{0.2, 0.2, 0.2, 1.0},
+			"shininess": []interface{}{50.0},
+		},
+	}}}
+	info := gltfMaterialInfo(g, &m)
+	assert(t, info.Type, "standard")
+	assert(t, info.DoubleSided, true)
+	assert(t, info.Textures["diffuse"], "Brick")
+	assert(t, info.Color == nil, true)
+	assert(t, *info.Specular, [3]float32{0.2, 0.2, 0.2})
+	assert(t, *info.Shininess, float32(50))
+	assert(t, info.Opacity == nil, true)
+
+	info = gltfMaterialInfo(g, &gltf.Material{Extensions: map[string]interface{}{"KHR_materials_unlit": map[string]interface{}{}}})
+	assert(t, info.Type, "other")
+	assert(t, info.Color == nil, true)
+}
+
+func TestGetMaterialInfos(t *testing.T) {
+	g := materialTestModel()
+	infos := getMaterialInfos(g, []deferredTexture{{material: 0, slot: "baseColor", texture: 0}})
+	assert(t, len(infos), 1)
+	imat, _ := g.LoadMaterial(0)
+	assert(t, infos[imat].Name, "Wall")
+	assert(t, infos[imat].Textures["baseColor"], "Brick")
+}
+
+func TestEngineMaterialInfo(t *testing.T) {
+	std := material.NewStandard(&math32.Color{R: 1, G: 0.5})
+	std.SetSide(material.SideDouble)
+	info := engineMaterialInfo(std)
+	assert(t, info.Type, "standard")
+	assert(t, *info.Color, [4]float32{1, 0.5, 0, 1})
+	assert(t, info.DoubleSided, true)
+	assert(t, engineMaterialInfo(material.NewPhysical()).Type, "physical")
+}
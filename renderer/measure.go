@@ -0,0 +1,101 @@
+package renderer
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/g3n/engine/math32"
+)
+
+// Measurement between two picked points
+type Measurement struct {
+	From     *Hit    `json:"from"`
+	To       *Hit    `json:"to"`
+	Distance float32 `json:"distance"` // in model units
+	Unit     string  `json:"unit"`
+	Text     string  `json:"text"`
+}
+
+// parseScreenPoints parses x1:y1:x2:y2 screen coordinates
+func parseScreenPoints(value string) ([]float32, bool) {
+	s := strings.Split(value, ":")
+	if len(s) != 4 {
+		return nil, false
+	}
+	points := make([]float32, 4)
+	for k, v := range s {
+		f, err := strconv.ParseFloat(v, 32)
+		if err != nil {
+			return nil, false
+		}
+		points[k] = float32(f)
+	}
+	return points, true
+}
+
+// Measure picks two points given as x1:y1:x2:y2 screen coordinates
+// and sends the distance between them, picks are snapped if enabled
+func (app *RenderingApp) Measure(cmd Command) {
+	p, ok := parseScreenPoints(cmd.Val)
+	if !ok {
+		return
+	}
+	from := app.pick(p[0], p[1])
+	to := app.pick(p[2], p[3])
+	if from == nil || to == nil {
+		app.sendDataToClient("measure", nil)
+		return
+	}
+	a := math32.Vector3{X: from.Point[0], Y: from.Point[1], Z: from.Point[2]}
+	b := math32.Vector3{X: to.Point[0], Y: to.Point[1], Z: to.Point[2]}
+	d := toModelUnits(a.DistanceTo(&b))
+	app.sendDataToClient("measure", &Measurement{From: from, To: to, Distance: d, Unit: app.modelConfig.Unit, Text: formatLength(d, app.modelConfig.Unit)})
+}
+
+// NodeMeasurement is the distance between the bounding box centers of two nodes
+type NodeMeasurement struct {
+	From     string        `json:"from"`
+	To       string        `json:"to"`
+	Centers  [2][3]float32 `json:"centers"`  // in scene coordinates like picked points
+	Delta    [3]float32    `json:"delta"`    // per axis in model units
+	Distance float32       `json:"distance"` // in model units
+	Unit     string        `json:"unit"`
+	Text     string        `json:"text"`
+}
+
+// Measurenodes sends the distance between the bounding box centers of two nodes given by id as id1,id2,
+// e.g. for dimension checks of scripts without picking
+func (app *RenderingApp) Measurenodes(cmd Command) {
+	ids, err := parseNodeIds(cmd.Val)
+	if err == nil && len(ids) != 2 {
+		err = fmt.Errorf("expected two node ids, got %s", cmd.Val)
+	}
+	if err != nil {
+		app.sendMessageToClient("measurenodes", err.Error())
+		return
+	}
+	var boxes [2]math32.Box3
+	for i, id := range ids {
+		inode, ok := app.nodeBuffer[id]
+		if !ok {
+			app.sendMessageToClient("measurenodes", "unknown node "+id)
+			return
+		}
+		boxes[i] = inode.BoundingBox()
+	}
+	m := measureCenters(boxes[0], boxes[1])
+	m.From, m.To, m.Unit, m.Text = ids[0], ids[1], app.modelConfig.Unit, formatLength(m.Distance, app.modelConfig.Unit)
+	app.sendDataToClient("measurenodes", m)
+}
+
+// measureCenters returns the distance between the centers of two bounding boxes
+func measureCenters(a, b math32.Box3) NodeMeasurement {
+	ca, cb := a.Center(nil), b.Center(nil)
+	delta := *cb.Clone().Sub(ca)
+	return NodeMeasurement{
+		Centers:  [2][3]float32{toArray(*ca), toArray(*cb)},
+		Delta:    [3]float32{toModelUnits(delta.X), toModelUnits(delta.Y), toModelUnits(delta.Z)},
+		Distance: toModelUnits(delta.Length()),
+	}
+}
@@ -0,0 +1,16 @@
+package renderer
+
+import (
+	"testing"
+
+	"github.com/g3n/engine/math32"
+)
+
+func TestMeasureCenters(t *testing.T) {
+	a := math32.Box3{Min: math32.Vector3{X: 0, Y: 0, Z: 0}, Max: math32.Vector3{X: 2, Y: 2, Z: 2}}
+	b := math32.Box3{Min: math32.Vector3{X: 3, Y: 4, Z: 0}, Max: math32.Vector3{X: 5, Y: 6, Z: 2}}
+	m := measureCenters(a, b)
+	assert(t, m.Centers, [2][3]float32{{1, 1, 1}, {4, 5, 1}})
+	assert(t, m.Delta, [3]float32{3, 4, 0})
+	assert(t, m.Distance, float32(5))
+}
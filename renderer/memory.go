@@ -0,0 +1,267 @@
+package renderer
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/geometry"
+	"github.com/g3n/engine/graphic"
+	"github.com/g3n/engine/math32"
+)
+
+// MemoryBudget is the maximum memory of the model geometry and textures of a session in bytes, 0 disables the budget.
+// Vertex data of meshes out of view for the longest time is swapped to disk and reloaded once they come into view.
+var MemoryBudget int64
+
+// residentGeometry is a geometry of the model tracked by the memory budget
+type residentGeometry struct {
+	size        int64
+	box         math32.Box3 // local bounding box, kept while the vertex data is swapped out
+	lastVisible uint64      // frame the geometry was last in view
+	evicted     bool
+	swapped     *swapSlot // vertex data on disk, kept after reloading as geometries don't change in place
+}
+
+// swapSlot locates the vertex buffers and indices of a geometry in the swap file
+type swapSlot struct {
+	offset  int64
+	buffers []int // number of floats per vertex buffer
+	indices int
+}
+
+// memoryState swaps geometries of a session to stay within the memory budget
+type memoryState struct {
+	geometries map[*geometry.Geometry]*residentGeometry
+	used       int64 // bytes of resident geometries
+	evicted    int
+	swap       *os.File
+	swapSize   int64
+	warned     bool
+}
+
+// manageMemory reloads geometries coming into view and evicts those out of view the longest
+// while the memory budget is exceeded. It runs before culling hides any nodes.
+func (app *RenderingApp) manageMemory(evname string, ev interface{}) {
+	m := &app.memory
+	if MemoryBudget <= 0 || app.modelRoot == nil {
+		return
+	}
+	if m.geometries == nil {
+		m.geometries = make(map[*geometry.Geometry]*residentGeometry)
+	}
+	frame := app.FrameCount()
+	var frustum *math32.Frustum
+	// all viewports of stereo and quad views would have to be tested, so only hidden nodes get evicted
+	if app.singleView() {
+		frustum = app.cameraFrustum()
+	}
+	seen := make(map[*geometry.Geometry]bool)
+	reloaded := false
+	var walk func(inode core.INode, visible bool)
+	walk = func(inode core.INode, visible bool) {
+		node := inode.GetNode()
+		visible = visible && node.Visible()
+		if gnode, ok := inode.(graphic.IGraphic); ok && gnode.Renderable() {
+			geom := gnode.GetGeometry()
+			r, found := m.geometries[geom]
+			if !found {
+				r = &residentGeometry{size: geometrySize(geom), box: geom.BoundingBox()}
+				m.geometries[geom] = r
+				m.used += r.size
+			}
+			seen[geom] = true
+			if visible && r.lastVisible != frame && inView(r.box, node.MatrixWorld(), frustum) {
+				r.lastVisible = frame
+				if r.evicted {
+					if err := m.reload(geom, r); err != nil {
+						app.log.Error("reloading geometry failed: %v", err)
+					}
+					reloaded = true
+				}
+			}
+		}
+		for _, child := range node.Children() {
+			walk(child, visible)
+		}
+	}
+	walk(app.modelRoot, true)
+	for geom, r := range m.geometries {
+		if !seen[geom] {
+			m.drop(geom, r)
+		}
+	}
+	if over := m.used + int64(app.textureMemory) - MemoryBudget; over > 0 {
+		left, err := m.evictUnseen(over, frame)
+		if err != nil {
+			app.log.Error("swapping geometry failed: %v", err)
+		} else if left > 0 && !m.warned {
+			app.log.Warn("memory budget exceeded by %d bytes of meshes in view", left)
+			app.ShowHud("memory", "Memory budget exceeded, zoom in to reduce the meshes in view", true, hudDuration)
+			m.warned = true
+		}
+	}
+	if reloaded {
+		app.buildCullingBoxes(app.modelRoot)
+	}
+}
+
+// evictUnseen swaps out geometries not in view, least recently seen first, until the given bytes are freed.
+// It returns the bytes which could not be freed.
+func (m *memoryState) evictUnseen(bytes int64, frame uint64) (int64, error) {
+	geoms := make([]*geometry.Geometry, 0, len(m.geometries))
+	for geom, r := range m.geometries {
+		if !r.evicted && r.lastVisible != frame && r.size > 0 {
+			geoms = append(geoms, geom)
+		}
+	}
+	sort.Slice(geoms, func(i, j int) bool { return m.geometries[geoms[i]].lastVisible < m.geometries[geoms[j]].lastVisible })
+	for _, geom := range geoms {
+		if bytes <= 0 {
+			break
+		}
+		r := m.geometries[geom]
+		if err := m.evict(geom, r); err != nil {
+			return bytes, err
+		}
+		bytes -= r.size
+	}
+	if bytes < 0 {
+		bytes = 0
+	}
+	return bytes, nil
+}
+
+// evict writes the vertex data of a geometry to the swap file once, then frees it in memory and on the GPU
+func (m *memoryState) evict(geom *geometry.Geometry, r *residentGeometry) error {
+	if r.swapped == nil {
+		slot, err := m.write(geom)
+		if err != nil {
+			return err
+		}
+		r.swapped = slot
+	}
+	// the geometry caches its bounding box until the indices change, so the box is computed before the
+	// vertex buffers are emptied and keeps answering bounding queries like zoom to extent or clash views
+	geom.SetIndices(math32.NewArrayU32(0, 0))
+	geom.BoundingBox()
+	for _, vbo := range geom.VBOs() {
+		vbo.Dispose()
+		vbo.SetBuffer(math32.NewArrayF32(0, 0))
+	}
+	r.evicted = true
+	m.used -= r.size
+	m.evicted++
+	return nil
+}
+
+// reload reads the vertex data of a geometry back from the swap file
+func (m *memoryState) reload(geom *geometry.Geometry, r *residentGeometry) error {
+	reader := io.NewSectionReader(m.swap, r.swapped.offset, m.swapSize-r.swapped.offset)
+	for i, vbo := range geom.VBOs() {
+		buffer := make([]float32, r.swapped.buffers[i])
+		if err := binary.Read(reader, binary.LittleEndian, buffer); err != nil {
+			return err
+		}
+		vbo.SetBuffer(math32.ArrayF32(buffer))
+	}
+	indices := make([]uint32, r.swapped.indices)
+	if err := binary.Read(reader, binary.LittleEndian, indices); err != nil {
+		return err
+	}
+	geom.SetIndices(math32.ArrayU32(indices))
+	r.evicted = false
+	m.used += r.size
+	m.evicted--
+	return nil
+}
+
+// loadGeometries reloads the swapped out geometries within nodes for operations reading their vertex data.
+// It has to run on the render thread, geometries out of view are evicted again by the next frames.
+func (app *RenderingApp) loadGeometries(nodes []core.INode) {
+	m := &app.memory
+	forEachGraphic(nodes, func(inode core.INode) {
+		geom := inode.(graphic.IGraphic).GetGeometry()
+		if r, ok := m.geometries[geom]; ok && r.evicted {
+			if err := m.reload(geom, r); err != nil {
+				app.log.Error("reloading geometry failed: %v", err)
+			}
+		}
+	})
+}
+
+// write appends the vertex buffers and indices of a geometry to the swap file
+func (m *memoryState) write(geom *geometry.Geometry) (*swapSlot, error) {
+	if m.swap == nil {
+		f, err := ioutil.TempFile("", "webg3n-swap-")
+		if err != nil {
+			return nil, err
+		}
+		m.swap, m.swapSize = f, 0
+	}
+	if _, err := m.swap.Seek(m.swapSize, io.SeekStart); err != nil {
+		return nil, err
+	}
+	slot := &swapSlot{offset: m.swapSize}
+	w := bufio.NewWriter(m.swap)
+	floats := 0
+	for _, vbo := range geom.VBOs() {
+		buffer := []float32(*vbo.Buffer())
+		if err := binary.Write(w, binary.LittleEndian, buffer); err != nil {
+			return nil, err
+		}
+		slot.buffers = append(slot.buffers, len(buffer))
+		floats += len(buffer)
+	}
+	indices := []uint32(geom.Indices())
+	if err := binary.Write(w, binary.LittleEndian, indices); err != nil {
+		return nil, err
+	}
+	if err := w.Flush(); err != nil {
+		return nil, err
+	}
+	slot.indices = len(indices)
+	m.swapSize += 4 * int64(floats+len(indices))
+	return slot, nil
+}
+
+// drop forgets a geometry which is no longer part of the model
+func (m *memoryState) drop(geom *geometry.Geometry, r *residentGeometry) {
+	if r.evicted {
+		m.evicted--
+	} else {
+		m.used -= r.size
+	}
+	delete(m.geometries, geom)
+}
+
+// close removes the swap file and forgets all geometries
+func (m *memoryState) close() {
+	if m.swap != nil {
+		m.swap.Close()
+		os.Remove(m.swap.Name())
+	}
+	*m = memoryState{}
+}
+
+// geometrySize returns the bytes of the vertex buffers and indices of a geometry
+func geometrySize(geom *geometry.Geometry) int64 {
+	size := 4 * int64(len(geom.Indices()))
+	for _, vbo := range geom.VBOs() {
+		size += int64(vbo.Buffer().Bytes())
+	}
+	return size
+}
+
+// inView returns true if a local bounding box transformed by a world matrix intersects the frustum, a nil frustum contains everything
+func inView(box math32.Box3, matrixWorld math32.Matrix4, frustum *math32.Frustum) bool {
+	if frustum == nil {
+		return true
+	}
+	box.ApplyMatrix4(&matrixWorld)
+	return frustum.IntersectsBox(&box)
+}
@@ -0,0 +1,85 @@
+package renderer
+
+import (
+	"testing"
+
+	"github.com/g3n/engine/geometry"
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/math32"
+)
+
+func memoryTestGeometry(offset float32) *geometry.Geometry {
+	geom := geometry.NewGeometry()
+	positions := math32.NewArrayF32(0, 9)
+	positions.Append(offset, 0, 0, offset+1, 0, 0, offset, 1, 0)
+	geom.AddVBO(gls.NewVBO(positions).AddAttrib(gls.VertexPosition))
+	indices := math32.NewArrayU32(0, 3)
+	indices.Append(0, 1, 2)
+	geom.SetIndices(indices)
+	return geom
+}
+
+func TestGeometrySize(t *testing.T) {
+	assert(t, geometrySize(memoryTestGeometry(0)), int64(48))
+}
+
+func TestInView(t *testing.T) {
+	var proj math32.Matrix4
+	proj.MakeOrthographic(-1, 1, 1, -1, 0.1, 10)
+	frustum := math32.NewFrustumFromMatrix(&proj)
+	box := math32.Box3{Min: math32.Vector3{X: -0.5, Y: -0.5, Z: -2}, Max: math32.Vector3{X: 0.5, Y: 0.5, Z: -1}}
+	var identity, moved math32.Matrix4
+	identity.Identity()
+	moved.MakeTranslation(5, 0, 0)
+	assert(t, inView(box, identity, frustum), true)
+	assert(t, inView(box, moved, frustum), false)
+	assert(t, inView(box, moved, nil), true)
+}
+
+func TestEvictAndReload(t *testing.T) {
+	m := memoryState{geometries: make(map[*geometry.Geometry]*residentGeometry)}
+	defer m.close()
+	old, recent := memoryTestGeometry(0), memoryTestGeometry(2)
+	m.geometries[old] = &residentGeometry{size: geometrySize(old), lastVisible: 1}
+	m.geometries[recent] = &residentGeometry{size: geometrySize(recent), lastVisible: 2}
+	m.used = 96
+
+	left, err := m.evictUnseen(10, 3)
+	assert(t, err, nil)
+	assert(t, left, int64(0))
+	assert(t, m.geometries[old].evicted, true)
+	assert(t, m.geometries[recent].evicted, false)
+	assert(t, m.used, int64(48))
+	assert(t, m.evicted, 1)
+	assert(t, old.Items(), 0)
+	assert(t, old.Indexed(), false)
+	// bounding queries still see the swapped out vertices
+	assert(t, old.BoundingBox(), math32.Box3{Max: math32.Vector3{X: 1, Y: 1}})
+
+	// geometries in view are not evicted
+	left, err = m.evictUnseen(100, 2)
+	assert(t, err, nil)
+	assert(t, left, int64(100))
+
+	assert(t, m.reload(old, m.geometries[old]), nil)
+	assert(t, m.used, int64(96))
+	assert(t, m.evicted, 0)
+	assert(t, old.Items(), 3)
+	assert(t, len(old.Indices()), 3)
+	assert(t, (*old.VBOs()[0].Buffer())[3], float32(1))
+
+	// data on disk is reused by later evictions
+	size := m.swapSize
+	assert(t, m.evict(old, m.geometries[old]), nil)
+	assert(t, m.swapSize, size)
+	assert(t, m.reload(old, m.geometries[old]), nil)
+	assert(t, old.Indices()[2], uint32(2))
+
+	assert(t, m.evict(recent, m.geometries[recent]), nil)
+	assert(t, m.reload(recent, m.geometries[recent]), nil)
+	assert(t, (*recent.VBOs()[0].Buffer())[0], float32(2))
+
+	m.drop(old, m.geometries[old])
+	assert(t, m.used, int64(48))
+	assert(t, len(m.geometries), 1)
+}
@@ -4,8 +4,9 @@ import "encoding/json"
 
 // Message for client
 type Message struct {
-	Action string `json:"action"`
-	Value  string `json:"value"`
+	Action string      `json:"action"`
+	Value  string      `json:"value"`
+	Data   interface{} `json:"data,omitempty"`
 }
 
 // sendMessageToClient sends a message to the client
@@ -13,9 +14,21 @@ func (app *RenderingApp) sendMessageToClient(action string, value string) {
 	m := &Message{Action: action, Value: value}
 	msgJSON, err := json.Marshal(m)
 	if err != nil {
-		app.Application.Log().Error(err.Error())
+		app.log.Error(err.Error())
 		return
 	}
-	app.Log().Info("sending message: " + string(msgJSON))
-	app.cImagestream <- []byte(string(msgJSON))
+	app.log.Debug("sending message: %s", msgJSON)
+	app.stream([]byte(string(msgJSON)))
+}
+
+// sendDataToClient sends a message with structured data to the client
+func (app *RenderingApp) sendDataToClient(action string, data interface{}) {
+	m := &Message{Action: action, Data: data}
+	msgJSON, err := json.Marshal(m)
+	if err != nil {
+		app.log.Error(err.Error())
+		return
+	}
+	app.log.Debug("sending message: %s", msgJSON)
+	app.stream(msgJSON)
 }
@@ -0,0 +1,162 @@
+package renderer
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+
+	"github.com/g3n/engine/camera"
+	"github.com/g3n/engine/math32"
+	"github.com/llgcode/draw2d/draw2dimg"
+)
+
+// minimapMargin is the distance of the minimap to the top right corner of the frame in pixels
+const minimapMargin = 10
+
+// minimapMinSize is the smallest minimap edge length in pixels, smaller frames get no minimap
+const minimapMinSize = 64
+
+// minimapState holds the top down view of the model composited into frames
+type minimapState struct {
+	enabled bool
+	frame   *image.RGBA // view of the last frame, north up
+}
+
+// minimapView maps scene positions on the ground plane to minimap pixels
+type minimapView struct {
+	center math32.Vector3
+	half   float32 // half the edge length of the mapped area in scene units
+	size   int     // edge length in pixels
+}
+
+// Minimap shows a top down view of the model with the camera at the top right of the frame, an empty value toggles
+func (app *RenderingApp) Minimap(cmd Command) {
+	switch cmd.Val {
+	case "":
+		app.minimap.enabled = !app.minimap.enabled
+	case "on":
+		app.minimap.enabled = true
+	case "off":
+		app.minimap.enabled = false
+	default:
+		app.sendMessageToClient("minimap", "invalid value "+cmd.Val)
+		return
+	}
+	app.minimap.frame = nil
+}
+
+// renderMinimap renders the model from above, marking position and view of the camera
+func (app *RenderingApp) renderMinimap() {
+	size := minimapSize(app.Width, app.Height)
+	if size == 0 || app.modelRoot == nil {
+		app.minimap.frame = nil
+		return
+	}
+	bbox := app.modelRoot.BoundingBox()
+	view := minimapView{center: *bbox.Center(nil), size: size}
+	view.half = math32.Max(bbox.Max.X-bbox.Min.X, bbox.Max.Z-bbox.Min.Z) * 0.55
+	if view.half <= 0 {
+		app.minimap.frame = nil
+		return
+	}
+	// north (-z) is up, east (+x) right
+	margin := view.half
+	cam := camera.NewOrthographic(-1, 1, 1, -1, 0, bbox.Max.Y-bbox.Min.Y+2*margin)
+	cam.SetZoom(1 / view.half)
+	cam.SetPosition(view.center.X, bbox.Max.Y+margin, view.center.Z)
+	cam.SetUp(&math32.Vector3{Z: -1})
+	cam.LookAt(&view.center)
+
+	gl := app.Gl()
+	gl.Viewport(0, 0, int32(size), int32(size))
+	if _, err := app.Renderer().Render(cam); err != nil {
+		app.log.Error("minimap pass failed: %v", err)
+	}
+	pix := gl.ReadPixels(0, 0, size, size, 6408, 5121)
+	gl.Viewport(0, 0, int32(app.Width), int32(app.Height))
+
+	if app.minimap.frame == nil || app.minimap.frame.Rect.Dx() != size {
+		app.minimap.frame = image.NewRGBA(image.Rect(0, 0, size, size))
+	}
+	frame := app.minimap.frame
+	// opengl rows start at the bottom
+	for y := 0; y < size; y++ {
+		copy(frame.Pix[y*frame.Stride:(y+1)*frame.Stride], pix[(size-1-y)*size*4:(size-y)*size*4])
+	}
+	if LinearWorkflow {
+		app.output.apply(frame.Pix)
+	}
+	eye := app.Camera().GetCamera()
+	left, right := frustumEdges(eye.Position(), eye.Target(), app.CameraPersp().Fov(), float32(app.Width)/float32(app.Height))
+	drawFrustum(frame, view, eye.Position(), left, right)
+}
+
+// minimapSize returns the minimap edge length for a frame size, 0 if the frame is too small
+func minimapSize(w, h int) int {
+	size := w
+	if h < size {
+		size = h
+	}
+	size /= 4
+	if size < minimapMinSize {
+		return 0
+	}
+	return size
+}
+
+// frustumEdges returns the ends of the left and right edge of the view on the ground plane,
+// as far from the camera as its target. Views straight down have no edges.
+func frustumEdges(position, target math32.Vector3, fov, aspect float32) (*math32.Vector3, *math32.Vector3) {
+	dir := math32.Vector3{X: target.X - position.X, Z: target.Z - position.Z}
+	length := dir.Length()
+	if length < 1e-6 {
+		return nil, nil
+	}
+	a := math32.Atan(math32.Tan(math32.DegToRad(fov)/2) * aspect)
+	edge := func(angle float32) *math32.Vector3 {
+		d := dir.Clone().ApplyAxisAngle(&math32.Vector3{Y: 1}, angle).MultiplyScalar(1 / math32.Cos(a))
+		return d.Add(&position)
+	}
+	return edge(a), edge(-a)
+}
+
+// point returns the minimap pixel of a scene position
+func (v minimapView) point(p math32.Vector3) (float64, float64) {
+	scale := float32(v.size) / (2 * v.half)
+	return float64((p.X-v.center.X)*scale + float32(v.size)/2), float64((p.Z-v.center.Z)*scale + float32(v.size)/2)
+}
+
+// drawFrustum marks the camera position and its view edges
+func drawFrustum(img *image.RGBA, view minimapView, position math32.Vector3, left, right *math32.Vector3) {
+	gc := draw2dimg.NewGraphicContext(img)
+	gc.SetStrokeColor(color.RGBA{R: 255, A: 255})
+	gc.SetFillColor(color.RGBA{R: 255, A: 255})
+	gc.SetLineWidth(1.5)
+	x, y := view.point(position)
+	if left != nil && right != nil {
+		lx, ly := view.point(*left)
+		rx, ry := view.point(*right)
+		gc.BeginPath()
+		gc.MoveTo(lx, ly)
+		gc.LineTo(x, y)
+		gc.LineTo(rx, ry)
+		gc.Stroke()
+	}
+	gc.BeginPath()
+	gc.ArcTo(x, y, 3, 3, 0, 2*math.Pi)
+	gc.Fill()
+}
+
+// DrawMinimap composites a minimap with a border into the top right corner of the image
+func DrawMinimap(img *image.RGBA, minimap *image.RGBA) *image.RGBA {
+	b := img.Bounds()
+	size := minimap.Bounds().Size()
+	if size.X+2*minimapMargin > b.Dx() || size.Y+2*minimapMargin > b.Dy() {
+		return img
+	}
+	r := image.Rect(b.Max.X-minimapMargin-size.X, b.Min.Y+minimapMargin, b.Max.X-minimapMargin, b.Min.Y+minimapMargin+size.Y)
+	draw.Draw(img, r.Inset(-1), image.NewUniform(color.RGBA{R: 64, G: 64, B: 64, A: 255}), image.Point{}, draw.Src)
+	draw.Draw(img, r, minimap, minimap.Bounds().Min, draw.Src)
+	return img
+}
@@ -0,0 +1,52 @@
+package renderer
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/g3n/engine/math32"
+)
+
+func TestMinimapSize(t *testing.T) {
+	assert(t, minimapSize(800, 600), 150)
+	assert(t, minimapSize(200, 800), 0)
+}
+
+func TestFrustumEdges(t *testing.T) {
+	// looking north with 90 degrees horizontal field of view
+	left, right := frustumEdges(math32.Vector3{Y: 5}, math32.Vector3{Z: -10}, 90, 1)
+	if left.DistanceTo(&math32.Vector3{X: -10, Y: 5, Z: -10}) > 1e-3 {
+		t.Error("left edge incorrect", left)
+	}
+	if right.DistanceTo(&math32.Vector3{X: 10, Y: 5, Z: -10}) > 1e-3 {
+		t.Error("right edge incorrect", right)
+	}
+	left, right = frustumEdges(math32.Vector3{Y: 5}, math32.Vector3{}, 90, 1)
+	assert(t, left == nil && right == nil, true)
+}
+
+func TestMinimapPoint(t *testing.T) {
+	v := minimapView{center: math32.Vector3{X: 10, Z: 10}, half: 5, size: 100}
+	x, y := v.point(math32.Vector3{X: 10, Y: 3, Z: 10})
+	assert(t, x, float64(50))
+	assert(t, y, float64(50))
+	// north is up
+	x, y = v.point(math32.Vector3{X: 15, Z: 5})
+	assert(t, x, float64(100))
+	assert(t, y, float64(0))
+}
+
+func TestDrawMinimap(t *testing.T) {
+	minimap := image.NewRGBA(image.Rect(0, 0, 20, 20))
+	red := color.RGBA{R: 255, A: 255}
+	minimap.SetRGBA(0, 0, red)
+	img := DrawMinimap(image.NewRGBA(image.Rect(0, 0, 100, 50)), minimap)
+	assert(t, img.RGBAAt(100-minimapMargin-20, minimapMargin), red)
+	assert(t, img.RGBAAt(100-minimapMargin, minimapMargin).R, uint8(64))
+	assert(t, img.RGBAAt(0, 0), color.RGBA{})
+
+	// too small frames get no minimap
+	small := DrawMinimap(image.NewRGBA(image.Rect(0, 0, 30, 30)), minimap)
+	assert(t, small.RGBAAt(30-minimapMargin-20, minimapMargin), color.RGBA{})
+}
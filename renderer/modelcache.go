@@ -0,0 +1,210 @@
+package renderer
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/g3n/engine/loader/gltf"
+)
+
+// ModelCacheSize is the maximum size of model files and their decoded buffers kept in memory in bytes, 0 disables the cache
+var ModelCacheSize int64 = 512 << 20
+
+// models is the model cache shared by all sessions
+var models = newModelCache()
+
+// cachedModel is the content of a model file and its parsed documents by resource directory
+type cachedModel struct {
+	data      []byte
+	templates map[string]*gltf.GLTF
+	size      int64
+	lastUsed  time.Time
+}
+
+// cachedFile maps a file version to the hash of its content
+type cachedFile struct {
+	modTime time.Time
+	size    int64
+	hash    string
+}
+
+// modelCache keeps model files in memory keyed by content hash,
+// so a model opened by several sessions is read and parsed only once
+// and identical files share their memory.
+type modelCache struct {
+	mu     sync.Mutex
+	files  map[string]cachedFile
+	models map[string]*cachedModel
+	size   int64
+}
+
+func newModelCache() *modelCache {
+	return &modelCache{files: make(map[string]cachedFile), models: make(map[string]*cachedModel)}
+}
+
+// read returns the content and hash of a model file
+func (c *modelCache) read(path string) ([]byte, string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, "", err
+	}
+
+	c.mu.Lock()
+	if f, ok := c.files[path]; ok && f.modTime.Equal(info.ModTime()) && f.size == info.Size() {
+		if m, ok := c.models[f.hash]; ok {
+			m.lastUsed = time.Now()
+			c.mu.Unlock()
+			return m.data, f.hash, nil
+		}
+	}
+	c.mu.Unlock()
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, "", err
+	}
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+	if int64(len(data)) > ModelCacheSize {
+		return data, hash, nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.files[path] = cachedFile{modTime: info.ModTime(), size: info.Size(), hash: hash}
+	if m, ok := c.models[hash]; ok {
+		m.lastUsed = time.Now()
+		return m.data, hash, nil
+	}
+	c.models[hash] = &cachedModel{data: data, size: int64(len(data)), lastUsed: time.Now()}
+	c.size += int64(len(data))
+	c.evict()
+	return data, hash, nil
+}
+
+// parse returns the parsed document of cached model content.
+// The document and its decoded buffers are parsed once per resource directory and shared by all sessions,
+// every caller gets an own copy to load nodes, materials and images from.
+// External buffers are kept with the document, so they are not read again until the model file changes.
+// Documents without resource directory, i.e. remote models, have to embed all resources.
+func (c *modelCache) parse(hash string, data []byte, ext string, dir string) (*gltf.GLTF, error) {
+	c.mu.Lock()
+	m, cached := c.models[hash]
+	if cached {
+		if t, ok := m.templates[dir]; ok {
+			m.lastUsed = time.Now()
+			c.mu.Unlock()
+			return cloneModel(t), nil
+		}
+	}
+	c.mu.Unlock()
+
+	var g *gltf.GLTF
+	var err error
+	if ext == ".gltf" {
+		g, err = gltf.ParseJSONReader(bytes.NewReader(data), dir)
+	} else {
+		g, err = gltf.ParseBinReader(bytes.NewReader(data), dir)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if dir == "" {
+		if err := checkEmbedded(g); err != nil {
+			return nil, err
+		}
+	}
+	if !cached || hasMorphTargets(g) {
+		return g, nil
+	}
+	size := loadBuffers(g)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if m, ok := c.models[hash]; ok {
+		if m.templates == nil {
+			m.templates = make(map[string]*gltf.GLTF)
+		}
+		if _, ok := m.templates[dir]; !ok {
+			m.templates[dir] = g
+			m.size += size
+			c.size += size
+			c.evict()
+		}
+	}
+	return cloneModel(g), nil
+}
+
+// loadBuffers decodes the buffers of a document by loading its meshes into a throwaway copy.
+// It returns the size of the buffers which are not part of the model file.
+func loadBuffers(g *gltf.GLTF) int64 {
+	w := cloneModel(g)
+	deferTextures(w)
+	for i := range w.Meshes {
+		w.LoadMesh(i)
+	}
+	g.Buffers, g.BufferViews = w.Buffers, w.BufferViews
+	var size int64
+	for _, b := range g.Buffers {
+		if b.Uri != "" {
+			size += int64(b.ByteLength)
+		}
+	}
+	return size
+}
+
+// cloneModel copies a parsed document without any loaded nodes, meshes, materials or images.
+// Decoded buffers are shared: the loader aliases float attributes to them, which are replaced but never modified in place.
+func cloneModel(g *gltf.GLTF) *gltf.GLTF {
+	c := *g
+	c.Accessors = append([]gltf.Accessor(nil), g.Accessors...)
+	c.Animations = append([]gltf.Animation(nil), g.Animations...)
+	c.Buffers = append([]gltf.Buffer(nil), g.Buffers...)
+	c.BufferViews = append([]gltf.BufferView(nil), g.BufferViews...)
+	c.Cameras = append([]gltf.Camera(nil), g.Cameras...)
+	c.Images = append([]gltf.Image(nil), g.Images...)
+	c.Materials = append([]gltf.Material(nil), g.Materials...)
+	c.Meshes = append([]gltf.Mesh(nil), g.Meshes...)
+	c.Nodes = append([]gltf.Node(nil), g.Nodes...)
+	c.Skins = append([]gltf.Skin(nil), g.Skins...)
+	c.Textures = append([]gltf.Texture(nil), g.Textures...)
+	// deferred textures are removed from the pbr settings
+	for i, m := range c.Materials {
+		if m.PbrMetallicRoughness != nil {
+			pbr := *m.PbrMetallicRoughness
+			c.Materials[i].PbrMetallicRoughness = &pbr
+		}
+	}
+	return &c
+}
+
+// hasMorphTargets returns true if any mesh has morph targets, their deltas are applied to the loaded buffers
+func hasMorphTargets(g *gltf.GLTF) bool {
+	for _, m := range g.Meshes {
+		for _, p := range m.Primitives {
+			if len(p.Targets) > 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// evict removes least recently used models until the cache fits into ModelCacheSize
+func (c *modelCache) evict() {
+	for c.size > ModelCacheSize {
+		var oldest string
+		for hash, m := range c.models {
+			if oldest == "" || m.lastUsed.Before(c.models[oldest].lastUsed) {
+				oldest = hash
+			}
+		}
+		c.size -= c.models[oldest].size
+		delete(c.models, oldest)
+	}
+}
@@ -0,0 +1,97 @@
+package renderer
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/g3n/engine/geometry"
+	"github.com/g3n/engine/graphic"
+	"github.com/g3n/engine/material"
+	"github.com/g3n/engine/math32"
+)
+
+func TestModelCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "models")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	a := filepath.Join(dir, "a.glb")
+	b := filepath.Join(dir, "b.glb")
+	ioutil.WriteFile(a, []byte("model"), 0644)
+	ioutil.WriteFile(b, []byte("model"), 0644)
+
+	cache := newModelCache()
+	data, hashA, err := cache.read(a)
+	assert(t, err, nil)
+	assert(t, string(data), "model")
+	_, hashB, _ := cache.read(b)
+	// identical files share one entry
+	assert(t, hashA, hashB)
+	assert(t, len(cache.models), 1)
+	assert(t, cache.size, int64(5))
+
+	_, _, err = cache.read(filepath.Join(dir, "missing.glb"))
+	assert(t, err != nil, true)
+}
+
+func TestModelCacheEvict(t *testing.T) {
+	size := ModelCacheSize
+	defer func() { ModelCacheSize = size }()
+	ModelCacheSize = 8
+
+	dir, err := ioutil.TempDir("", "models")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	a := filepath.Join(dir, "a.glb")
+	b := filepath.Join(dir, "b.glb")
+	ioutil.WriteFile(a, []byte("first"), 0644)
+	ioutil.WriteFile(b, []byte("second"), 0644)
+
+	cache := newModelCache()
+	cache.read(a)
+	cache.read(b)
+	assert(t, len(cache.models), 1)
+	assert(t, cache.size, int64(6))
+}
+
+func TestModelCacheParse(t *testing.T) {
+	dir, err := ioutil.TempDir("", "models")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "a.gltf")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mat := material.NewStandard(math32.NewColor("red"))
+	writeGLTF(f, graphic.NewMesh(geometry.NewBox(1, 2, 3), mat))
+	f.Close()
+
+	cache := newModelCache()
+	data, hash, _ := cache.read(path)
+	size := cache.size
+	first, err := cache.parse(hash, data, ".gltf", dir)
+	assert(t, err, nil)
+	second, err := cache.parse(hash, data, ".gltf", dir)
+	assert(t, err, nil)
+	// sessions get own documents sharing the decoded buffers
+	assert(t, first != second, true)
+	assert(t, len(cache.models[hash].templates), 1)
+	assert(t, cache.size > size, true)
+	a, err := first.LoadMesh(0)
+	assert(t, err, nil)
+	b, err := second.LoadMesh(0)
+	assert(t, err, nil)
+	assert(t, a != b, true)
+
+	// remote models have to embed their resources
+	_, err = cache.parse("other", []byte(`{"buffers": [{"uri": "../secret.bin", "byteLength": 4}]}`), ".gltf", "")
+	assert(t, err != nil, true)
+}
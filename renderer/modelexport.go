@@ -0,0 +1,139 @@
+package renderer
+
+import (
+	"bytes"
+	"encoding/base64"
+	"path/filepath"
+	"strings"
+
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/graphic"
+	"github.com/g3n/engine/math32"
+)
+
+// Exportmodel sends the visible model with all applied changes as glTF (gltf, default),
+// binary glTF (glb) or Wavefront OBJ (obj). Textures are not exported.
+// Exports run on the render thread, which keeps swapped out meshes in memory while they are written.
+func (app *RenderingApp) Exportmodel(cmd Command) {
+	update := func() {
+		if app.modelRoot == nil {
+			app.sendMessageToClient("exportmodel", "no model loaded")
+			return
+		}
+		// the children of the model root, which carries the global model scale
+		app.sendModel(cmd.Val, "model", app.modelRoot.GetNode().Children())
+	}
+	select {
+	case app.sceneUpdates <- update:
+	case <-app.quit:
+	}
+}
+
+// Exportselection sends the selected nodes in model coordinates like Exportmodel, e.g. to hand over a subassembly
+func (app *RenderingApp) Exportselection(cmd Command) {
+	update := func() {
+		var selection []core.INode
+		for inode := range app.selectionBuffer {
+			selection = append(selection, inode)
+		}
+		nodes := topmostNodes(selection)
+		if len(nodes) == 0 || app.modelRoot == nil {
+			app.sendMessageToClient("exportselection", "nothing selected")
+			return
+		}
+		// selected nodes are exported with their own materials instead of the highlight
+		for inode, materials := range app.selectionBuffer {
+			gfx := inode.(graphic.IGraphic).GetGraphic()
+			gfx.ClearMaterials()
+			for _, gm := range materials {
+				gfx.AddMaterial(gm.IGraphic(), gm.IMaterial(), 0, 0)
+			}
+		}
+		app.sendModel(cmd.Val, "selection", nodes)
+		for inode := range app.selectionBuffer {
+			app.highlight(inode)
+		}
+	}
+	select {
+	case app.sceneUpdates <- update:
+	case <-app.quit:
+	}
+}
+
+// sendModel sends nodes and their visible children in model coordinates as download named after the model.
+// It has to run on the render thread.
+func (app *RenderingApp) sendModel(format string, suffix string, nodes []core.INode) {
+	app.loadGeometries(nodes)
+	transforms := modelTransforms(app.modelRoot, nodes)
+	buf := new(bytes.Buffer)
+	var err error
+	switch format {
+	case "", "gltf", "glb":
+		gw := newGLTFWriter(true)
+		gw.transforms = transforms
+		if format == "glb" {
+			err = gw.writeBinary(buf, nodes...)
+		} else {
+			format = "gltf"
+			err = gw.write(buf, nodes...)
+		}
+	case "obj":
+		err = writeOBJ(buf, transforms, nodes...)
+	default:
+		app.sendMessageToClient("export"+suffix, "unknown model format "+format)
+		return
+	}
+	if err != nil {
+		app.log.Error("model export failed: %v", err)
+		app.sendMessageToClient("export"+suffix, err.Error())
+		return
+	}
+	name := strings.TrimSuffix(filepath.Base(app.modelpath), filepath.Ext(app.modelpath))
+	if suffix != "model" {
+		name += "-" + suffix
+	}
+	app.sendDataToClient("export", annotationExport{
+		Name:   name + "." + format,
+		Format: format,
+		File:   base64.StdEncoding.EncodeToString(buf.Bytes()),
+	})
+}
+
+// modelTransforms returns the transforms of nodes relative to the model root, which leaves out the global model scale
+func modelTransforms(root core.INode, nodes []core.INode) map[core.INode]math32.Matrix4 {
+	var inverse math32.Matrix4
+	rootWorld := root.GetNode().MatrixWorld()
+	if err := inverse.GetInverse(&rootWorld); err != nil {
+		inverse.Identity()
+	}
+	transforms := make(map[core.INode]math32.Matrix4)
+	for _, n := range nodes {
+		world := n.GetNode().MatrixWorld()
+		var m math32.Matrix4
+		m.MultiplyMatrices(&inverse, &world)
+		transforms[n] = m
+	}
+	return transforms
+}
+
+// topmostNodes leaves out nodes whose ancestor is in the list, as they are exported with it
+func topmostNodes(nodes []core.INode) []core.INode {
+	included := make(map[core.INode]bool)
+	for _, n := range nodes {
+		included[n] = true
+	}
+	var topmost []core.INode
+	for _, n := range nodes {
+		nested := false
+		for p := n.GetNode().Parent(); p != nil; p = p.GetNode().Parent() {
+			if included[p] {
+				nested = true
+				break
+			}
+		}
+		if !nested {
+			topmost = append(topmost, n)
+		}
+	}
+	return topmost
+}
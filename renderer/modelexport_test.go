@@ -0,0 +1,39 @@
+package renderer
+
+import (
+	"testing"
+
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/math32"
+)
+
+func TestTopmostNodes(t *testing.T) {
+	root := core.NewNode()
+	group := core.NewNode()
+	child := core.NewNode()
+	other := core.NewNode()
+	root.Add(group)
+	group.Add(child)
+	root.Add(other)
+	nodes := topmostNodes([]core.INode{child, group, other})
+	assert(t, len(nodes), 2)
+	assert(t, nodes[0], core.INode(group))
+	assert(t, nodes[1], core.INode(other))
+}
+
+func TestModelTransforms(t *testing.T) {
+	root := core.NewNode()
+	root.SetScale(2, 2, 2)
+	child := core.NewNode()
+	child.SetPosition(1, 0, 0)
+	root.Add(child)
+	root.UpdateMatrixWorld()
+
+	m := modelTransforms(root, []core.INode{child})[child]
+	var p, s math32.Vector3
+	var q math32.Quaternion
+	m.Decompose(&p, &q, &s)
+	// the scale of the root is left out
+	assert(t, p, math32.Vector3{X: 1})
+	assert(t, s, math32.Vector3{X: 1, Y: 1, Z: 1})
+}
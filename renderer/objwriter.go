@@ -0,0 +1,94 @@
+package renderer
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/graphic"
+	"github.com/g3n/engine/math32"
+)
+
+// writeOBJ writes the visible meshes below the given nodes as Wavefront OBJ with one object per mesh.
+// Vertices are transformed by the matrix of their root, roots without matrix keep their coordinates.
+// Materials and texture coordinates are not written.
+func writeOBJ(w io.Writer, transforms map[core.INode]math32.Matrix4, nodes ...core.INode) error {
+	ow := &objWriter{w: bufio.NewWriter(w)}
+	fmt.Fprintln(ow.w, "# webg3n")
+	for _, n := range nodes {
+		matrix, ok := transforms[n]
+		if !ok {
+			matrix.Identity()
+		}
+		ow.addNode(n, &matrix)
+	}
+	return ow.w.Flush()
+}
+
+// objWriter keeps the number of written vertices, as faces refer to vertices across objects
+type objWriter struct {
+	w        *bufio.Writer
+	vertices int
+}
+
+// addNode writes a mesh and the meshes of its visible children
+func (ow *objWriter) addNode(inode core.INode, matrix *math32.Matrix4) {
+	node := inode.GetNode()
+	if !node.Visible() {
+		return
+	}
+	if mesh, ok := inode.(*graphic.Mesh); ok {
+		ow.addMesh(node.Name(), mesh, matrix)
+	}
+	for _, child := range node.Children() {
+		local := child.GetNode().Matrix()
+		var m math32.Matrix4
+		m.MultiplyMatrices(matrix, &local)
+		ow.addNode(child, &m)
+	}
+}
+
+// addMesh writes the transformed vertices, normals and faces of a mesh
+func (ow *objWriter) addMesh(name string, mesh *graphic.Mesh, matrix *math32.Matrix4) {
+	geom := mesh.GetGeometry()
+	positions := readAttribute(geom, gls.VertexPosition, 3)
+	if len(positions) == 0 {
+		return
+	}
+	normals := readAttribute(geom, gls.VertexNormal, 3)
+	hasNormals := len(normals) == len(positions)
+	var normalMatrix math32.Matrix3
+	normalMatrix.GetNormalMatrix(matrix)
+
+	fmt.Fprintf(ow.w, "o %s\n", name)
+	var v math32.Vector3
+	for i := 0; i < len(positions); i += 3 {
+		v.Set(positions[i], positions[i+1], positions[i+2]).ApplyMatrix4(matrix)
+		fmt.Fprintf(ow.w, "v %g %g %g\n", v.X, v.Y, v.Z)
+	}
+	if hasNormals {
+		for i := 0; i < len(normals); i += 3 {
+			v.Set(normals[i], normals[i+1], normals[i+2]).ApplyMatrix3(&normalMatrix).Normalize()
+			fmt.Fprintf(ow.w, "vn %g %g %g\n", v.X, v.Y, v.Z)
+		}
+	}
+
+	indices := []uint32(geom.Indices())
+	if len(indices) == 0 {
+		indices = make([]uint32, len(positions)/3)
+		for i := range indices {
+			indices[i] = uint32(i)
+		}
+	}
+	for i := 0; i+2 < len(indices); i += 3 {
+		a, b, c := ow.vertices+int(indices[i])+1, ow.vertices+int(indices[i+1])+1, ow.vertices+int(indices[i+2])+1
+		if hasNormals {
+			fmt.Fprintf(ow.w, "f %d//%d %d//%d %d//%d\n", a, a, b, b, c, c)
+		} else {
+			fmt.Fprintf(ow.w, "f %d %d %d\n", a, b, c)
+		}
+	}
+	ow.vertices += len(positions) / 3
+}
@@ -0,0 +1,42 @@
+package renderer
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/geometry"
+	"github.com/g3n/engine/graphic"
+	"github.com/g3n/engine/material"
+	"github.com/g3n/engine/math32"
+)
+
+func TestWriteOBJ(t *testing.T) {
+	mat := material.NewStandard(math32.NewColor("red"))
+	first := graphic.NewMesh(geometry.NewBox(1, 1, 1), mat)
+	first.SetName("first")
+	second := graphic.NewMesh(geometry.NewBox(1, 1, 1), mat)
+	second.SetName("second")
+	hidden := graphic.NewMesh(geometry.NewBox(1, 1, 1), mat)
+	hidden.SetVisible(false)
+	first.Add(second)
+	first.Add(hidden)
+	var moved math32.Matrix4
+	moved.MakeTranslation(10, 0, 0)
+
+	var buf bytes.Buffer
+	assert(t, writeOBJ(&buf, map[core.INode]math32.Matrix4{first: moved}, first), nil)
+	obj := buf.String()
+	assert(t, strings.Count(obj, "\no "), 2)
+	// a box has 24 vertices and 12 triangles
+	assert(t, strings.Count(obj, "\nv "), 48)
+	assert(t, strings.Count(obj, "\nf "), 24)
+	// faces of the second object refer to its own vertices
+	if !strings.Contains(obj, "f 25//25") {
+		t.Error("wrong vertex offset of the second object")
+	}
+	if !strings.Contains(obj, "v 10.5 ") {
+		t.Error("vertices are not transformed")
+	}
+}
@@ -0,0 +1,102 @@
+package renderer
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/g3n/engine/math32"
+)
+
+// OrbitLimits constrain the camera of sessions, e.g. to keep kiosk visitors above the ground and near the model
+type OrbitLimits struct {
+	MinPolarAngle float32 // degrees of the camera from straight above the target, 90 is level with it
+	MaxPolarAngle float32
+	MinDistance   float32 // distance of the camera to its target in model units
+	MaxDistance   float32 // 0 disables the limit
+	PanBounds     float32 // the target stays within the model bounds grown by this fraction of their size, 0 disables the bounds
+}
+
+// DefaultOrbitLimits apply to all sessions, by default the camera is unconstrained
+var DefaultOrbitLimits = OrbitLimits{MaxPolarAngle: 180}
+
+// Validate checks the ranges of the limits
+func (l OrbitLimits) Validate() error {
+	if l.MinPolarAngle < 0 || l.MaxPolarAngle > 180 || l.MinPolarAngle > l.MaxPolarAngle {
+		return fmt.Errorf("polar angles must be within 0 to 180 degrees, minimum first")
+	}
+	if l.MinDistance < 0 || l.MaxDistance < 0 || (l.MaxDistance > 0 && l.MinDistance > l.MaxDistance) {
+		return fmt.Errorf("distances must be positive, minimum first")
+	}
+	if l.PanBounds < 0 {
+		return fmt.Errorf("pan bounds must be positive")
+	}
+	return nil
+}
+
+// applyOrbitLimits makes the orbit control stop at the angle and distance limits
+func (app *RenderingApp) applyOrbitLimits() {
+	l := DefaultOrbitLimits
+	oc := app.Orbit()
+	oc.MinPolarAngle = math32.DegToRad(l.MinPolarAngle)
+	oc.MaxPolarAngle = math32.DegToRad(l.MaxPolarAngle)
+	oc.MinDistance = math32.Max(0.01, l.MinDistance*ModelScale)
+	oc.MaxDistance = float32(math.Inf(1))
+	if l.MaxDistance > 0 {
+		oc.MaxDistance = l.MaxDistance * ModelScale
+	}
+}
+
+// constrainCamera keeps views set by commands or transitions within the limits before rendering
+func (app *RenderingApp) constrainCamera(evname string, ev interface{}) {
+	l := DefaultOrbitLimits
+	if l == (OrbitLimits{MaxPolarAngle: 180}) || app.modelRoot == nil {
+		return
+	}
+	if l.PanBounds > 0 && app.orbitBounds == nil {
+		bbox := app.modelRoot.BoundingBox()
+		app.orbitBounds = &bbox
+	}
+	cam := app.Camera().GetCamera()
+	position, target := l.constrain(cam.Position(), cam.Target(), app.orbitBounds)
+	if position != cam.Position() || target != cam.Target() {
+		cam.SetPositionVec(&position)
+		cam.LookAt(&target)
+	}
+}
+
+// constrain returns a view within the limits, the target is moved into the bounds first, then the camera
+// is rotated and moved along its view direction. Views within the limits are returned unchanged.
+func (l OrbitLimits) constrain(position, target math32.Vector3, bounds *math32.Box3) (math32.Vector3, math32.Vector3) {
+	if bounds != nil && l.PanBounds > 0 {
+		// Box3.Size of the engine returns the negative size
+		margin := bounds.Max
+		margin.Sub(&bounds.Min).MultiplyScalar(l.PanBounds)
+		min, max := bounds.Min, bounds.Max
+		min.Sub(&margin)
+		max.Add(&margin)
+		clamped := target
+		clamped.Clamp(&min, &max)
+		position.Add(clamped.Clone().Sub(&target))
+		target = clamped
+	}
+	offset := position
+	offset.Sub(&target)
+	distance := offset.Length()
+	if distance == 0 {
+		return position, target
+	}
+	polar := math32.Acos(math32.Clamp(offset.Y/distance, -1, 1))
+	azimuth := math32.Atan2(offset.X, offset.Z)
+	p := math32.Clamp(polar, math32.DegToRad(l.MinPolarAngle), math32.DegToRad(l.MaxPolarAngle))
+	d := math32.Max(distance, l.MinDistance*ModelScale)
+	if l.MaxDistance > 0 {
+		d = math32.Min(d, l.MaxDistance*ModelScale)
+	}
+	if p == polar && d == distance {
+		return position, target
+	}
+	offset = math32.Vector3{X: math32.Sin(p) * math32.Sin(azimuth), Y: math32.Cos(p), Z: math32.Sin(p) * math32.Cos(azimuth)}
+	position = target
+	position.Add(offset.MultiplyScalar(d))
+	return position, target
+}
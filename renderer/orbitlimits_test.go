@@ -0,0 +1,47 @@
+package renderer
+
+import (
+	"testing"
+
+	"github.com/g3n/engine/math32"
+)
+
+func TestOrbitLimitsValidate(t *testing.T) {
+	assert(t, DefaultOrbitLimits.Validate(), nil)
+	assert(t, OrbitLimits{MinPolarAngle: 10, MaxPolarAngle: 85, MinDistance: 1, MaxDistance: 50, PanBounds: 0.2}.Validate(), nil)
+	for _, l := range []OrbitLimits{
+		{MinPolarAngle: 90, MaxPolarAngle: 45},
+		{MaxPolarAngle: 200},
+		{MaxPolarAngle: 180, MinDistance: 10, MaxDistance: 5},
+		{MaxPolarAngle: 180, PanBounds: -1},
+	} {
+		if l.Validate() == nil {
+			t.Errorf("invalid limits %+v accepted", l)
+		}
+	}
+}
+
+func TestOrbitLimitsConstrain(t *testing.T) {
+	near := func(a, b math32.Vector3) bool { return a.DistanceTo(&b) < 1e-4 }
+
+	// views within the limits stay unchanged
+	l := OrbitLimits{MaxPolarAngle: 90, MaxDistance: 20}
+	position, target := l.constrain(math32.Vector3{X: 3, Y: 4}, math32.Vector3{}, nil)
+	assert(t, position, math32.Vector3{X: 3, Y: 4})
+	assert(t, target, math32.Vector3{})
+
+	// below the ground the camera is rotated up to the horizon, keeping its distance
+	position, _ = l.constrain(math32.Vector3{X: 0, Y: -5, Z: 5}, math32.Vector3{}, nil)
+	assert(t, near(position, math32.Vector3{Z: math32.Sqrt(50)}), true)
+
+	// too far away the camera moves closer along its view direction
+	position, _ = l.constrain(math32.Vector3{X: 30, Y: 40}, math32.Vector3{}, nil)
+	assert(t, near(position, math32.Vector3{X: 12, Y: 16}), true)
+
+	// the target is moved into the grown bounds, the camera follows
+	l = OrbitLimits{MaxPolarAngle: 180, PanBounds: 0.5}
+	bounds := math32.Box3{Min: math32.Vector3{X: -1, Y: -1, Z: -1}, Max: math32.Vector3{X: 1, Y: 1, Z: 1}}
+	position, target = l.constrain(math32.Vector3{X: 10, Y: 1}, math32.Vector3{X: 5}, &bounds)
+	assert(t, target, math32.Vector3{X: 2})
+	assert(t, position, math32.Vector3{X: 7, Y: 1})
+}
@@ -0,0 +1,150 @@
+package renderer
+
+import (
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/graphic"
+	"github.com/g3n/engine/material"
+	"github.com/g3n/engine/math32"
+)
+
+// outline width in pixels
+const outlineWidth = 2
+
+// outline color as rgb
+var outlineColor = [3]byte{255, 140, 0}
+
+// selection styles
+const (
+	selectMaterial = iota
+	selectOutline
+	selectBoth
+)
+
+// outlineState is the selection as outlined by the render thread, a copy of the selection buffer
+type outlineState struct {
+	style int
+	nodes []core.INode
+}
+
+// Selectionstyle sets how selected nodes are highlighted: material, outline or both
+func (app *RenderingApp) Selectionstyle(cmd Command) {
+	selection := make([]core.INode, 0, len(app.selectionBuffer))
+	for inode := range app.selectionBuffer {
+		selection = append(selection, inode)
+	}
+	app.resetSelection()
+	switch cmd.Val {
+	case "material":
+		app.selectionStyle = selectMaterial
+	case "outline":
+		app.selectionStyle = selectOutline
+	case "both":
+		app.selectionStyle = selectBoth
+	default:
+		app.sendMessageToClient("selectionstyle", "unknown selection style "+cmd.Val)
+	}
+	for _, inode := range selection {
+		app.changeNodeMaterial(inode)
+	}
+}
+
+// outlinesSelection returns true if the frame needs a selection outline
+func (app *RenderingApp) outlinesSelection() bool {
+	return app.outline.style != selectMaterial && len(app.outline.nodes) > 0 && app.singleView()
+}
+
+// drawSelectionOutline renders the silhouette of all selected graphics
+// and draws its outline onto the bottom up frame
+func (app *RenderingApp) drawSelectionOutline(pix []byte) {
+	w, h := app.Width, app.Height
+	if app.outlineMaterial == nil {
+		app.outlineMaterial = material.NewStandard(&math32.Color{})
+		app.outlineMaterial.SetSpecularColor(&math32.Color{})
+		app.outlineMaterial.SetEmissiveColor(&math32.Color{R: 1, G: 1, B: 1})
+		app.outlineMaterial.SetUseLights(material.UseLightNone)
+		app.outlineMaterial.SetSide(material.SideDouble)
+	}
+
+	// render selected graphics only, in white
+	originals := make(map[core.INode][]graphic.GraphicMaterial, len(app.outline.nodes))
+	for _, inode := range app.outline.nodes {
+		gnode := inode.(graphic.IGraphic)
+		gfx := gnode.GetGraphic()
+		originals[inode] = append([]graphic.GraphicMaterial(nil), gfx.Materials()...)
+		gfx.ClearMaterials()
+		gfx.AddMaterial(gnode, app.outlineMaterial, 0, 0)
+	}
+	visible := make(map[core.INode]bool)
+	forEachGraphic([]core.INode{app.Scene()}, func(inode core.INode) {
+		if _, selected := originals[inode]; !selected {
+			visible[inode] = inode.GetNode().Visible()
+			inode.GetNode().SetVisible(false)
+		}
+	})
+	gl := app.Gl()
+	gl.ClearColor(0, 0, 0, 1)
+	if _, err := app.Renderer().Render(app.Camera()); err != nil {
+		app.log.Error("outline pass failed: %v", err)
+	}
+	data := gl.ReadPixels(0, 0, w, h, gls.RGBA, gls.UNSIGNED_BYTE)
+	gl.ClearColor(1, 1, 1, 1)
+	for inode, v := range visible {
+		inode.GetNode().SetVisible(v)
+	}
+	for inode, materials := range originals {
+		gfx := inode.(graphic.IGraphic).GetGraphic()
+		gfx.ClearMaterials()
+		for _, gm := range materials {
+			gfx.AddMaterial(gm.IGraphic(), gm.IMaterial(), 0, 0)
+		}
+	}
+
+	mask := make([]bool, w*h)
+	for i := range mask {
+		mask[i] = data[i*4] > 0
+	}
+	color := outlineColor
+	if LinearWorkflow {
+		for c := range color {
+			color[c] = app.output.decode[color[c]]
+		}
+	}
+	for i, edge := range outlineMask(mask, w, h, outlineWidth) {
+		if edge {
+			copy(pix[i*4:i*4+3], color[:])
+		}
+	}
+}
+
+// outlineMask returns all pixels within width of the mask which are not part of it
+func outlineMask(mask []bool, w, h, width int) []bool {
+	// separable dilation, first horizontal then vertical
+	horizontal := make([]bool, len(mask))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			if !mask[y*w+x] {
+				continue
+			}
+			for k := x - width; k <= x+width; k++ {
+				if k >= 0 && k < w {
+					horizontal[y*w+k] = true
+				}
+			}
+		}
+	}
+	outline := make([]bool, len(mask))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			if !horizontal[y*w+x] {
+				continue
+			}
+			for k := y - width; k <= y+width; k++ {
+				if k >= 0 && k < h && !mask[k*w+x] {
+					outline[k*w+x] = true
+				}
+			}
+		}
+	}
+	return outline
+}
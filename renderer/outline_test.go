@@ -0,0 +1,45 @@
+package renderer
+
+import (
+	"testing"
+
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/graphic"
+)
+
+func TestOutlineMask(t *testing.T) {
+	// single pixel in the center of a 5x5 mask
+	mask := make([]bool, 25)
+	mask[12] = true
+	outline := outlineMask(mask, 5, 5, 1)
+	assert(t, outline[12], false)
+	assert(t, outline[6], true)
+	assert(t, outline[18], true)
+	assert(t, outline[0], false)
+	count := 0
+	for _, o := range outline {
+		if o {
+			count++
+		}
+	}
+	assert(t, count, 8)
+}
+
+func TestShareSelection(t *testing.T) {
+	app := RenderingApp{selectionBuffer: map[core.INode][]graphic.GraphicMaterial{}, selectionStyle: selectOutline}
+	app.sceneUpdates = make(chan func(), 1)
+	node := core.NewNode()
+	app.selectionBuffer[node] = nil
+	app.selectionChanged = true
+	app.shareSelection()
+	assert(t, len(app.sceneUpdates), 1)
+	// the render thread keeps its copy when the selection changes again
+	(<-app.sceneUpdates)()
+	delete(app.selectionBuffer, node)
+	assert(t, len(app.outline.nodes), 1)
+	assert(t, app.outline.nodes[0], core.INode(node))
+	assert(t, app.outline.style, selectOutline)
+	// unchanged selections are not passed on
+	app.shareSelection()
+	assert(t, len(app.sceneUpdates), 0)
+}
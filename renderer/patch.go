@@ -0,0 +1,239 @@
+package renderer
+
+import (
+	"fmt"
+
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/geometry"
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/graphic"
+	"github.com/g3n/engine/material"
+	"github.com/g3n/engine/math32"
+)
+
+// number of patch batches queued per session before Patch blocks
+const patchQueueSize = 64
+
+// NodePatch changes a node of a running scene, unset fields are left unchanged
+type NodePatch struct {
+	Node     string                 `json:"node"`
+	Position *[3]float32            `json:"position,omitempty"`
+	Rotation *[4]float32            `json:"rotation,omitempty"` // quaternion x, y, z, w
+	Scale    *[3]float32            `json:"scale,omitempty"`
+	Visible  *bool                  `json:"visible,omitempty"`
+	Color    string                 `json:"color,omitempty"`
+	UserData map[string]interface{} `json:"userdata,omitempty"` // merged into the node user data
+	Geometry *GeometryPatch         `json:"geometry,omitempty"`
+}
+
+// GeometryPatch replaces the geometry of a mesh node
+type GeometryPatch struct {
+	Positions []float32 `json:"positions"`
+	Normals   []float32 `json:"normals,omitempty"` // computed if missing
+	Indices   []uint32  `json:"indices,omitempty"`
+}
+
+// Validate checks a patch before it is queued
+func (p NodePatch) Validate() error {
+	if p.Node == "" {
+		return fmt.Errorf("missing node id")
+	}
+	if p.Color != "" {
+		if _, err := parseColor(p.Color); err != nil {
+			return err
+		}
+	}
+	if p.Geometry != nil {
+		return p.Geometry.validate()
+	}
+	return nil
+}
+
+// validate checks sizes and index bounds of a geometry
+func (g *GeometryPatch) validate() error {
+	if len(g.Positions) == 0 || len(g.Positions)%3 != 0 {
+		return fmt.Errorf("positions must be a non-empty list of xyz triples")
+	}
+	if len(g.Normals) > 0 && len(g.Normals) != len(g.Positions) {
+		return fmt.Errorf("normals must match positions")
+	}
+	if len(g.Indices)%3 != 0 {
+		return fmt.Errorf("indices must form triangles")
+	}
+	for _, i := range g.Indices {
+		if int(i) >= len(g.Positions)/3 {
+			return fmt.Errorf("index %d out of range", i)
+		}
+	}
+	return nil
+}
+
+// Patch queues node changes of an external feed, they are applied between two commands
+// before the next frame. Patches are expected to be validated.
+func (app *RenderingApp) Patch(patches []NodePatch) {
+	if app.Window() == nil {
+		return
+	}
+	app.queueCommandUpdate(func() { app.patch(patches) })
+}
+
+// patch applies node changes on the render thread while the command goroutine waits,
+// swapped geometries change the node buffer and the selection
+func (app *RenderingApp) patch(patches []NodePatch) {
+	app.renderSync(func() { app.applyPatches(patches) })
+}
+
+// applySceneUpdates applies queued scene changes before a frame gets rendered
+func (app *RenderingApp) applySceneUpdates(evname string, ev interface{}) {
+	for {
+		select {
+		case update := <-app.sceneUpdates:
+			update()
+			app.frameCache.invalidate()
+		default:
+			return
+		}
+	}
+}
+
+// applyPatches changes the scene and updates the culling boxes of moved nodes
+func (app *RenderingApp) applyPatches(patches []NodePatch) {
+	moved := false
+	for _, p := range patches {
+		inode, ok := app.nodeBuffer[p.Node]
+		if !ok {
+			app.log.Warn("patch: unknown node %s", p.Node)
+			continue
+		}
+		node := inode.GetNode()
+		if p.Position != nil {
+			node.SetPosition(p.Position[0], p.Position[1], p.Position[2])
+			moved = true
+		}
+		if p.Rotation != nil {
+			node.SetQuaternion(p.Rotation[0], p.Rotation[1], p.Rotation[2], p.Rotation[3])
+			moved = true
+		}
+		if p.Scale != nil {
+			node.SetScale(p.Scale[0], p.Scale[1], p.Scale[2])
+			moved = true
+		}
+		if p.Visible != nil {
+			node.SetVisible(*p.Visible)
+		}
+		if p.UserData != nil {
+			node.SetUserData(mergeUserData(node.UserData(), p.UserData))
+		}
+		if p.Geometry != nil {
+			mesh, ok := inode.(*graphic.Mesh)
+			if !ok {
+				app.log.Warn("patch: node %s has no geometry", p.Node)
+			} else {
+				inode = app.replaceGeometry(p.Node, mesh, p.Geometry)
+				moved = true
+			}
+		}
+		if p.Color != "" {
+			color, _ := parseColor(p.Color)
+			mat := material.NewStandard(color)
+			forEachGraphic([]core.INode{inode}, func(inode core.INode) {
+				gfx := inode.(graphic.IGraphic).GetGraphic()
+				gfx.ClearMaterials()
+				gfx.AddMaterial(inode.(graphic.IGraphic), mat, 0, 0)
+			})
+		}
+	}
+	if moved && app.modelRoot != nil {
+		app.buildCullingBoxes(app.modelRoot)
+	}
+}
+
+// replaceGeometry swaps a mesh for a new one with the given geometry,
+// keeping name, transform, materials and children
+func (app *RenderingApp) replaceGeometry(id string, old *graphic.Mesh, g *GeometryPatch) core.INode {
+	app.resetSelection()
+	geom := geometry.NewGeometry()
+	geom.AddVBO(gls.NewVBO(math32.ArrayF32(g.Positions)).AddAttrib(gls.VertexPosition))
+	indices := g.Indices
+	if len(indices) == 0 {
+		indices = make([]uint32, len(g.Positions)/3)
+		for i := range indices {
+			indices[i] = uint32(i)
+		}
+	}
+	normals := g.Normals
+	if len(normals) == 0 {
+		normals = computeNormals(g.Positions, indices)
+	}
+	geom.AddVBO(gls.NewVBO(math32.ArrayF32(normals)).AddAttrib(gls.VertexNormal))
+	geom.SetIndices(math32.ArrayU32(indices))
+
+	mesh := graphic.NewMesh(geom, nil)
+	for _, gm := range old.Materials() {
+		mesh.AddMaterial(gm.IMaterial(), 0, 0)
+	}
+	node := mesh.GetNode()
+	node.SetName(old.Name())
+	position, quaternion, scale := old.Position(), old.Quaternion(), old.Scale()
+	node.SetPositionVec(&position)
+	node.SetQuaternionQuat(&quaternion)
+	node.SetScaleVec(&scale)
+	node.SetVisible(old.Visible())
+	node.SetUserData(old.UserData())
+	// adding a child removes it from the old mesh
+	children := append([]core.INode(nil), old.Children()...)
+	for _, child := range children {
+		mesh.Add(child)
+	}
+
+	parent := old.Parent().GetNode()
+	idx := parent.ChildIndex(old)
+	parent.RemoveAt(idx)
+	parent.AddAt(idx, mesh)
+	// materials stay in use, only the old geometry gets released
+	old.GetGeometry().Dispose()
+	app.nodeBuffer[id] = mesh
+	return mesh
+}
+
+// computeNormals returns smooth vertex normals averaged from the adjacent triangles
+func computeNormals(positions []float32, indices []uint32) []float32 {
+	normals := make([]float32, len(positions))
+	var a, b, c, ab, ac math32.Vector3
+	for i := 0; i+2 < len(indices); i += 3 {
+		a.Set(positions[indices[i]*3], positions[indices[i]*3+1], positions[indices[i]*3+2])
+		b.Set(positions[indices[i+1]*3], positions[indices[i+1]*3+1], positions[indices[i+1]*3+2])
+		c.Set(positions[indices[i+2]*3], positions[indices[i+2]*3+1], positions[indices[i+2]*3+2])
+		ab.SubVectors(&b, &a)
+		ac.SubVectors(&c, &a)
+		ab.Cross(&ac)
+		for k := 0; k < 3; k++ {
+			v := indices[i+k] * 3
+			normals[v] += ab.X
+			normals[v+1] += ab.Y
+			normals[v+2] += ab.Z
+		}
+	}
+	var n math32.Vector3
+	for i := 0; i < len(normals); i += 3 {
+		n.Set(normals[i], normals[i+1], normals[i+2]).Normalize()
+		normals[i], normals[i+1], normals[i+2] = n.X, n.Y, n.Z
+	}
+	return normals
+}
+
+// mergeUserData adds values to map user data, other user data is replaced
+func mergeUserData(data interface{}, values map[string]interface{}) interface{} {
+	m, ok := data.(map[string]interface{})
+	if !ok {
+		return values
+	}
+	merged := make(map[string]interface{}, len(m)+len(values))
+	for k, v := range m {
+		merged[k] = v
+	}
+	for k, v := range values {
+		merged[k] = v
+	}
+	return merged
+}
@@ -0,0 +1,36 @@
+package renderer
+
+import (
+	"testing"
+)
+
+func TestNodePatchValidate(t *testing.T) {
+	assert(t, NodePatch{}.Validate() != nil, true)
+	assert(t, NodePatch{Node: "/0/1"}.Validate(), nil)
+	assert(t, NodePatch{Node: "/0/1", Color: "nocolor"}.Validate() != nil, true)
+
+	g := &GeometryPatch{Positions: []float32{0, 0, 0, 1, 0, 0, 0, 1, 0}, Indices: []uint32{0, 1, 2}}
+	assert(t, NodePatch{Node: "/0/1", Geometry: g}.Validate(), nil)
+	g.Indices = []uint32{0, 1, 3}
+	assert(t, NodePatch{Node: "/0/1", Geometry: g}.Validate() != nil, true)
+	g.Indices = nil
+	g.Normals = []float32{0, 0, 1}
+	assert(t, NodePatch{Node: "/0/1", Geometry: g}.Validate() != nil, true)
+}
+
+func TestComputeNormals(t *testing.T) {
+	normals := computeNormals([]float32{0, 0, 0, 1, 0, 0, 0, 1, 0}, []uint32{0, 1, 2})
+	for i := 0; i < 9; i += 3 {
+		assert(t, normals[i+2], float32(1))
+	}
+}
+
+func TestMergeUserData(t *testing.T) {
+	merged := mergeUserData(map[string]interface{}{"a": 1, "b": 2}, map[string]interface{}{"b": 3})
+	m := merged.(map[string]interface{})
+	assert(t, m["a"], 1)
+	assert(t, m["b"], 3)
+
+	merged = mergeUserData("text", map[string]interface{}{"b": 3})
+	assert(t, merged.(map[string]interface{})["b"], 3)
+}
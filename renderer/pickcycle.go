@@ -0,0 +1,87 @@
+package renderer
+
+import (
+	"strconv"
+
+	"github.com/g3n/engine/core"
+)
+
+// pickCycleRadius is the distance in pixels within which repeated clicks cycle through the same candidates
+const pickCycleRadius = 4
+
+// pickCycleState holds the candidates of the last pick cycle
+type pickCycleState struct {
+	x, y       float32
+	candidates []core.INode
+	hits       []*Hit
+	index      int
+}
+
+// PickCycle is sent as pickcycle message with all nodes under the cursor and the selected one
+type PickCycle struct {
+	Index      int    `json:"index"`
+	Candidates []*Hit `json:"candidates"` // closest first
+}
+
+// Pickcycle selects overlapping nodes under the cursor one after another, repeated clicks at the same position
+// select the next node behind the current one. All candidates are sent to the client for a disambiguation popup,
+// a value selects a candidate of the last cycle by its index.
+func (app *RenderingApp) Pickcycle(cmd Command) {
+	p := &app.pickCycle
+	if cmd.Val != "" {
+		index, err := strconv.Atoi(cmd.Val)
+		if err != nil || index < 0 || index >= len(p.candidates) {
+			app.sendMessageToClient("pickcycle", "invalid candidate "+cmd.Val)
+			return
+		}
+		p.index = index
+	} else {
+		intersects := uniqueIntersects(app.raycast(cmd.X, cmd.Y))
+		candidates := make([]core.INode, len(intersects))
+		for i, intersect := range intersects {
+			candidates[i] = intersect.Object
+		}
+		p.index = p.next(cmd.X, cmd.Y, candidates)
+		p.x, p.y, p.candidates = cmd.X, cmd.Y, candidates
+		p.hits = make([]*Hit, len(intersects))
+		for i, intersect := range intersects {
+			p.hits[i] = newHit(intersect)
+			p.hits[i].Material = app.materialName(intersect.Object)
+		}
+	}
+	if len(p.candidates) == 0 {
+		app.selectObject(nil, false)
+		app.sendDataToClient("pickcycle", PickCycle{Candidates: []*Hit{}})
+		return
+	}
+	app.selectObject(p.candidates[p.index], false)
+	app.sendDataToClient("pickcycle", PickCycle{Index: p.index, Candidates: p.hits})
+}
+
+// next returns the candidate to select for a click, the one behind the last selected
+// if the click hits the same candidates near the last position, otherwise the closest
+func (p *pickCycleState) next(x, y float32, candidates []core.INode) int {
+	dx, dy := x-p.x, y-p.y
+	if len(candidates) == 0 || dx*dx+dy*dy > pickCycleRadius*pickCycleRadius || len(candidates) != len(p.candidates) {
+		return 0
+	}
+	for i := range candidates {
+		if candidates[i] != p.candidates[i] {
+			return 0
+		}
+	}
+	return (p.index + 1) % len(candidates)
+}
+
+// uniqueIntersects keeps the closest intersection of each node, e.g. a mesh is hit at its front and back
+func uniqueIntersects(intersects []core.Intersect) []core.Intersect {
+	seen := make(map[core.INode]bool)
+	unique := intersects[:0]
+	for _, i := range intersects {
+		if !seen[i.Object] {
+			seen[i.Object] = true
+			unique = append(unique, i)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,30 @@
+package renderer
+
+import (
+	"testing"
+
+	"github.com/g3n/engine/core"
+)
+
+func TestUniqueIntersects(t *testing.T) {
+	a, b := core.NewNode(), core.NewNode()
+	unique := uniqueIntersects([]core.Intersect{{Object: a, Distance: 1}, {Object: b, Distance: 2}, {Object: a, Distance: 3}})
+	assert(t, len(unique), 2)
+	assert(t, unique[0].Distance, float32(1))
+	assert(t, unique[1].Object, core.INode(b))
+}
+
+func TestPickCycleNext(t *testing.T) {
+	a, b, c := core.NewNode(), core.NewNode(), core.NewNode()
+	p := pickCycleState{x: 100, y: 100, candidates: []core.INode{a, b, c}}
+	assert(t, p.next(101, 99, []core.INode{a, b, c}), 1)
+	p.index = 2
+	assert(t, p.next(100, 100, []core.INode{a, b, c}), 0)
+	p.index = 1
+	// moved too far
+	assert(t, p.next(110, 100, []core.INode{a, b, c}), 0)
+	// different nodes under the cursor
+	assert(t, p.next(100, 100, []core.INode{a, c}), 0)
+	assert(t, p.next(100, 100, []core.INode{b, a, c}), 0)
+	assert(t, p.next(100, 100, nil), 0)
+}
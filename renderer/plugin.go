@@ -0,0 +1,40 @@
+package renderer
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// CommandHandler handles a custom command sent by the client
+type CommandHandler func(app *RenderingApp, cmd Command)
+
+// registry of custom commands, shared by all rendering apps
+var commandRegistry = make(map[string]CommandHandler)
+var commandRegistryMutex sync.RWMutex
+
+// RegisterCommand adds a custom command which can be sent by the client like any built-in command.
+// Names of built-in commands and already registered commands can't be registered again.
+func RegisterCommand(name string, handler CommandHandler) error {
+	if name == "" || handler == nil {
+		return fmt.Errorf("command name and handler are required")
+	}
+	if _, found := reflect.TypeOf(&RenderingApp{}).MethodByName(name); found {
+		return fmt.Errorf("command %s is a built-in command", name)
+	}
+	commandRegistryMutex.Lock()
+	defer commandRegistryMutex.Unlock()
+	if _, found := commandRegistry[name]; found {
+		return fmt.Errorf("command %s is already registered", name)
+	}
+	commandRegistry[name] = handler
+	return nil
+}
+
+// getCommandHandler returns the custom command handler registered by name
+func getCommandHandler(name string) (CommandHandler, bool) {
+	commandRegistryMutex.RLock()
+	defer commandRegistryMutex.RUnlock()
+	handler, found := commandRegistry[name]
+	return handler, found
+}
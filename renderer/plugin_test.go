@@ -0,0 +1,22 @@
+package renderer
+
+import (
+	"testing"
+)
+
+func TestRegisterCommand(t *testing.T) {
+	handler := func(app *RenderingApp, cmd Command) {}
+	assert(t, RegisterCommand("Customtest", handler), nil)
+	_, found := getCommandHandler("Customtest")
+	assert(t, found, true)
+
+	if RegisterCommand("Customtest", handler) == nil {
+		t.Error("duplicate command registered")
+	}
+	if RegisterCommand("Zoom", handler) == nil {
+		t.Error("built-in command overridden")
+	}
+	if RegisterCommand("", handler) == nil {
+		t.Error("empty command name registered")
+	}
+}
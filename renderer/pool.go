@@ -0,0 +1,54 @@
+package renderer
+
+import (
+	"bytes"
+	"encoding/base64"
+	"image"
+	"sync"
+)
+
+// encodePool reuses encode buffers across frames and sessions
+var encodePool = sync.Pool{
+	New: func() interface{} { return new(bytes.Buffer) },
+}
+
+// frameBuffers holds the buffers a session reuses from frame to frame
+type frameBuffers struct {
+	pixels []byte      // copy of the read pixels, the passes after reading share the opengl buffer
+	frame  *image.RGBA // the vertically flipped frame
+}
+
+// copyPixels copies data into the pixel buffer unless it already is the pixel buffer
+func (f *frameBuffers) copyPixels(data []byte) []byte {
+	if len(f.pixels) == len(data) && len(data) > 0 && &f.pixels[0] == &data[0] {
+		return data
+	}
+	f.pixels = append(f.pixels[:0], data...)
+	return f.pixels
+}
+
+// flipV writes img upside down into the frame buffer, the frame is only valid until the next one
+func (f *frameBuffers) flipV(img *image.RGBA) *image.RGBA {
+	b := img.Bounds()
+	if f.frame == nil || f.frame.Rect.Size() != b.Size() {
+		f.frame = image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	}
+	n := b.Dx() * 4
+	for y := 0; y < b.Dy(); y++ {
+		src := img.PixOffset(b.Min.X, b.Max.Y-1-y)
+		copy(f.frame.Pix[y*f.frame.Stride:y*f.frame.Stride+n], img.Pix[src:src+n])
+	}
+	return f.frame
+}
+
+// rgbaImage wraps pixels read from opengl without copying them
+func rgbaImage(pix []byte, w, h int) *image.RGBA {
+	return &image.RGBA{Pix: pix, Stride: 4 * w, Rect: image.Rect(0, 0, w, h)}
+}
+
+// encodeBase64 returns the base64 encoding of data in a single allocation
+func encodeBase64(data []byte) []byte {
+	out := make([]byte, base64.StdEncoding.EncodedLen(len(data)))
+	base64.StdEncoding.Encode(out, data)
+	return out
+}
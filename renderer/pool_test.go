@@ -0,0 +1,48 @@
+package renderer
+
+import (
+	"encoding/base64"
+	"image"
+	"testing"
+)
+
+func TestCopyPixels(t *testing.T) {
+	var f frameBuffers
+	data := []byte{1, 2, 3, 4}
+	pix := f.copyPixels(data)
+	data[0] = 9
+	assert(t, pix[0], byte(1))
+	// copying the buffer itself keeps it
+	assert(t, &f.copyPixels(pix)[0], &pix[0])
+	// the buffer is reused for frames of the same size
+	assert(t, &f.copyPixels([]byte{5, 6, 7, 8})[0], &pix[0])
+	assert(t, pix[0], byte(5))
+}
+
+func TestFlipV(t *testing.T) {
+	var f frameBuffers
+	img := rgbaImage([]byte{
+		1, 1, 1, 1, 2, 2, 2, 2,
+		3, 3, 3, 3, 4, 4, 4, 4,
+		5, 5, 5, 5, 6, 6, 6, 6,
+	}, 2, 3)
+	frame := f.flipV(img)
+	assert(t, frame.Bounds(), image.Rect(0, 0, 2, 3))
+	assert(t, frame.Pix[0], byte(5))
+	assert(t, frame.Pix[4], byte(6))
+	assert(t, frame.Pix[8], byte(3))
+	assert(t, frame.Pix[20], byte(2))
+	assert(t, f.flipV(img), frame)
+
+	// sub images are flipped within their bounds
+	sub := img.SubImage(image.Rect(1, 1, 2, 3)).(*image.RGBA)
+	frame = f.flipV(sub)
+	assert(t, frame.Bounds(), image.Rect(0, 0, 1, 2))
+	assert(t, frame.Pix[0], byte(6))
+	assert(t, frame.Pix[4], byte(4))
+}
+
+func TestEncodeBase64(t *testing.T) {
+	data := []byte("frame")
+	assert(t, string(encodeBase64(data)), base64.StdEncoding.EncodeToString(data))
+}
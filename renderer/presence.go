@@ -0,0 +1,165 @@
+package renderer
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/geometry"
+	"github.com/g3n/engine/graphic"
+	"github.com/g3n/engine/material"
+	"github.com/g3n/engine/math32"
+)
+
+// presenceInterval is the interval the cursor of a shared session is published
+const presenceInterval = 100 * time.Millisecond
+
+// Presence is the cursor and selection of a session, shared with other participants
+type Presence struct {
+	Cursor    *[3]float32 `json:"cursor,omitempty"` // world position under the cursor, nil if the cursor is off the model
+	Selection []string    `json:"selection"`
+}
+
+// Participant is another user of a shared session
+type Participant struct {
+	Name  string `json:"name"`
+	Color string `json:"color"`
+	Presence
+}
+
+// PresenceHandler receives cursor and selection changes by session id, nil disables sharing them
+var PresenceHandler func(session string, p Presence)
+
+// presenceState holds the published presence and the cursor markers of other participants
+type presenceState struct {
+	shared    bool // set once the session got participants, sessions on their own do not publish their cursor
+	published time.Time
+	mu        sync.Mutex // guards current, the render thread publishes the cursor, the command goroutine the selection
+	current   Presence
+	markers   map[string]*graphic.Mesh // by participant color
+	pointers  map[string]pointerMarker // by participant color
+	group     *core.Node
+}
+
+// reportPresence publishes the cursor once it moved
+func (app *RenderingApp) reportPresence(arg interface{}) {
+	if !app.presence.shared || !app.cursor.moved.After(app.presence.published) {
+		return
+	}
+	app.presence.published = time.Now()
+	var cursor *[3]float32
+	if hit := app.pick(app.cursor.x, app.cursor.y); hit != nil {
+		cursor = &hit.Point
+	}
+	app.publishPresence(func(p *Presence) { p.Cursor = cursor })
+}
+
+// publishPresence changes the presence of the session and passes it to the presence handler
+func (app *RenderingApp) publishPresence(update func(p *Presence)) {
+	if PresenceHandler == nil {
+		return
+	}
+	app.presence.mu.Lock()
+	defer app.presence.mu.Unlock()
+	p := &app.presence.current
+	if p.Selection == nil {
+		p.Selection = []string{}
+	}
+	update(p)
+	PresenceHandler(app.log.Session(), *p)
+}
+
+// selectedNames returns the sorted names of all selected nodes
+func (app *RenderingApp) selectedNames() []string {
+	names := []string{}
+	for inode := range app.selectionBuffer {
+		names = append(names, inode.GetNode().Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ShowParticipants sends the other participants of a shared session to the client
+// and shows their cursors as colored markers. Updates are dropped while the scene update queue is full.
+func (app *RenderingApp) ShowParticipants(participants []Participant) {
+	if app.Window() == nil {
+		return
+	}
+	select {
+	case app.sceneUpdates <- func() { app.showParticipants(participants) }:
+	default:
+	}
+}
+
+// showParticipants places one cursor marker per participant and removes markers of participants who left
+func (app *RenderingApp) showParticipants(participants []Participant) {
+	p := app.participantGroup()
+	active := make(map[string]bool)
+	for _, participant := range participants {
+		if participant.Cursor == nil {
+			continue
+		}
+		marker, ok := p.markers[participant.Color]
+		if !ok {
+			var err error
+			if marker, err = app.newCursorMarker(participant.Color); err != nil {
+				app.log.Warn("participant %s: %v", participant.Name, err)
+				continue
+			}
+			p.group.Add(marker)
+			p.markers[participant.Color] = marker
+		}
+		marker.SetPosition(participant.Cursor[0], participant.Cursor[1], participant.Cursor[2])
+		active[participant.Color] = true
+	}
+	for color, marker := range p.markers {
+		if !active[color] {
+			p.group.Remove(marker)
+			marker.Dispose()
+			delete(p.markers, color)
+		}
+	}
+	app.sendDataToClient("participants", participants)
+}
+
+// participantGroup returns the presence state, adding the group of participant markers to the scene on first use
+func (app *RenderingApp) participantGroup() *presenceState {
+	p := &app.presence
+	p.shared = true
+	if p.group == nil {
+		p.group = core.NewNode()
+		p.group.SetName("participants")
+		app.Scene().Add(p.group)
+		p.markers = make(map[string]*graphic.Mesh)
+		p.pointers = make(map[string]pointerMarker)
+	}
+	return p
+}
+
+// newCursorMarker returns an unlit sphere sized relative to the model
+func (app *RenderingApp) newCursorMarker(color string) (*graphic.Mesh, error) {
+	c, err := parseColor(color)
+	if err != nil {
+		return nil, err
+	}
+	radius := float32(0.05)
+	if app.modelRoot != nil {
+		if box, ok := getWorldBoundingBox([]core.INode{app.modelRoot}); ok {
+			radius = math32.Max(box.Size(nil).Length()*0.005, 0.001)
+		}
+	}
+	mat := material.NewStandard(&math32.Color{})
+	mat.SetSpecularColor(&math32.Color{})
+	mat.SetEmissiveColor(c)
+	mat.SetUseLights(material.UseLightNone)
+	marker := graphic.NewMesh(geometry.NewSphere(float64(radius), 12, 8, 0, 2*math.Pi, 0, math.Pi), mat)
+	marker.SetName("cursor " + color)
+	return marker, nil
+}
+
+// isCursorMarker returns true for markers of other participants, which are not picked
+func (app *RenderingApp) isCursorMarker(inode core.INode) bool {
+	return app.presence.group != nil && inode.GetNode().Parent() == core.INode(app.presence.group)
+}
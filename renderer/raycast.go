@@ -0,0 +1,170 @@
+package renderer
+
+import (
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/graphic"
+	"github.com/g3n/engine/math32"
+)
+
+// Hit is the result of a raycast sent to the client
+type Hit struct {
+	Node     string      `json:"node"`
+	Point    [3]float32  `json:"point"`
+	Normal   [3]float32  `json:"normal"`
+	Distance float32     `json:"distance"`
+	Snap     string      `json:"snap,omitempty"`
+	UV       *[2]float32 `json:"uv,omitempty"`       // texture coordinates at the hit point
+	Material string      `json:"material,omitempty"` // gltf material name
+}
+
+// raycast intersects the scene at the given screen coordinates,
+// intersections are sorted by distance, closest first
+func (app *RenderingApp) raycast(mx float32, my float32) []core.Intersect {
+	hits := app.screenRaycaster(mx, my).IntersectObject(app.Scene(), true)
+	if app.presence.group == nil && app.shadows.mesh == nil && app.ground.node == nil {
+		return hits
+	}
+	picked := hits[:0]
+	for _, hit := range hits {
+		if !app.isOverlay(hit.Object) {
+			picked = append(picked, hit)
+		}
+	}
+	return picked
+}
+
+// screenRaycaster returns a raycaster from the camera through the given screen coordinates
+func (app *RenderingApp) screenRaycaster(mx float32, my float32) *core.Raycaster {
+	width, height := app.Window().Size()
+	x := (-.5 + mx/float32(width)) * 2.0
+	y := (.5 - my/float32(height)) * 2.0
+	r := core.NewRaycaster(&math32.Vector3{}, &math32.Vector3{})
+	app.CameraPersp().SetRaycaster(r, x, y)
+	return r
+}
+
+// isOverlay returns true for graphics shown in addition to the model, which are not picked
+func (app *RenderingApp) isOverlay(inode core.INode) bool {
+	return app.isCursorMarker(inode) || inode == core.INode(app.shadows.mesh) || app.isGround(inode)
+}
+
+// getFaceVertices returns the world coordinates of the face hit by an intersection
+func getFaceVertices(i core.Intersect) ([3]math32.Vector3, bool) {
+	var face [3]math32.Vector3
+	gnode, ok := i.Object.(graphic.IGraphic)
+	if !ok {
+		return face, false
+	}
+	found := false
+	idx := uint32(0)
+	gnode.GetGeometry().ReadFaces(func(vA, vB, vC math32.Vector3) bool {
+		if idx == i.Index {
+			face = [3]math32.Vector3{vA, vB, vC}
+			found = true
+			return true
+		}
+		idx += 3
+		return false
+	})
+	if !found {
+		return face, false
+	}
+	matrixWorld := i.Object.GetNode().MatrixWorld()
+	for k := range face {
+		face[k].ApplyMatrix4(&matrixWorld)
+	}
+	return face, true
+}
+
+// getFaceNormal returns the normalized normal of a triangle
+func getFaceNormal(face [3]math32.Vector3) math32.Vector3 {
+	ab := face[1]
+	ab.Sub(&face[0])
+	ac := face[2]
+	ac.Sub(&face[0])
+	var n math32.Vector3
+	n.CrossVectors(&ab, &ac).Normalize()
+	return n
+}
+
+// toArray converts a vector into a JSON friendly array
+func toArray(v math32.Vector3) [3]float32 {
+	return [3]float32{v.X, v.Y, v.Z}
+}
+
+// newHit creates the client hit data of an intersection
+func newHit(i core.Intersect) *Hit {
+	hit := &Hit{Node: i.Object.GetNode().Name(), Point: toArray(i.Point), Distance: i.Distance}
+	if face, ok := getFaceVertices(i); ok {
+		hit.Normal = toArray(getFaceNormal(face))
+		hit.UV = getFaceUV(i, face)
+	}
+	return hit
+}
+
+// getFaceUV returns the texture coordinates at the hit point of a face in world coordinates, nil without texture coordinates
+func getFaceUV(i core.Intersect, face [3]math32.Vector3) *[2]float32 {
+	geom := i.Object.(graphic.IGraphic).GetGeometry()
+	uvs := readAttribute(geom, gls.VertexTexcoord, 2)
+	if len(uvs) == 0 {
+		return nil
+	}
+	vertices := [3]int{int(i.Index), int(i.Index) + 1, int(i.Index) + 2}
+	if geom.Indexed() {
+		indices := geom.Indices()
+		if int(i.Index)+2 >= len(indices) {
+			return nil
+		}
+		for k := range vertices {
+			vertices[k] = int(indices[int(i.Index)+k])
+		}
+	}
+	weights := barycentric(i.Point, face)
+	var uv [2]float32
+	for k, v := range vertices {
+		if 2*v+1 >= len(uvs) {
+			return nil
+		}
+		uv[0] += weights[k] * uvs[2*v]
+		uv[1] += weights[k] * uvs[2*v+1]
+	}
+	return &uv
+}
+
+// barycentric returns the weights of the triangle vertices at a point on the triangle
+func barycentric(p math32.Vector3, face [3]math32.Vector3) [3]float32 {
+	var v0, v1, v2 math32.Vector3
+	v0.SubVectors(&face[1], &face[0])
+	v1.SubVectors(&face[2], &face[0])
+	v2.SubVectors(&p, &face[0])
+	d00, d01, d11 := v0.Dot(&v0), v0.Dot(&v1), v1.Dot(&v1)
+	d20, d21 := v2.Dot(&v0), v2.Dot(&v1)
+	denom := d00*d11 - d01*d01
+	if denom == 0 {
+		return [3]float32{1, 0, 0}
+	}
+	v := (d11*d20 - d01*d21) / denom
+	w := (d00*d21 - d01*d20) / denom
+	return [3]float32{1 - v - w, v, w}
+}
+
+// materialName returns the gltf name of the material of a graphic, materials replaced
+// by a selection or coloring are looked up by the original material
+func (app *RenderingApp) materialName(inode core.INode) string {
+	materials := app.originalMaterials(inode)
+	if len(materials) == 0 {
+		return ""
+	}
+	return app.materialInfos[materials[0].IMaterial()].Name
+}
+
+// Raycast sends the 3D hit data at the given screen coordinates without changing the selection
+func (app *RenderingApp) Raycast(cmd Command) {
+	hit := app.pick(cmd.X, cmd.Y)
+	if hit == nil {
+		app.sendDataToClient("raycast", nil)
+		return
+	}
+	app.sendDataToClient("raycast", hit)
+}
@@ -0,0 +1,53 @@
+package renderer
+
+import (
+	"testing"
+
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/geometry"
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/graphic"
+	"github.com/g3n/engine/math32"
+)
+
+func TestGetFaceNormal(t *testing.T) {
+	face := [3]math32.Vector3{{X: 0, Y: 0, Z: 0}, {X: 1, Y: 0, Z: 0}, {X: 0, Y: 1, Z: 0}}
+	n := getFaceNormal(face)
+	assert(t, n, math32.Vector3{X: 0, Y: 0, Z: 1})
+}
+
+func TestBarycentric(t *testing.T) {
+	face := [3]math32.Vector3{{X: 0, Y: 0, Z: 0}, {X: 2, Y: 0, Z: 0}, {X: 0, Y: 2, Z: 0}}
+	assert(t, barycentric(face[0], face), [3]float32{1, 0, 0})
+	assert(t, barycentric(face[2], face), [3]float32{0, 0, 1})
+	assert(t, barycentric(math32.Vector3{X: 1, Y: 1}, face), [3]float32{0, 0.5, 0.5})
+}
+
+func TestGetFaceUV(t *testing.T) {
+	geom := geometry.NewGeometry()
+	positions := math32.NewArrayF32(0, 12)
+	positions.Append(0, 0, 0, 2, 0, 0, 0, 2, 0, 2, 2, 0)
+	uvs := math32.NewArrayF32(0, 8)
+	uvs.Append(0, 0, 1, 0, 0, 1, 1, 1)
+	geom.AddVBO(gls.NewVBO(positions).AddAttrib(gls.VertexPosition))
+	geom.AddVBO(gls.NewVBO(uvs).AddAttrib(gls.VertexTexcoord))
+	indices := math32.NewArrayU32(0, 6)
+	indices.Append(0, 1, 2, 1, 3, 2)
+	geom.SetIndices(indices)
+	mesh := graphic.NewMesh(geom, nil)
+
+	// second face of the quad
+	i := core.Intersect{Object: mesh, Index: 3, Point: math32.Vector3{X: 1.5, Y: 1.5}}
+	face, ok := getFaceVertices(i)
+	assert(t, ok, true)
+	uv := getFaceUV(i, face)
+	if uv == nil || math32.Abs(uv[0]-0.75) > 1e-5 || math32.Abs(uv[1]-0.75) > 1e-5 {
+		t.Error("wrong texture coordinates", uv)
+	}
+
+	// meshes without texture coordinates have none
+	plain := geometry.NewGeometry()
+	plain.AddVBO(gls.NewVBO(positions).AddAttrib(gls.VertexPosition))
+	i.Object, i.Index = graphic.NewMesh(plain, nil), 0
+	assert(t, getFaceUV(i, face) == nil, true)
+}
@@ -0,0 +1,109 @@
+package renderer
+
+import (
+	"bytes"
+	"encoding/base64"
+	"image"
+	"image/color/palette"
+	"image/draw"
+	"image/gif"
+)
+
+// every recordInterval'th rendered frame is recorded
+const recordInterval = 3
+
+// delay between recorded frames in 100ths of a second
+const recordDelay = 10
+
+// maxRecordFrames limits the length of a recording, recording stops when it is reached
+const maxRecordFrames = 600
+
+// recordState holds the frames of a running recording
+type recordState struct {
+	active bool
+	skip   int
+	frames *gif.GIF
+	done   func() // called after the recording was sent
+}
+
+// Record starts (start) or stops and sends (stop) a recording of the rendered frames as animated GIF.
+// The recording is started and stopped by the render loop, which records the frames.
+func (app *RenderingApp) Record(cmd Command) {
+	var update func()
+	switch cmd.Val {
+	case "start":
+		update = func() { app.startRecording(nil) }
+	case "stop":
+		update = app.stopRecording
+	default:
+		app.sendMessageToClient("record", "unknown record command "+cmd.Val)
+		return
+	}
+	select {
+	case app.sceneUpdates <- update:
+	case <-app.quit:
+	}
+}
+
+// startRecording records all following frames, done is called once the recording was sent.
+// It runs on the render thread.
+func (app *RenderingApp) startRecording(done func()) {
+	app.recording = recordState{active: true, frames: &gif.GIF{}, done: done}
+}
+
+// stopRecording ends a running recording on the render thread, its frames are encoded and sent in the background
+func (app *RenderingApp) stopRecording() {
+	r := app.recording
+	app.recording = recordState{}
+	if r.active {
+		go app.sendRecording(r)
+	}
+}
+
+// sendRecording encodes the frames of a stopped recording and sends them to the client
+func (app *RenderingApp) sendRecording(r recordState) {
+	if len(r.frames.Image) == 0 {
+		app.sendMessageToClient("record", "no frames recorded")
+		return
+	}
+	buf := new(bytes.Buffer)
+	if err := gif.EncodeAll(buf, r.frames); err != nil {
+		app.log.Error("encoding recording failed: %v", err)
+		app.sendMessageToClient("record", err.Error())
+		return
+	}
+	app.sendDataToClient("recording", annotationExport{
+		Name:   "recording.gif",
+		Format: "gif",
+		File:   base64.StdEncoding.EncodeToString(buf.Bytes()),
+	})
+	if r.done != nil {
+		r.done()
+	}
+}
+
+// recordFrame adds a rendered frame to a running recording
+func (app *RenderingApp) recordFrame(img *image.RGBA) {
+	r := &app.recording
+	if !r.active {
+		return
+	}
+	r.skip++
+	if r.skip < recordInterval {
+		return
+	}
+	r.skip = 0
+	r.frames.Image = append(r.frames.Image, quantize(app.watermark(img)))
+	r.frames.Delay = append(r.frames.Delay, recordDelay)
+	if len(r.frames.Image) >= maxRecordFrames {
+		app.log.Warn("recording stopped after %d frames", maxRecordFrames)
+		app.stopRecording()
+	}
+}
+
+// quantize reduces an image to the colors of a fixed palette
+func quantize(img image.Image) *image.Paletted {
+	p := image.NewPaletted(img.Bounds(), palette.Plan9)
+	draw.FloydSteinberg.Draw(p, img.Bounds(), img, img.Bounds().Min)
+	return p
+}
@@ -0,0 +1,18 @@
+package renderer
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestQuantize(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	for i := 0; i < len(img.Pix); i += 4 {
+		img.Pix[i], img.Pix[i+3] = 255, 255
+	}
+	p := quantize(img)
+	assert(t, p.Bounds(), img.Bounds())
+	r, g, b, _ := p.At(1, 1).RGBA()
+	assert(t, color.RGBA{uint8(r >> 8), uint8(g >> 8), uint8(b >> 8), 255}, color.RGBA{255, 0, 0, 255})
+}
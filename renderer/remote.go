@@ -0,0 +1,276 @@
+package renderer
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"time"
+)
+
+// ModelSources lists URLs remote models may be loaded from, e.g. s3://bucket/models/.
+// Models must have the scheme and host of a source and lie below its path. Remote models are disabled if the list is empty.
+var ModelSources []string
+
+// MaxModelSize limits the size of downloaded models in bytes
+var MaxModelSize int64 = 512 << 20
+
+var modelClient = &http.Client{Timeout: 5 * time.Minute, CheckRedirect: checkModelRedirect}
+
+// IsRemoteModel returns true if the model is loaded from an http(s) or s3 URL
+func IsRemoteModel(model string) bool {
+	return strings.HasPrefix(model, "http://") || strings.HasPrefix(model, "https://") || strings.HasPrefix(model, "s3://")
+}
+
+// IsAllowedModelSource returns true if the remote model URL matches a configured model source
+func IsAllowedModelSource(model string) bool {
+	for _, source := range ModelSources {
+		if matchesModelSource(model, source) {
+			return true
+		}
+	}
+	return false
+}
+
+// isAllowedModelURL returns true if a requested http(s) URL belongs to a configured model source
+func isAllowedModelURL(u string) bool {
+	for _, source := range ModelSources {
+		if matchesModelSource(u, modelSourceURL(source)) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesModelSource returns true if a URL has the scheme and host of a source and its path segments start with the source's
+func matchesModelSource(model string, source string) bool {
+	m, err := url.Parse(model)
+	if err != nil || m.User != nil {
+		return false
+	}
+	s, err := url.Parse(source)
+	if err != nil || m.Scheme != s.Scheme || !strings.EqualFold(m.Host, s.Host) {
+		return false
+	}
+	modelSegments, sourceSegments := pathSegments(m.Path), pathSegments(s.Path)
+	if len(modelSegments) < len(sourceSegments) {
+		return false
+	}
+	for i, segment := range sourceSegments {
+		if modelSegments[i] != segment {
+			return false
+		}
+	}
+	return true
+}
+
+// pathSegments returns the segments of a cleaned URL path, so dot segments can't leave a source
+func pathSegments(p string) []string {
+	p = strings.Trim(path.Clean("/"+p), "/")
+	if p == "" {
+		return nil
+	}
+	return strings.Split(p, "/")
+}
+
+// modelSourceURL returns the http(s) URL prefix models of a source are requested from
+func modelSourceURL(source string) string {
+	if !strings.HasPrefix(source, "s3://") {
+		return source
+	}
+	s := strings.SplitN(strings.TrimPrefix(source, "s3://"), "/", 2)
+	prefix := ""
+	if len(s) == 2 {
+		prefix = awsURIEncode(s[1])
+	}
+	return "https://" + s3Host(s[0]) + "/" + prefix
+}
+
+// checkModelRedirect only follows redirects to configured model sources
+func checkModelRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= 10 {
+		return fmt.Errorf("stopped after 10 redirects")
+	}
+	if !isAllowedModelURL(req.URL.String()) {
+		return fmt.Errorf("redirect to model source not allowed: %s", req.URL.Host)
+	}
+	return nil
+}
+
+// modelExt returns the file extension of a local or remote model, ignoring URL query strings
+func modelExt(model string) string {
+	if IsRemoteModel(model) {
+		if u, err := url.Parse(model); err == nil {
+			return path.Ext(u.Path)
+		}
+	}
+	return path.Ext(model)
+}
+
+// fetch downloads a remote model from a configured source, verifying its size and an optional sha256 checksum.
+// Models with a known checksum are served from the cache without downloading them again.
+func (c *modelCache) fetch(model string, checksum string) ([]byte, string, error) {
+	if !IsAllowedModelSource(model) {
+		return nil, "", fmt.Errorf("model source not allowed: %s", model)
+	}
+	checksum = strings.ToLower(checksum)
+	if checksum != "" {
+		c.mu.Lock()
+		m, ok := c.models[checksum]
+		if ok {
+			m.lastUsed = time.Now()
+		}
+		c.mu.Unlock()
+		if ok {
+			return m.data, checksum, nil
+		}
+	}
+
+	req, err := newModelRequest(model)
+	if err != nil {
+		return nil, "", err
+	}
+	resp, err := modelClient.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("fetching model: %s", resp.Status)
+	}
+	if resp.ContentLength > MaxModelSize {
+		return nil, "", fmt.Errorf("model exceeds %d bytes", MaxModelSize)
+	}
+	data, err := ioutil.ReadAll(io.LimitReader(resp.Body, MaxModelSize+1))
+	if err != nil {
+		return nil, "", err
+	}
+	if int64(len(data)) > MaxModelSize {
+		return nil, "", fmt.Errorf("model exceeds %d bytes", MaxModelSize)
+	}
+
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+	if checksum != "" && checksum != hash {
+		return nil, "", fmt.Errorf("checksum mismatch: expected %s, got %s", checksum, hash)
+	}
+	if int64(len(data)) > ModelCacheSize {
+		return data, hash, nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.models[hash]; !ok {
+		c.models[hash] = &cachedModel{data: data, size: int64(len(data)), lastUsed: time.Now()}
+		c.size += int64(len(data))
+		c.evict()
+	}
+	return data, hash, nil
+}
+
+// newModelRequest creates the request of a remote model.
+// s3:// URLs are mapped to the bucket endpoint and signed with AWS credentials from the environment
+// (AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY, optional AWS_SESSION_TOKEN and AWS_REGION).
+// Presigned URLs are used as they are.
+func newModelRequest(model string) (*http.Request, error) {
+	if !strings.HasPrefix(model, "s3://") {
+		return http.NewRequest("GET", model, nil)
+	}
+	s := strings.SplitN(strings.TrimPrefix(model, "s3://"), "/", 2)
+	if len(s) != 2 || s[0] == "" || s[1] == "" {
+		return nil, fmt.Errorf("invalid s3 url: %s", model)
+	}
+	region := s3Region()
+	host := s3Host(s[0])
+	key := "/" + awsURIEncode(s[1])
+	req, err := http.NewRequest("GET", "https://"+host+key, nil)
+	if err != nil {
+		return nil, err
+	}
+	accessKey, secretKey := os.Getenv("AWS_ACCESS_KEY_ID"), os.Getenv("AWS_SECRET_ACCESS_KEY")
+	if accessKey == "" || secretKey == "" {
+		// public buckets need no signature
+		return req, nil
+	}
+	signS3Request(req, host, key, region, accessKey, secretKey, os.Getenv("AWS_SESSION_TOKEN"), time.Now().UTC())
+	return req, nil
+}
+
+// s3Region returns the region of s3 buckets from the environment
+func s3Region() string {
+	if region := os.Getenv("AWS_REGION"); region != "" {
+		return region
+	}
+	return "us-east-1"
+}
+
+// s3Host returns the endpoint of a bucket
+func s3Host(bucket string) string {
+	return bucket + ".s3." + s3Region() + ".amazonaws.com"
+}
+
+// signS3Request adds an AWS signature version 4 to a GET request without payload
+func signS3Request(req *http.Request, host, key, region, accessKey, secretKey, token string, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	payload := "UNSIGNED-PAYLOAD"
+
+	headers := map[string]string{"host": host, "x-amz-content-sha256": payload, "x-amz-date": amzDate}
+	names := []string{"host", "x-amz-content-sha256", "x-amz-date"}
+	if token != "" {
+		headers["x-amz-security-token"] = token
+		names = append(names, "x-amz-security-token")
+	}
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+	canonicalRequest := strings.Join([]string{"GET", key, "", canonicalHeaders.String(), signedHeaders, payload}, "\n")
+
+	scope := date + "/" + region + "/s3/aws4_request"
+	hashed := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{"AWS4-HMAC-SHA256", amzDate, scope, hex.EncodeToString(hashed[:])}, "\n")
+	signature := hex.EncodeToString(hmacSHA256(awsSigningKey(secretKey, date, region, "s3"), stringToSign))
+
+	for _, name := range names[1:] {
+		req.Header.Set(name, headers[name])
+	}
+	req.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential="+accessKey+"/"+scope+
+		", SignedHeaders="+signedHeaders+", Signature="+signature)
+}
+
+// awsSigningKey derives the signature version 4 signing key
+func awsSigningKey(secretKey, date, region, service string) []byte {
+	k := hmacSHA256([]byte("AWS4"+secretKey), date)
+	k = hmacSHA256(k, region)
+	k = hmacSHA256(k, service)
+	return hmacSHA256(k, "aws4_request")
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// awsURIEncode encodes an object key as required by AWS, keeping slashes
+func awsURIEncode(key string) string {
+	var b strings.Builder
+	for _, c := range []byte(key) {
+		if (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') ||
+			c == '-' || c == '_' || c == '.' || c == '~' || c == '/' {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
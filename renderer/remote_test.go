@@ -0,0 +1,110 @@
+package renderer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+func TestModelExt(t *testing.T) {
+	assert(t, modelExt("models/Cathedral.glb"), ".glb")
+	assert(t, modelExt("https://bucket.s3.amazonaws.com/a.gltf?X-Amz-Signature=abc.def"), ".gltf")
+	assert(t, modelExt("s3://bucket/dir/model.glb"), ".glb")
+}
+
+func TestIsAllowedModelSource(t *testing.T) {
+	sources := ModelSources
+	defer func() { ModelSources = sources }()
+	ModelSources = []string{"s3://bucket/models/"}
+	assert(t, IsAllowedModelSource("s3://bucket/models/a.glb"), true)
+	assert(t, IsAllowedModelSource("s3://other/models/a.glb"), false)
+	assert(t, IsAllowedModelSource("http://localhost/a.glb"), false)
+	assert(t, IsAllowedModelSource("s3://bucket/models-private/a.glb"), false)
+	assert(t, IsAllowedModelSource("s3://bucket/models/../private/a.glb"), false)
+
+	ModelSources = []string{"https://example.com"}
+	assert(t, IsAllowedModelSource("https://example.com/a.glb"), true)
+	assert(t, IsAllowedModelSource("https://EXAMPLE.com/a.glb"), true)
+	assert(t, IsAllowedModelSource("https://example.com.evil/a.glb"), false)
+	assert(t, IsAllowedModelSource("https://example.com@evil/a.glb"), false)
+	assert(t, IsAllowedModelSource("https://example.com:8443/a.glb"), false)
+	assert(t, IsAllowedModelSource("http://example.com/a.glb"), false)
+}
+
+func TestFetchModel(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "model")
+	}))
+	defer srv.Close()
+	sum := sha256.Sum256([]byte("model"))
+	checksum := hex.EncodeToString(sum[:])
+	sources := ModelSources
+	defer func() { ModelSources = sources }()
+	ModelSources = []string{srv.URL + "/"}
+
+	cache := newModelCache()
+	data, hash, err := cache.fetch(srv.URL+"/a.glb", checksum)
+	assert(t, err, nil)
+	assert(t, string(data), "model")
+	assert(t, hash, checksum)
+
+	_, _, err = cache.fetch(srv.URL+"/a.glb", "0000")
+	assert(t, err != nil, true)
+
+	size := MaxModelSize
+	defer func() { MaxModelSize = size }()
+	MaxModelSize = 2
+	_, _, err = cache.fetch(srv.URL+"/a.glb", "")
+	assert(t, err != nil, true)
+	// cached models are not downloaded again
+	_, _, err = cache.fetch(srv.URL+"/a.glb", checksum)
+	assert(t, err, nil)
+	// but only served to allowed sources
+	ModelSources = nil
+	_, _, err = cache.fetch(srv.URL+"/a.glb", checksum)
+	assert(t, err != nil, true)
+}
+
+func TestFetchModelRedirect(t *testing.T) {
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "model")
+	}))
+	defer other.Close()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, other.URL+"/a.glb", http.StatusFound)
+	}))
+	defer srv.Close()
+	sources := ModelSources
+	defer func() { ModelSources = sources }()
+	ModelSources = []string{srv.URL + "/"}
+
+	cache := newModelCache()
+	_, _, err := cache.fetch(srv.URL+"/a.glb", "")
+	assert(t, err != nil, true)
+	ModelSources = append(ModelSources, other.URL+"/")
+	data, _, err := cache.fetch(srv.URL+"/a.glb", "")
+	assert(t, err, nil)
+	assert(t, string(data), "model")
+}
+
+func TestModelSourceURL(t *testing.T) {
+	region := os.Getenv("AWS_REGION")
+	defer os.Setenv("AWS_REGION", region)
+	os.Setenv("AWS_REGION", "eu-west-1")
+	assert(t, modelSourceURL("s3://bucket/my models/"), "https://bucket.s3.eu-west-1.amazonaws.com/my%20models/")
+	assert(t, modelSourceURL("https://example.com/models/"), "https://example.com/models/")
+}
+
+func TestAWSSigningKey(t *testing.T) {
+	// example from the AWS signature version 4 documentation
+	key := awsSigningKey("wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY", "20120215", "us-east-1", "iam")
+	assert(t, hex.EncodeToString(key), "f4780e2d9f65fa895f9c67b32ce1baf0b0d8a43505a000a1a9e090d414db404d")
+}
+
+func TestAWSURIEncode(t *testing.T) {
+	assert(t, awsURIEncode("models/My Model+1.glb"), "models/My%20Model%2B1.glb")
+}
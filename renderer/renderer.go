@@ -1,7 +1,7 @@
 package renderer
 
 import (
-	"log"
+	"image"
 
 	"github.com/g3n/engine/core"
 	"github.com/g3n/engine/graphic"
@@ -10,42 +10,59 @@ import (
 	"github.com/g3n/engine/material"
 	"github.com/g3n/engine/math32"
 	"github.com/g3n/engine/util/application"
-	"github.com/g3n/engine/util/logger"
 )
 
 // ImageSettings for rendering image
 type ImageSettings struct {
-	saturation   float64
-	contrast     float64
-	brightness   float64
-	blur         float64
-	pixelation   float64
-	invert       bool
-	quality      Quality
-	isNavigating bool
-	encoder      string
+	saturation    float64
+	contrast      float64
+	brightness    float64
+	blur          float64
+	pixelation    float64
+	invert        bool
+	quality       Quality
+	isNavigating  bool
+	encoder       string
+	navEncoder    string // encoder while navigating, empty uses encoder
+	degradation   bandwidthLevel
+	roi           image.Rectangle // region of interest streamed at full resolution, empty streams the whole frame alike
+	roiPixelation int
+}
+
+// getEncoder returns the encoder depending on navigation movement
+func (i *ImageSettings) getEncoder() string {
+	if i.isNavigating && i.navEncoder != "" {
+		return i.navEncoder
+	}
+	return i.encoder
 }
 
 // getJpegQuality returns quality depending on navigation movement
+// and the degradation of a bandwidth limit
 func (i *ImageSettings) getJpegQuality() int {
+	quality := i.quality.jpegQualityStill
 	if i.isNavigating {
-		return i.quality.jpegQualityNav
-	} else {
-		return i.quality.jpegQualityStill
+		quality = i.quality.jpegQualityNav
 	}
+	if i.degradation.maxQuality > 0 && quality > i.degradation.maxQuality {
+		return i.degradation.maxQuality
+	}
+	return quality
 }
 
 // getPixelation returns pixelation depending on navigation movement
-// A global pixelation level will override preset pixelation levels
+// A global pixelation level will override preset pixelation levels, a bandwidth limit may pixelate further
 func (i *ImageSettings) getPixelation() float64 {
+	pixelation := i.quality.pixelationStill
 	if i.pixelation > 1.0 {
-		return i.pixelation
+		pixelation = i.pixelation
+	} else if i.isNavigating {
+		pixelation = i.quality.pixelationNav
 	}
-	if i.isNavigating {
-		return i.quality.pixelationNav
-	} else {
-		return i.quality.pixelationStill
+	if pixelation < i.degradation.minPixelation {
+		return i.degradation.minPixelation
 	}
+	return pixelation
 }
 
 // Quality Image quality settings for still and navigating situations
@@ -65,6 +82,30 @@ var mediumQ Quality = Quality{jpegQualityStill: 80, jpegQualityNav: 60, pixelati
 // low image quality definition
 var lowQ Quality = Quality{jpegQualityStill: 60, jpegQualityNav: 40, pixelationStill: 1.0, pixelationNav: 1.5}
 
+// quality presets by name
+var qualityPresets = map[string]Quality{"high": highQ, "medium": mediumQ, "low": lowQ}
+
+// DefaultQuality is the quality preset of new sessions
+var DefaultQuality = "high"
+
+// DefaultEncoder is the image encoder of new sessions
+var DefaultEncoder = "libjpeg"
+
+// IsQuality returns true for quality preset names
+func IsQuality(name string) bool {
+	_, ok := qualityPresets[name]
+	return ok
+}
+
+// IsEncoder returns true for supported image encoders
+func IsEncoder(name string) bool {
+	switch name {
+	case "libjpeg", "progressive", "jpeg", "png", "png-interlaced":
+		return true
+	}
+	return false
+}
+
 // RenderingApp application settings
 type RenderingApp struct {
 	application.Application
@@ -75,21 +116,83 @@ type RenderingApp struct {
 	Height            int
 	imageSettings     ImageSettings
 	selectionBuffer   map[core.INode][]graphic.GraphicMaterial
+	selectionChanged  bool
 	selectionMaterial material.IMaterial
 	modelpath         string
-	nodeBuffer        map[string]*core.Node
+	nodeBuffer        map[string]core.INode
 	Debug             bool
+	quit              chan struct{}
+	log               *Logger
+	cursor            cursorState
+	showCoordinates   bool
+	snap              snapSettings
+	modelConfig       modelConfig
+	textureMemory     int
+	culling           cullingState
+	loadOptions       LoadOptions
+	textureUpdates    chan func()
+	modelRoot         core.INode
+	sceneUpdates      chan func()
+	commandUpdates    chan func()
+	binding           bindingState
+	heatmap           heatmapState
+	stereo            stereoSettings
+	depth             depthSettings
+	idPass            idPassState
+	gpuPicking        bool
+	selectionStyle    int
+	outlineMaterial   *material.Standard
+	outline           outlineState
+	watch             modelWatch
+	tiles             tileState
+	presence          presenceState
+	annotations       []Annotation
+	frameCaptures     chan func(img *image.RGBA)
+	defaultLight      *light.Point
+	sun               sunState
+	shadows           shadowState
+	study             shadowStudy
+	recording         recordState
+	turntable         turntableState
+	fog               fogSettings
+	effects           effectSettings
+	ground            groundState
+	clipping          clippingState
+	transition        cameraTransition
+	orbitBounds       *math32.Box3 // bounds of the camera target, computed when first needed
+	filters           filterState
+	colorBy           colorByState
+	clashes           clashState
+	drag              dragState
+	memory            memoryState
+	pickCycle         pickCycleState
+	output            outputTransfer
+	hud               hudState
+	compare           compareState
+	delta             deltaState
+	bandwidth         bandwidthState
+	timestamps        timestampState
+	zoom              zoomSettings
+	materialInfos     map[material.IMaterial]MaterialInfo
+	buffers           frameBuffers
+	auditLog          auditLog
+	journalLog        journalState
+	lighting          lightState
+	scaleBar          bool
+	minimap           minimapState
+	frameCache        frameCache
+	quad              quadState
 }
 
 // LoadRenderingApp loads the rendering application
-func LoadRenderingApp(app *RenderingApp, sessionId string, h int, w int, write chan []byte, read chan []byte, modelpath string) {
+func LoadRenderingApp(app *RenderingApp, sessionLog *Logger, h int, w int, write chan []byte, read chan []byte, modelpath string, options LoadOptions) {
 	a, err := application.Create(application.Options{
 		Title:       "g3nServerApplication",
 		Width:       w,
 		Height:      h,
 		Fullscreen:  false,
-		LogPrefix:   sessionId,
-		LogLevel:    logger.DEBUG,
+		LogPrefix:   sessionLog.Session(),
+		LogLevel:    logLevel(),
 		TargetFPS:   30,
 		EnableFlags: true,
 	})
@@ -99,9 +202,18 @@ func LoadRenderingApp(app *RenderingApp, sessionId string, h int, w int, write c
 	}
 
 	app.Application = *a
+	app.log = sessionLog
+	if LinearWorkflow {
+		app.useLinearShaders()
+		app.output = newOutputTransfer(0)
+	}
 	app.Width = w
 	app.Height = h
 
+	settingsMutex.RLock()
+	quality, encoder := qualityPresets[DefaultQuality], DefaultEncoder
+	app.bandwidth = bandwidthState{limit: MaxBandwidth, max: MaxBandwidth}
+	settingsMutex.RUnlock()
 	app.imageSettings = ImageSettings{
 		saturation: 0,
 		brightness: 0,
@@ -109,34 +221,76 @@ func LoadRenderingApp(app *RenderingApp, sessionId string, h int, w int, write c
 		blur:       0,
 		pixelation: 1.0,
 		invert:     false,
-		quality:    highQ,
-		encoder:    "libjpeg",
+		quality:    quality,
+		encoder:    encoder,
 	}
 
 	app.cImagestream = write
 	app.cCommands = read
 	app.modelpath = modelpath
-	app.setupScene()
-	go app.commandLoop()
+	app.loadOptions = options
+	app.gpuPicking = GPUPicking
+	app.quit = make(chan struct{})
+	app.sceneUpdates = make(chan func(), patchQueueSize)
+	app.commandUpdates = make(chan func(), patchQueueSize)
+	app.frameCaptures = make(chan func(img *image.RGBA), patchQueueSize)
+	app.transition.duration = defaultTransition
+	app.compare.split = defaultSplit
+	app.zoom = zoomSettings{sensitivity: DefaultZoomSensitivity, natural: DefaultNaturalScrolling}
+	if err := app.setupScene(); err != nil {
+		// a model which can't be loaded only ends this session
+		app.log.Error("loading scene failed: %v", err)
+		app.Disconnect("load-failed", err.Error())
+	} else {
+		go app.commandLoop()
+	}
 	err = app.Run()
+	close(app.quit)
+	// a failed frame may end the loop before culled nodes were restored
+	app.restoreCulled("", nil)
+	app.auditLog.close()
+	app.journalLog.close()
+	app.memory.close()
 	if err != nil {
 		panic(err)
 	}
 
-	app.Log().Info("app was running for %f seconds\n", app.RunSeconds())
+	app.log.Info("app was running for %f seconds", app.RunSeconds())
+}
+
+// Shutdown notifies the client that the server is closing and stops the render loop.
+func (app *RenderingApp) Shutdown() {
+	app.Disconnect("server-closing", "")
+}
+
+// Disconnect sends a last message to the client and stops the render loop.
+// Closing the window lets the render loop dispose the scene and destroy the GL context.
+func (app *RenderingApp) Disconnect(action string, value string) {
+	if app.Window() == nil {
+		return
+	}
+	app.sendMessageToClient(action, value)
+	app.Window().SetShouldClose(true)
+}
+
+// Notify sends a message to the client of a running app
+func (app *RenderingApp) Notify(action string, value string) {
+	if app.Window() == nil {
+		return
+	}
+	app.sendMessageToClient(action, value)
 }
 
 // setupScene sets up the current scene
-func (app *RenderingApp) setupScene() {
+func (app *RenderingApp) setupScene() error {
 	app.selectionMaterial = material.NewPhong(math32.NewColor("Red"))
 	app.selectionBuffer = make(map[core.INode][]graphic.GraphicMaterial)
-	app.nodeBuffer = make(map[string]*core.Node)
+	app.nodeBuffer = make(map[string]core.INode)
 
 	app.Gl().ClearColor(1.0, 1.0, 1.0, 1.0)
 
-	er := app.loadScene(app.modelpath)
-	if er != nil {
-		log.Fatal(er)
+	if err := app.loadScene(app.modelpath); err != nil {
+		return err
 	}
 
 	amb := light.NewAmbient(&math32.Color{R: 0.2, G: 0.2, B: 0.2}, 1.0)
@@ -147,6 +301,8 @@ func (app *RenderingApp) setupScene() {
 	plight.SetLinearDecay(.001)
 	plight.SetQuadraticDecay(.001)
 	app.Scene().Add(plight)
+	app.defaultLight = plight
+	app.applyLighting()
 
 	app.Camera().GetCamera().SetPosition(12, 1, 5)
 
@@ -155,5 +311,23 @@ func (app *RenderingApp) setupScene() {
 	app.CameraPersp().SetFov(50)
 	app.zoomToExtent()
 	app.Orbit().Enabled = true
+	app.applyOrbitLimits()
+	app.Application.Subscribe(application.OnBeforeRender, app.applyTextureUpdates)
+	app.Application.Subscribe(application.OnBeforeRender, app.applySceneUpdates)
+	app.Application.Subscribe(application.OnBeforeRender, app.animateSun)
+	app.Application.Subscribe(application.OnBeforeRender, app.animateCamera)
+	app.Application.Subscribe(application.OnBeforeRender, app.animateTurntable)
+	app.Application.Subscribe(application.OnBeforeRender, app.constrainCamera)
+	app.Application.Subscribe(application.OnBeforeRender, app.autoClip)
+	app.Application.Subscribe(application.OnBeforeRender, app.manageMemory)
+	app.Application.Subscribe(application.OnBeforeRender, app.cullScene)
+	app.Application.Subscribe(application.OnAfterRender, app.restoreCulled)
 	app.Application.Subscribe(application.OnAfterRender, app.onRender)
+	app.SetInterval(coordinateInterval, nil, app.reportCoordinates)
+	app.SetInterval(presenceInterval, nil, app.reportPresence)
+	app.SetInterval(presenceInterval, nil, app.expirePointers)
+	app.watchModel()
+	// participants of a shared session get to know the new one
+	app.publishPresence(func(p *Presence) {})
+	return nil
 }
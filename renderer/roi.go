@@ -0,0 +1,90 @@
+package renderer
+
+import (
+	"fmt"
+	"image"
+	"strconv"
+	"strings"
+)
+
+// defaultRoiPixelation is the block size of the periphery of a region of interest
+const defaultRoiPixelation = 4
+
+// Roi declares a region of interest as x,y,width,height[,pixelation] in frame pixels from the top left corner.
+// The region is streamed at full resolution while the periphery is pixelated by blocks of the given size (default 4),
+// an empty value streams the whole frame alike again.
+func (app *RenderingApp) Roi(cmd Command) {
+	rect, pixelation, err := parseRoi(cmd.Val)
+	if err != nil {
+		app.sendMessageToClient("roi", err.Error())
+		return
+	}
+	app.imageSettings.roi = rect.Intersect(image.Rect(0, 0, app.Width, app.Height))
+	app.imageSettings.roiPixelation = pixelation
+}
+
+// getRoiPixelation returns the block size of the periphery, at least the pixelation of the whole frame
+func (i *ImageSettings) getRoiPixelation() int {
+	if p := int(i.getPixelation() + 0.5); p > i.roiPixelation {
+		return p
+	}
+	return i.roiPixelation
+}
+
+// pixelateOutside averages blocks of pixels which do not overlap the region, blocks are aligned to the image origin
+func pixelateOutside(img *image.RGBA, roi image.Rectangle, block int) {
+	if block < 2 {
+		return
+	}
+	b := img.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y += block {
+		for x := b.Min.X; x < b.Max.X; x += block {
+			cell := image.Rect(x, y, x+block, y+block).Intersect(b)
+			if cell.Overlaps(roi) {
+				continue
+			}
+			var sum [4]int
+			for cy := cell.Min.Y; cy < cell.Max.Y; cy++ {
+				row := img.Pix[img.PixOffset(cell.Min.X, cy):img.PixOffset(cell.Max.X, cy)]
+				for i, v := range row {
+					sum[i%4] += int(v)
+				}
+			}
+			n := cell.Dx() * cell.Dy()
+			avg := [4]byte{byte(sum[0] / n), byte(sum[1] / n), byte(sum[2] / n), byte(sum[3] / n)}
+			for cy := cell.Min.Y; cy < cell.Max.Y; cy++ {
+				row := img.Pix[img.PixOffset(cell.Min.X, cy):img.PixOffset(cell.Max.X, cy)]
+				for i := 0; i < len(row); i += 4 {
+					copy(row[i:i+4], avg[:])
+				}
+			}
+		}
+	}
+}
+
+// parseRoi parses x,y,width,height[,pixelation], an empty value returns an empty region
+func parseRoi(value string) (image.Rectangle, int, error) {
+	if strings.TrimSpace(value) == "" {
+		return image.Rectangle{}, 0, nil
+	}
+	s := strings.Split(value, ",")
+	if len(s) != 4 && len(s) != 5 {
+		return image.Rectangle{}, 0, fmt.Errorf("invalid region %s, expected x,y,width,height[,pixelation]", value)
+	}
+	v := make([]int, len(s))
+	for i := range s {
+		var err error
+		v[i], err = strconv.Atoi(strings.TrimSpace(s[i]))
+		if err != nil || v[i] < 0 {
+			return image.Rectangle{}, 0, fmt.Errorf("invalid region %s, expected x,y,width,height[,pixelation]", value)
+		}
+	}
+	if v[2] == 0 || v[3] == 0 {
+		return image.Rectangle{}, 0, fmt.Errorf("empty region %s", value)
+	}
+	pixelation := defaultRoiPixelation
+	if len(v) == 5 {
+		pixelation = getValueInRange(v[4], 2, 16)
+	}
+	return image.Rect(v[0], v[1], v[0]+v[2], v[1]+v[3]), pixelation, nil
+}
@@ -0,0 +1,47 @@
+package renderer
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestParseRoi(t *testing.T) {
+	rect, pixelation, err := parseRoi("10, 20,100,50")
+	assert(t, err, nil)
+	assert(t, rect, image.Rect(10, 20, 110, 70))
+	assert(t, pixelation, defaultRoiPixelation)
+	_, pixelation, _ = parseRoi("0,0,10,10,40")
+	assert(t, pixelation, 16)
+	rect, _, err = parseRoi("")
+	assert(t, err, nil)
+	assert(t, rect, image.Rectangle{})
+	_, _, err = parseRoi("0,0,0,10")
+	assert(t, err != nil, true)
+	_, _, err = parseRoi("-1,0,10,10")
+	assert(t, err != nil, true)
+	_, _, err = parseRoi("0,0,10")
+	assert(t, err != nil, true)
+}
+
+func TestRoiPixelation(t *testing.T) {
+	i := ImageSettings{quality: highQ, roiPixelation: 4}
+	assert(t, i.getRoiPixelation(), 4)
+	i.pixelation = 6
+	assert(t, i.getRoiPixelation(), 6)
+}
+
+func TestPixelateOutside(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 4; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(x * 60), G: uint8(y * 60), A: 255})
+		}
+	}
+	pixelateOutside(img, image.Rect(0, 0, 1, 1), 2)
+	// the block overlapping the region stays sharp
+	assert(t, img.RGBAAt(1, 1), color.RGBA{R: 60, G: 60, A: 255})
+	assert(t, img.RGBAAt(2, 0), color.RGBA{R: 150, G: 30, A: 255})
+	assert(t, img.RGBAAt(3, 1), color.RGBA{R: 150, G: 30, A: 255})
+	assert(t, img.RGBAAt(3, 3), color.RGBA{R: 150, G: 150, A: 255})
+}
@@ -0,0 +1,88 @@
+package renderer
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"strconv"
+
+	"github.com/g3n/engine/math32"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+// scaleBarMargin is the distance of the scale bar to the bottom left corner in pixels
+const scaleBarMargin = 20
+
+// Scalebar shows a scale bar at the bottom left of the frame measuring lengths at the camera target, an empty value toggles
+func (app *RenderingApp) Scalebar(cmd Command) {
+	switch cmd.Val {
+	case "":
+		app.scaleBar = !app.scaleBar
+	case "on":
+		app.scaleBar = true
+	case "off":
+		app.scaleBar = false
+	default:
+		app.sendMessageToClient("scalebar", "invalid value "+cmd.Val)
+	}
+}
+
+// viewHeight returns the height of the view at the distance of the camera target in model units
+func (app *RenderingApp) viewHeight() float32 {
+	cam := app.Camera().GetCamera()
+	position, target := cam.Position(), cam.Target()
+	return toModelUnits(2 * position.DistanceTo(&target) * math32.Tan(math32.DegToRad(app.CameraPersp().Fov())/2))
+}
+
+// DrawScaleBar draws a bar of a round length at the bottom left of the image, the view height is given in model units
+func DrawScaleBar(img *image.RGBA, viewHeight float32, unit string) *image.RGBA {
+	b := img.Bounds()
+	if viewHeight <= 0 || b.Dy() == 0 {
+		return img
+	}
+	perPixel := float64(viewHeight) / float64(b.Dy())
+	length := niceLength(perPixel * float64(b.Dx()) / 4)
+	width := int(math.Round(length / perPixel))
+	if width < 2 {
+		return img
+	}
+	x0, y0 := b.Min.X+scaleBarMargin, b.Max.Y-scaleBarMargin
+	black := color.RGBA{A: 255}
+	for x := x0; x < x0+width; x++ {
+		for y := y0 - 3; y < y0; y++ {
+			img.SetRGBA(x, y, black)
+		}
+	}
+	// end ticks
+	for y := y0 - 9; y < y0; y++ {
+		img.SetRGBA(x0, y, black)
+		img.SetRGBA(x0+width-1, y, black)
+	}
+	d := font.Drawer{Dst: img, Src: image.Black, Face: basicfont.Face7x13, Dot: fixed.P(x0+4, y0-8)}
+	d.DrawString(formatScale(length, unit))
+	return img
+}
+
+// niceLength returns the largest length of 1, 2 or 5 times a power of ten not exceeding max
+func niceLength(max float64) float64 {
+	if max <= 0 {
+		return 0
+	}
+	p := math.Pow(10, math.Floor(math.Log10(max)))
+	for _, f := range []float64{5, 2, 1} {
+		if f*p <= max {
+			return f * p
+		}
+	}
+	return p
+}
+
+// formatScale formats a scale bar length given in model units without trailing zeros
+func formatScale(length float64, unit string) string {
+	if unit == "ft-in" {
+		return formatLength(float32(length), unit)
+	}
+	return strconv.FormatFloat(length, 'g', -1, 32) + " " + unit
+}
@@ -0,0 +1,34 @@
+package renderer
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestNiceLength(t *testing.T) {
+	assert(t, niceLength(7), float64(5))
+	assert(t, niceLength(3), float64(2))
+	assert(t, niceLength(1.5), float64(1))
+	assert(t, niceLength(120), float64(100))
+	assert(t, niceLength(0), float64(0))
+	assert(t, formatScale(niceLength(0.3), "m"), "0.2 m")
+}
+
+func TestFormatScale(t *testing.T) {
+	assert(t, formatScale(500, "mm"), "500 mm")
+	assert(t, formatScale(5, "m"), "5 m")
+	assert(t, formatScale(10, "ft-in"), "10' 0\"")
+}
+
+func TestDrawScaleBar(t *testing.T) {
+	// 100 pixels show 10 m, a quarter of the width is 5 m or 50 pixels
+	img := DrawScaleBar(image.NewRGBA(image.Rect(0, 0, 200, 100)), 10, "m")
+	black := color.RGBA{A: 255}
+	assert(t, img.RGBAAt(scaleBarMargin, 100-scaleBarMargin-1), black)
+	assert(t, img.RGBAAt(scaleBarMargin+49, 100-scaleBarMargin-1), black)
+	assert(t, img.RGBAAt(scaleBarMargin+50, 100-scaleBarMargin-1), color.RGBA{})
+
+	empty := image.NewRGBA(image.Rect(0, 0, 200, 100))
+	assert(t, DrawScaleBar(empty, 0, "m").RGBAAt(scaleBarMargin, 100-scaleBarMargin-1), color.RGBA{})
+}
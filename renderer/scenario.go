@@ -0,0 +1,140 @@
+package renderer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ScenarioPath is the directory holding server-side scenarios
+var ScenarioPath = "scenarios/"
+
+// scenario is a named sequence of commands, optionally shown on another model
+type scenario struct {
+	Model    string    `json:"model"` // file next to the session model or allowed remote model, empty keeps the model
+	Commands []Command `json:"commands"`
+}
+
+// Scenario runs the commands of scenarios/name.json in order, e.g. for kiosk setups:
+//
+//	{"model": "Building.gltf", "commands": [{"cmd": "Filter", "val": "hide userdata.category == MEP"}, {"cmd": "View", "val": "top"}]}
+//
+// The model is loaded before the first command.
+func (app *RenderingApp) Scenario(cmd Command) {
+	s, err := loadScenario(cmd.Val)
+	if err == nil && s.Model != "" {
+		err = app.switchModel(s.Model)
+	}
+	if err != nil {
+		app.log.Warn("scenario: %v", err)
+		app.sendMessageToClient("scenario", err.Error())
+		return
+	}
+	for _, c := range s.Commands {
+		app.runCommand(c)
+	}
+	app.sendMessageToClient("scenario", "ok")
+}
+
+// Scenarios sends the names of all scenarios
+func (app *RenderingApp) Scenarios(cmd Command) {
+	names, err := scenarioNames()
+	if err != nil {
+		app.sendMessageToClient("scenarios", err.Error())
+		return
+	}
+	app.sendDataToClient("scenarios", names)
+}
+
+// switchModel loads another model on the render thread and waits until it is shown
+func (app *RenderingApp) switchModel(model string) error {
+	path, err := resolveModel(app.modelpath, model)
+	if err != nil {
+		return err
+	}
+	done := make(chan error, 1)
+	update := func() {
+		previous := app.modelpath
+		app.resetSelection()
+		if err := app.loadScene(path); err != nil {
+			done <- err
+			return
+		}
+		app.modelpath = path
+		if info, err := os.Stat(path); err == nil {
+			app.watch = modelWatch{loaded: info, seen: info}
+		}
+		if previous != path {
+			app.zoomToExtent()
+		}
+		app.audit("model", path, true)
+		done <- nil
+	}
+	select {
+	case app.sceneUpdates <- update:
+	case <-app.quit:
+		return fmt.Errorf("session closed")
+	}
+	select {
+	case err := <-done:
+		return err
+	case <-app.quit:
+		return fmt.Errorf("session closed")
+	}
+}
+
+// resolveModel returns the path of a model next to the session model or an allowed remote model
+func resolveModel(sessionModel string, model string) (string, error) {
+	if IsRemoteModel(model) {
+		if !IsAllowedModelSource(model) {
+			return "", fmt.Errorf("model source not allowed: %s", model)
+		}
+		return model, nil
+	}
+	if IsRemoteModel(sessionModel) {
+		return "", fmt.Errorf("model %s is not next to the remote session model", model)
+	}
+	clean := filepath.Clean(model)
+	if filepath.IsAbs(clean) || strings.HasPrefix(clean, "..") {
+		return "", fmt.Errorf("invalid model %s", model)
+	}
+	return filepath.Join(filepath.Dir(sessionModel), clean), nil
+}
+
+// loadScenario reads a scenario by name
+func loadScenario(name string) (scenario, error) {
+	var s scenario
+	data, err := ioutil.ReadFile(filepath.Join(ScenarioPath, filepath.Base(name)+".json"))
+	if err != nil {
+		return s, err
+	}
+	if err := json.Unmarshal(data, &s); err != nil {
+		return s, fmt.Errorf("invalid scenario %s: %v", name, err)
+	}
+	for _, c := range s.Commands {
+		if c.Cmd == "Scenario" {
+			return s, fmt.Errorf("scenario %s must not run scenarios", name)
+		}
+	}
+	return s, nil
+}
+
+// scenarioNames returns the names of all scenarios sorted
+func scenarioNames() ([]string, error) {
+	files, err := ioutil.ReadDir(ScenarioPath)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	names := []string{}
+	for _, f := range files {
+		if !f.IsDir() && filepath.Ext(f.Name()) == ".json" {
+			names = append(names, strings.TrimSuffix(f.Name(), ".json"))
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
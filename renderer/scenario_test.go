@@ -0,0 +1,54 @@
+package renderer
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadScenario(t *testing.T) {
+	dir, err := ioutil.TempDir("", "scenarios")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	defer func(path string) { ScenarioPath = path }(ScenarioPath)
+	ScenarioPath = dir
+
+	ioutil.WriteFile(filepath.Join(dir, "kiosk.json"), []byte(`{"model": "Building.gltf", "commands": [{"cmd": "View", "val": "top"}, {"cmd": "Quality", "val": "2"}]}`), 0644)
+	ioutil.WriteFile(filepath.Join(dir, "loop.json"), []byte(`{"commands": [{"cmd": "Scenario", "val": "loop"}]}`), 0644)
+
+	s, err := loadScenario("kiosk")
+	assert(t, err, nil)
+	assert(t, s.Model, "Building.gltf")
+	assert(t, len(s.Commands), 2)
+	assert(t, s.Commands[0], Command{Cmd: "View", Val: "top"})
+
+	if _, err = loadScenario("loop"); err == nil {
+		t.Error("recursive scenario accepted")
+	}
+	if _, err = loadScenario("missing"); err == nil {
+		t.Error("missing scenario loaded")
+	}
+
+	names, err := scenarioNames()
+	assert(t, err, nil)
+	assert(t, len(names), 2)
+	assert(t, names[0], "kiosk")
+}
+
+func TestResolveModel(t *testing.T) {
+	path, err := resolveModel("models/Building.gltf", "Saw.glb")
+	assert(t, err, nil)
+	assert(t, path, filepath.Join("models", "Saw.glb"))
+	if _, err = resolveModel("models/Building.gltf", "../secret.glb"); err == nil {
+		t.Error("model outside the model directory accepted")
+	}
+	if _, err = resolveModel("https://example.com/a.glb", "Saw.glb"); err == nil {
+		t.Error("local model next to remote model accepted")
+	}
+	if _, err = resolveModel("models/Building.gltf", "https://example.com/b.glb"); err == nil {
+		t.Error("remote model from unknown source accepted")
+	}
+}
@@ -0,0 +1,126 @@
+package renderer
+
+import (
+	"path/filepath"
+
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/graphic"
+	"github.com/g3n/engine/material"
+)
+
+// SceneGraph is the model of a session as the renderer sees it
+type SceneGraph struct {
+	Model     string          `json:"model"`
+	Unit      string          `json:"unit"`
+	Root      *SceneNode      `json:"root"`
+	Materials []SceneMaterial `json:"materials"`
+}
+
+// SceneNode is a node of the scene graph with its transforms, materials and user data
+type SceneNode struct {
+	ID        string      `json:"id"`
+	Type      string      `json:"type"` // node, mesh, lines or points
+	Visible   bool        `json:"visible"`
+	Position  [3]float32  `json:"position"`
+	Rotation  [4]float32  `json:"rotation"` // quaternion x, y, z, w
+	Scale     [3]float32  `json:"scale"`
+	World     [16]float32 `json:"world"`               // column major world matrix
+	Materials []int       `json:"materials,omitempty"` // indices into the materials of the scene graph
+	UserData  interface{} `json:"userdata,omitempty"`
+	Children  []SceneNode `json:"children,omitempty"`
+}
+
+// SceneMaterial is a material referenced by scene nodes
+type SceneMaterial struct {
+	Type        string      `json:"type"`            // standard, physical or other
+	Color       *[3]float32 `json:"color,omitempty"` // known for standard materials only
+	DoubleSided bool        `json:"doubleSided"`
+	Transparent bool        `json:"transparent"`
+}
+
+// SceneGraph returns the scene graph of the model, read on the render thread before the next frame.
+// It returns false if the session has ended.
+func (app *RenderingApp) SceneGraph() (SceneGraph, bool) {
+	result := make(chan SceneGraph, 1)
+	select {
+	case app.sceneUpdates <- func() { result <- app.sceneGraph() }:
+	case <-app.quit:
+		return SceneGraph{}, false
+	}
+	select {
+	case graph := <-result:
+		return graph, true
+	case <-app.quit:
+		return SceneGraph{}, false
+	}
+}
+
+// sceneGraph converts the model into a scene graph
+func (app *RenderingApp) sceneGraph() SceneGraph {
+	graph := SceneGraph{Model: filepath.Base(app.modelpath), Unit: app.modelConfig.Unit, Materials: []SceneMaterial{}}
+	if app.modelRoot == nil {
+		return graph
+	}
+	materials := make(map[material.IMaterial]int)
+	root := sceneNode(app.modelRoot, materials, &graph.Materials)
+	graph.Root = &root
+	return graph
+}
+
+// sceneNode converts a node and its children, materials are added once
+func sceneNode(inode core.INode, materials map[material.IMaterial]int, list *[]SceneMaterial) SceneNode {
+	node := inode.GetNode()
+	p, q, s := node.Position(), node.Quaternion(), node.Scale()
+	matrixWorld := node.MatrixWorld()
+	n := SceneNode{
+		ID:       node.Name(),
+		Type:     "node",
+		Visible:  node.Visible(),
+		Position: [3]float32{p.X, p.Y, p.Z},
+		Rotation: [4]float32{q.X, q.Y, q.Z, q.W},
+		Scale:    [3]float32{s.X, s.Y, s.Z},
+		World:    [16]float32(matrixWorld),
+		UserData: node.UserData(),
+	}
+	switch inode.(type) {
+	case *graphic.Mesh:
+		n.Type = "mesh"
+	case *graphic.Lines, *graphic.LineStrip:
+		n.Type = "lines"
+	case *graphic.Points:
+		n.Type = "points"
+	}
+	if gnode, ok := inode.(graphic.IGraphic); ok {
+		for _, gm := range gnode.GetGraphic().Materials() {
+			imat := gm.IMaterial()
+			idx, found := materials[imat]
+			if !found {
+				idx = len(*list)
+				*list = append(*list, sceneMaterial(imat))
+				materials[imat] = idx
+			}
+			n.Materials = append(n.Materials, idx)
+		}
+	}
+	for _, child := range node.Children() {
+		n.Children = append(n.Children, sceneNode(child, materials, list))
+	}
+	return n
+}
+
+// sceneMaterial describes a material
+func sceneMaterial(imat material.IMaterial) SceneMaterial {
+	m := SceneMaterial{Type: "other"}
+	switch mat := imat.(type) {
+	case *material.Standard:
+		c := mat.AmbientColor()
+		m.Type, m.Color = "standard", &[3]float32{c.R, c.G, c.B}
+	case *material.Physical:
+		m.Type = "physical"
+	}
+	if mat := imat.GetMaterial(); mat != nil {
+		m.DoubleSided = mat.Side() == material.SideDouble
+		m.Transparent = mat.Transparent()
+	}
+	return m
+}
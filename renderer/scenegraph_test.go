@@ -0,0 +1,24 @@
+package renderer
+
+import (
+	"testing"
+
+	"github.com/g3n/engine/material"
+	"github.com/g3n/engine/math32"
+)
+
+func TestSceneMaterial(t *testing.T) {
+	mat := material.NewStandard(&math32.Color{R: 1, G: 0.5, B: 0})
+	mat.SetSide(material.SideDouble)
+	m := sceneMaterial(mat)
+	assert(t, m.Type, "standard")
+	assert(t, *m.Color, [3]float32{1, 0.5, 0})
+	assert(t, m.DoubleSided, true)
+	assert(t, m.Transparent, false)
+
+	m = sceneMaterial(material.NewPhysical())
+	assert(t, m.Type, "physical")
+	if m.Color != nil {
+		t.Error("physical material has a color")
+	}
+}
@@ -0,0 +1,409 @@
+package renderer
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/graphic"
+	"github.com/g3n/engine/material"
+	"github.com/g3n/engine/math32"
+	lua "github.com/yuin/gopher-lua"
+)
+
+// ScriptPath is the directory holding server-side scene scripts
+var ScriptPath = "scripts/"
+
+// scriptFilter is a single condition of a find call
+type scriptFilter struct {
+	field string
+	op    string
+	value string
+}
+
+// scriptTimeout limits the run time of a script
+const scriptTimeout = 5 * time.Second
+
+// Script runs a scene automation script in an embedded Lua interpreter.
+// The value is either the Lua source or @name to run scripts/name.lua
+//
+// Scripts work on node ids and have no access to files or the os:
+//
+//	find([condition]) -> ids    nodes matching e.g. "userdata.category == furniture and name ~ /0/"
+//	userdata(id[, key]) -> value
+//	select(ids) | selection() -> ids | clearselection()
+//	hide(ids) | show(ids) | color(ids, name|#rrggbb)
+//	view(name) | zoomextent() | focus() | fov(degrees)
+//	print(...)                  sends a script message to the client
+func (app *RenderingApp) Script(cmd Command) {
+	src := cmd.Val
+	if strings.HasPrefix(src, "@") {
+		data, err := ioutil.ReadFile(filepath.Join(ScriptPath, filepath.Base(src[1:])+".lua"))
+		if err != nil {
+			app.log.Warn("script: %v", err)
+			app.sendMessageToClient("script", err.Error())
+			return
+		}
+		src = string(data)
+	}
+	if err := app.runScript(src); err != nil {
+		app.log.Warn("script: %v", err)
+		app.sendMessageToClient("script", err.Error())
+		return
+	}
+	app.sendMessageToClient("script", "ok")
+}
+
+// runScript executes a script in a sandboxed interpreter which is stopped after scriptTimeout
+func (app *RenderingApp) runScript(src string) error {
+	L := newScriptState()
+	defer L.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), scriptTimeout)
+	defer cancel()
+	L.SetContext(ctx)
+	for name, f := range app.scriptFunctions() {
+		L.SetGlobal(name, L.NewFunction(f))
+	}
+	return L.DoString(src)
+}
+
+// newScriptState returns an interpreter with the base, table, string and math libraries only
+func newScriptState() *lua.LState {
+	L := lua.NewState(lua.Options{SkipOpenLibs: true})
+	libs := []struct {
+		name string
+		open lua.LGFunction
+	}{
+		{lua.BaseLibName, lua.OpenBase},
+		{lua.TabLibName, lua.OpenTable},
+		{lua.StringLibName, lua.OpenString},
+		{lua.MathLibName, lua.OpenMath},
+	}
+	for _, lib := range libs {
+		L.Push(L.NewFunction(lib.open))
+		L.Push(lua.LString(lib.name))
+		L.Call(1, 0)
+	}
+	for _, name := range []string{"dofile", "loadfile", "load", "loadstring", "require", "module"} {
+		L.SetGlobal(name, lua.LNil)
+	}
+	return L
+}
+
+// scriptFunctions returns the scene functions available to scripts
+func (app *RenderingApp) scriptFunctions() map[string]lua.LGFunction {
+	return map[string]lua.LGFunction{
+		"find": func(L *lua.LState) int {
+			var filters []scriptFilter
+			if condition := L.OptString(1, ""); strings.TrimSpace(condition) != "" {
+				tokens, err := tokenizeScriptLine(condition)
+				if err == nil {
+					filters, err = parseScriptFilters(tokens)
+				}
+				if err != nil {
+					L.ArgError(1, err.Error())
+				}
+			}
+			var nodes []core.INode
+			if app.modelRoot != nil {
+				nodes = findNodes(app.modelRoot, filters)
+			}
+			L.Push(scriptIds(L, nodes))
+			return 1
+		},
+		"userdata": func(L *lua.LState) int {
+			node := app.scriptNodes(L, 1)[0].GetNode()
+			data := node.UserData()
+			if key := L.OptString(2, ""); key != "" {
+				for _, k := range strings.Split(key, ".") {
+					m, _ := data.(map[string]interface{})
+					data = m[k]
+				}
+			}
+			L.Push(toScriptValue(L, data))
+			return 1
+		},
+		"select": func(L *lua.LState) int {
+			nodes := app.scriptNodes(L, 1)
+			app.resetSelection()
+			forEachGraphic(nodes, app.changeNodeMaterial)
+			return 0
+		},
+		"selection": func(L *lua.LState) int {
+			ids := L.NewTable()
+			for _, name := range app.selectedNames() {
+				ids.Append(lua.LString(name))
+			}
+			L.Push(ids)
+			return 1
+		},
+		"clearselection": func(L *lua.LState) int {
+			app.resetSelection()
+			return 0
+		},
+		"hide": func(L *lua.LState) int {
+			for _, inode := range app.scriptNodes(L, 1) {
+				inode.GetNode().SetVisible(false)
+			}
+			return 0
+		},
+		"show": func(L *lua.LState) int {
+			for _, inode := range app.scriptNodes(L, 1) {
+				inode.GetNode().SetVisible(true)
+			}
+			return 0
+		},
+		"color": func(L *lua.LState) int {
+			nodes := app.scriptNodes(L, 1)
+			color, err := parseColor(L.CheckString(2))
+			if err != nil {
+				L.ArgError(2, err.Error())
+			}
+			mat := material.NewStandard(color)
+			forEachGraphic(nodes, func(inode core.INode) {
+				gfx := inode.(graphic.IGraphic).GetGraphic()
+				gfx.ClearMaterials()
+				gfx.AddMaterial(inode.(graphic.IGraphic), mat, 0, 0)
+			})
+			return 0
+		},
+		"view": func(L *lua.LState) int {
+			app.setCamera(L.CheckString(1))
+			return 0
+		},
+		"zoomextent": func(L *lua.LState) int {
+			app.zoomToExtent()
+			return 0
+		},
+		"focus": func(L *lua.LState) int {
+			app.focusOnSelection()
+			return 0
+		},
+		"fov": func(L *lua.LState) int {
+			app.CameraPersp().SetFov(float32(getValueInRange(L.CheckInt(1), 5, 120)))
+			return 0
+		},
+		"print": func(L *lua.LState) int {
+			s := make([]string, L.GetTop())
+			for i := range s {
+				s[i] = L.ToStringMeta(L.Get(i + 1)).String()
+			}
+			app.sendMessageToClient("script", strings.Join(s, "\t"))
+			return 0
+		},
+	}
+}
+
+// scriptNodes returns the nodes of a script argument holding a node id or a table of node ids
+func (app *RenderingApp) scriptNodes(L *lua.LState, n int) []core.INode {
+	var ids []string
+	switch v := L.CheckAny(n).(type) {
+	case lua.LString:
+		ids = []string{string(v)}
+	case *lua.LTable:
+		v.ForEach(func(_, id lua.LValue) {
+			ids = append(ids, id.String())
+		})
+	default:
+		L.ArgError(n, "node id or table of node ids expected")
+	}
+	nodes := make([]core.INode, 0, len(ids))
+	for _, id := range ids {
+		inode, ok := app.nodeBuffer[id]
+		if !ok {
+			L.ArgError(n, "unknown node "+id)
+		}
+		nodes = append(nodes, inode)
+	}
+	return nodes
+}
+
+// scriptIds returns the ids of nodes as Lua table
+func scriptIds(L *lua.LState, nodes []core.INode) *lua.LTable {
+	ids := L.CreateTable(len(nodes), 0)
+	for _, inode := range nodes {
+		ids.Append(lua.LString(inode.GetNode().Name()))
+	}
+	return ids
+}
+
+// toScriptValue converts decoded json user data to a Lua value
+func toScriptValue(L *lua.LState, data interface{}) lua.LValue {
+	switch v := data.(type) {
+	case string:
+		return lua.LString(v)
+	case float64:
+		return lua.LNumber(v)
+	case bool:
+		return lua.LBool(v)
+	case []interface{}:
+		t := L.CreateTable(len(v), 0)
+		for _, e := range v {
+			t.Append(toScriptValue(L, e))
+		}
+		return t
+	case map[string]interface{}:
+		t := L.CreateTable(0, len(v))
+		for k, e := range v {
+			t.RawSetString(k, toScriptValue(L, e))
+		}
+		return t
+	}
+	return lua.LNil
+}
+
+// tokenizeScriptLine splits a line by whitespace, keeping quoted strings together
+func tokenizeScriptLine(line string) ([]string, error) {
+	var tokens []string
+	var current strings.Builder
+	inToken := false
+	var quote rune
+	for _, r := range line {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '"' || r == '\'':
+			quote = r
+			inToken = true
+		case r == ' ' || r == '\t':
+			if inToken {
+				tokens = append(tokens, current.String())
+				current.Reset()
+				inToken = false
+			}
+		default:
+			current.WriteRune(r)
+			inToken = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated string")
+	}
+	if inToken {
+		tokens = append(tokens, current.String())
+	}
+	return tokens, nil
+}
+
+// parseScriptFilters parses conditions joined by "and"
+func parseScriptFilters(args []string) ([]scriptFilter, error) {
+	var filters []scriptFilter
+	for len(args) > 0 {
+		if len(args) < 3 {
+			return nil, fmt.Errorf("incomplete condition")
+		}
+		f := scriptFilter{field: args[0], op: args[1], value: args[2]}
+		if f.op != "==" && f.op != "!=" && f.op != "~" {
+			return nil, fmt.Errorf("unknown operator %s", f.op)
+		}
+		if f.field != "name" && !strings.HasPrefix(f.field, "userdata.") {
+			return nil, fmt.Errorf("unknown field %s", f.field)
+		}
+		filters = append(filters, f)
+		args = args[3:]
+		if len(args) > 0 {
+			if strings.ToLower(args[0]) != "and" {
+				return nil, fmt.Errorf("expected and, got %s", args[0])
+			}
+			args = args[1:]
+		}
+	}
+	return filters, nil
+}
+
+// matches returns true if the node fulfills the condition
+func (f scriptFilter) matches(node *core.Node) bool {
+	var value string
+	var found bool
+	if f.field == "name" {
+		value, found = node.Name(), true
+	} else {
+		value, found = getUserDataValue(node.UserData(), strings.TrimPrefix(f.field, "userdata."))
+	}
+	switch f.op {
+	case "==":
+		return found && value == f.value
+	case "!=":
+		return !found || value != f.value
+	default:
+		return found && strings.Contains(value, f.value)
+	}
+}
+
+// getUserDataValue returns a user data entry by dotted key as string
+func getUserDataValue(data interface{}, key string) (string, bool) {
+	for _, k := range strings.Split(key, ".") {
+		m, ok := data.(map[string]interface{})
+		if !ok {
+			return "", false
+		}
+		data, ok = m[k]
+		if !ok {
+			return "", false
+		}
+	}
+	return fmt.Sprintf("%v", data), true
+}
+
+// findNodes returns all nodes below root matching every filter
+func findNodes(root core.INode, filters []scriptFilter) []core.INode {
+	var nodes []core.INode
+	for _, child := range root.GetNode().Children() {
+		match := true
+		for _, f := range filters {
+			if !f.matches(child.GetNode()) {
+				match = false
+				break
+			}
+		}
+		if match {
+			nodes = append(nodes, child)
+		}
+		nodes = append(nodes, findNodes(child, filters)...)
+	}
+	return nodes
+}
+
+// forEachGraphic calls f once for every renderable graphic within the given nodes and their children
+func forEachGraphic(nodes []core.INode, f func(inode core.INode)) {
+	visited := make(map[core.INode]bool)
+	var walk func(nodes []core.INode)
+	walk = func(nodes []core.INode) {
+		for _, inode := range nodes {
+			if visited[inode] {
+				continue
+			}
+			visited[inode] = true
+			if gnode, ok := inode.(graphic.IGraphic); ok && gnode.Renderable() {
+				f(inode)
+			}
+			walk(inode.GetNode().Children())
+		}
+	}
+	walk(nodes)
+}
+
+// parseColor parses a web color name or a #rrggbb hex value
+func parseColor(value string) (*math32.Color, error) {
+	if strings.HasPrefix(value, "#") {
+		hex, err := strconv.ParseUint(value[1:], 16, 32)
+		if err != nil || len(value) != 7 {
+			return nil, fmt.Errorf("invalid color %s", value)
+		}
+		return math32.NewColorHex(uint(hex)), nil
+	}
+	color := math32.NewColor(value)
+	if color == nil {
+		return nil, fmt.Errorf("invalid color %s", value)
+	}
+	return color, nil
+}
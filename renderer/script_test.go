@@ -0,0 +1,97 @@
+package renderer
+
+import (
+	"testing"
+
+	"github.com/g3n/engine/core"
+)
+
+func TestTokenizeScriptLine(t *testing.T) {
+	tokens, err := tokenizeScriptLine(`select userdata.category == 'office furniture'`)
+	assert(t, err, nil)
+	assert(t, len(tokens), 4)
+	assert(t, tokens[3], "office furniture")
+
+	_, err = tokenizeScriptLine(`select name == "open`)
+	if err == nil {
+		t.Error("unterminated string accepted")
+	}
+}
+
+func TestParseScriptFilters(t *testing.T) {
+	filters, err := parseScriptFilters([]string{"name", "~", "/0", "and", "userdata.level", "!=", "2"})
+	assert(t, err, nil)
+	assert(t, len(filters), 2)
+	_, err = parseScriptFilters([]string{"name", "<", "x"})
+	if err == nil {
+		t.Error("unknown operator accepted")
+	}
+	_, err = parseScriptFilters([]string{"name", "=="})
+	if err == nil {
+		t.Error("incomplete condition accepted")
+	}
+}
+
+func TestFindNodes(t *testing.T) {
+	root := core.NewNode()
+	chair := core.NewNode()
+	chair.SetName("/0/1")
+	chair.SetUserData(map[string]interface{}{"category": "furniture", "props": map[string]interface{}{"level": 2.0}})
+	wall := core.NewNode()
+	wall.SetName("/0/2")
+	wall.SetUserData(map[string]interface{}{"category": "wall"})
+	root.Add(chair)
+	root.Add(wall)
+
+	nodes := findNodes(root, []scriptFilter{{field: "userdata.category", op: "==", value: "furniture"}})
+	assert(t, len(nodes), 1)
+	assert(t, nodes[0].GetNode(), chair)
+
+	nodes = findNodes(root, []scriptFilter{{field: "userdata.props.level", op: "==", value: "2"}})
+	assert(t, len(nodes), 1)
+
+	nodes = findNodes(root, []scriptFilter{{field: "name", op: "~", value: "/0/"}})
+	assert(t, len(nodes), 2)
+}
+
+func TestRunScript(t *testing.T) {
+	root := core.NewNode()
+	chair := core.NewNode()
+	chair.SetName("/0/1")
+	chair.SetUserData(map[string]interface{}{"category": "furniture", "props": map[string]interface{}{"level": 2.0}})
+	wall := core.NewNode()
+	wall.SetName("/0/2")
+	root.Add(chair)
+	root.Add(wall)
+	app := RenderingApp{modelRoot: root, nodeBuffer: map[string]core.INode{"/0/1": chair, "/0/2": wall}}
+
+	err := app.runScript(`
+		local ids = find("userdata.category == furniture")
+		assert(#ids == 1 and ids[1] == "/0/1")
+		assert(userdata(ids[1], "props.level") == 2)
+		assert(userdata("/0/2", "category") == nil)
+		hide(find())
+		show("/0/2")`)
+	assert(t, err, nil)
+	assert(t, chair.Visible(), false)
+	assert(t, wall.Visible(), true)
+
+	assert(t, app.runScript(`hide("/0/3")`) != nil, true)
+	assert(t, app.runScript(`find("name <")`) != nil, true)
+	// no access to files or the os
+	assert(t, app.runScript(`dofile("/etc/passwd")`) != nil, true)
+	assert(t, app.runScript(`os.exit(1)`) != nil, true)
+	assert(t, app.runScript(`io.open("/etc/passwd")`) != nil, true)
+}
+
+func TestParseColor(t *testing.T) {
+	c, err := parseColor("#ff0000")
+	assert(t, err, nil)
+	assert(t, c.R, float32(1))
+	_, err = parseColor("red")
+	assert(t, err, nil)
+	_, err = parseColor("nocolor")
+	if err == nil {
+		t.Error("invalid color accepted")
+	}
+}
@@ -3,31 +3,46 @@ package renderer
 import (
 	"github.com/g3n/engine/core"
 	"github.com/g3n/engine/graphic"
-	"github.com/g3n/engine/math32"
 )
 
-// selectNode uses a raycaster to get the selected node.
+// selectNode uses a raycaster or the object id pass to get the selected node.
 // It sends the selection as json to the image channel
 // and changes the node's material
 func (app *RenderingApp) selectNode(mx float32, my float32, multiselect bool) {
-	width, height := app.Window().Size()
-	x := (-.5 + mx/float32(width)) * 2.0
-	y := (.5 - my/float32(height)) * 2.0
-	app.Log().Info("click: %f, %f", x, y)
-	r := core.NewRaycaster(&math32.Vector3{}, &math32.Vector3{})
-	app.CameraPersp().SetRaycaster(r, x, y)
-
-	i := r.IntersectObject(app.Scene(), true)
+	app.log.Debug("click: %f, %f", mx, my)
+	if app.gpuPicking {
+		// the id pass renders the scene, so it has to run on the render thread
+		result := make(chan core.INode, 1)
+		select {
+		case app.sceneUpdates <- func() { inode, _ := app.pickID(mx, my); result <- inode }:
+		case <-app.quit:
+			return
+		}
+		select {
+		case inode := <-result:
+			app.selectObject(inode, multiselect)
+		case <-app.quit:
+		}
+		return
+	}
+	var object core.INode
+	if i := app.raycast(mx, my); len(i) != 0 {
+		object = i[0].Object
+	}
+	app.selectObject(object, multiselect)
+}
 
-	var object *core.Node
-	if len(i) != 0 {
-		object = i[0].Object.GetNode()
-		app.Log().Info("selected: %s", object.Name())
+// selectObject selects a picked node, nil clears the selection unless multiselect is set
+func (app *RenderingApp) selectObject(inode core.INode, multiselect bool) {
+	if inode != nil {
+		object := inode.GetNode()
+		app.log.Info("selected: %s", object.Name())
 		app.sendMessageToClient("selected", object.Name())
+		FireEvent(EventNodeSelected, app.log.Session(), map[string]string{"node": object.Name()})
 		if !multiselect {
 			app.resetSelection()
 		}
-		app.changeNodeMaterial(i[0].Object)
+		app.changeNodeMaterial(inode)
 	} else {
 		if !multiselect {
 			app.sendMessageToClient("selected", "")
@@ -38,6 +53,9 @@ func (app *RenderingApp) selectNode(mx float32, my float32, multiselect bool) {
 
 // resetSelection resets selected nodes to their original state
 func (app *RenderingApp) resetSelection() {
+	if len(app.selectionBuffer) > 0 {
+		app.selectionChanged = true
+	}
 	for inode, materials := range app.selectionBuffer {
 		gnode, _ := inode.(graphic.IGraphic)
 		gfx := gnode.GetGraphic()
@@ -61,8 +79,42 @@ func (app *RenderingApp) changeNodeMaterial(inode core.INode) {
 				materials = append(materials, material)
 			}
 			app.selectionBuffer[inode] = materials
-			gfx.ClearMaterials()
-			gfx.AddMaterial(gnode, app.selectionMaterial, 0, 0)
+			app.selectionChanged = true
+			app.highlight(inode)
 		}
 	}
 }
+
+// highlight sets the material of a selected node depending on the selection style,
+// outlined nodes keep their original materials
+func (app *RenderingApp) highlight(inode core.INode) {
+	gnode := inode.(graphic.IGraphic)
+	gfx := gnode.GetGraphic()
+	gfx.ClearMaterials()
+	if app.selectionStyle == selectOutline {
+		for _, material := range app.selectionBuffer[inode] {
+			gfx.AddMaterial(material.IGraphic(), material.IMaterial(), 0, 0)
+		}
+		return
+	}
+	gfx.AddMaterial(gnode, app.selectionMaterial, 0, 0)
+}
+
+// shareSelection passes a changed selection on to the render thread, which outlines it,
+// and to the participants of a shared session
+func (app *RenderingApp) shareSelection() {
+	if !app.selectionChanged {
+		return
+	}
+	app.selectionChanged = false
+	outline := outlineState{style: app.selectionStyle, nodes: make([]core.INode, 0, len(app.selectionBuffer))}
+	for inode := range app.selectionBuffer {
+		outline.nodes = append(outline.nodes, inode)
+	}
+	select {
+	case app.sceneUpdates <- func() { app.outline = outline }:
+	case <-app.quit:
+	}
+	names := app.selectedNames()
+	app.publishPresence(func(p *Presence) { p.Selection = names })
+}
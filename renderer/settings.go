@@ -0,0 +1,22 @@
+package renderer
+
+import "sync"
+
+// settingsMutex guards settings which may change while sessions are running:
+// LogLevel, DefaultQuality, DefaultEncoder and MaxBandwidth
+var settingsMutex sync.RWMutex
+
+// UpdateSettings changes settings of a running server within f.
+// Quality, encoder and bandwidth apply to new sessions, the log level applies to all sessions.
+func UpdateSettings(f func()) {
+	settingsMutex.Lock()
+	defer settingsMutex.Unlock()
+	f()
+}
+
+// logLevel returns the current minimum log level
+func logLevel() int {
+	settingsMutex.RLock()
+	defer settingsMutex.RUnlock()
+	return LogLevel
+}
@@ -0,0 +1,138 @@
+package renderer
+
+import (
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/geometry"
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/graphic"
+	"github.com/g3n/engine/material"
+	"github.com/g3n/engine/math32"
+)
+
+// maxShadowTriangles limits the triangles casting shadows, larger models only get the shadows of their first triangles
+const maxShadowTriangles = 1000000
+
+// sun elevation sine below which no shadows are cast, avoiding endless shadows at sunrise and sunset
+const minShadowElevation = 0.02
+
+// shadowState holds the shadow the model casts onto the ground plane below it
+type shadowState struct {
+	enabled   bool
+	positions []float32 // world positions of all shadow casting triangles
+	ground    float32
+	mesh      *graphic.Mesh
+}
+
+// Shadows toggles shadows of the model on the ground while the sun is set.
+// Shadows fall onto the plane at the bottom of the model, the model does not shadow itself.
+// The shadow mesh is changed by the render loop.
+func (app *RenderingApp) Shadows(cmd Command) {
+	update := func() {
+		app.shadows.enabled = !app.shadows.enabled
+		app.updateShadows()
+	}
+	select {
+	case app.sceneUpdates <- update:
+	case <-app.quit:
+	}
+}
+
+// updateShadows projects the model along the sun direction onto the ground
+func (app *RenderingApp) updateShadows() {
+	s := &app.shadows
+	if !s.enabled || app.sun.light == nil || app.modelRoot == nil {
+		app.removeShadows()
+		return
+	}
+	if s.mesh == nil {
+		app.buildShadows()
+	}
+	sun := app.sun.light.Position()
+	if sun.Y < minShadowElevation || app.sun.light.Intensity() == 0 {
+		s.mesh.SetVisible(false)
+		return
+	}
+	projected := projectShadow(s.positions, sun, s.ground)
+	s.mesh.GetGeometry().VBO(gls.VertexPosition).SetBuffer(math32.ArrayF32(projected))
+	s.mesh.SetVisible(true)
+}
+
+// buildShadows collects the triangles of all visible meshes in world coordinates and adds the shadow mesh
+func (app *RenderingApp) buildShadows() {
+	s := &app.shadows
+	s.positions = nil
+	var v math32.Vector3
+	app.modelRoot.GetNode().UpdateMatrixWorld()
+	forEachGraphic([]core.INode{app.modelRoot}, func(inode core.INode) {
+		mesh, ok := inode.(*graphic.Mesh)
+		if !ok || !mesh.Visible() || len(s.positions) >= maxShadowTriangles*9 {
+			return
+		}
+		app.loadGeometries([]core.INode{mesh})
+		geom := mesh.GetGeometry()
+		positions := readAttribute(geom, gls.VertexPosition, 3)
+		indices := []uint32(geom.Indices())
+		if len(indices) == 0 {
+			indices = make([]uint32, len(positions)/3)
+			for i := range indices {
+				indices[i] = uint32(i)
+			}
+		}
+		matrixWorld := mesh.MatrixWorld()
+		for _, i := range indices {
+			v.Set(positions[i*3], positions[i*3+1], positions[i*3+2])
+			v.ApplyMatrix4(&matrixWorld)
+			s.positions = append(s.positions, v.X, v.Y, v.Z)
+		}
+	})
+	if len(s.positions) >= maxShadowTriangles*9 {
+		app.log.Warn("shadows are limited to %d triangles", maxShadowTriangles)
+	}
+	box, _ := getWorldBoundingBox([]core.INode{app.modelRoot})
+	// lifted slightly to keep the shadow from flickering on ground faces of the model
+	s.ground = box.Min.Y + box.Size(nil).Length()*0.0005
+
+	normals := make([]float32, len(s.positions))
+	for i := 1; i < len(normals); i += 3 {
+		normals[i] = 1
+	}
+	geom := geometry.NewGeometry()
+	geom.AddVBO(gls.NewVBO(math32.ArrayF32(append([]float32(nil), s.positions...))).AddAttrib(gls.VertexPosition))
+	geom.AddVBO(gls.NewVBO(math32.ArrayF32(normals)).AddAttrib(gls.VertexNormal))
+	mat := material.NewStandard(&math32.Color{})
+	mat.SetSpecularColor(&math32.Color{})
+	mat.SetEmissiveColor(&math32.Color{R: 0.45, G: 0.45, B: 0.5})
+	mat.SetUseLights(material.UseLightNone)
+	mat.SetSide(material.SideDouble)
+	s.mesh = graphic.NewMesh(geom, mat)
+	s.mesh.SetName("shadows")
+	// the projected vertices change with the sun, so the bounds of the geometry are not kept up to date
+	s.mesh.SetCullable(false)
+	app.Scene().Add(s.mesh)
+}
+
+// removeShadows removes the shadow mesh, it gets rebuilt when shadows are shown again
+func (app *RenderingApp) removeShadows() {
+	s := &app.shadows
+	if s.mesh == nil {
+		return
+	}
+	app.Scene().Remove(s.mesh)
+	s.mesh.Dispose()
+	s.mesh = nil
+	s.positions = nil
+}
+
+// projectShadow moves world positions along the sun direction onto the ground plane.
+// Positions below the ground stay where they are.
+func projectShadow(positions []float32, sun math32.Vector3, ground float32) []float32 {
+	projected := make([]float32, len(positions))
+	for i := 0; i+2 < len(positions); i += 3 {
+		x, y, z := positions[i], positions[i+1], positions[i+2]
+		if t := (y - ground) / sun.Y; t > 0 {
+			x, y, z = x-sun.X*t, ground, z-sun.Z*t
+		}
+		projected[i], projected[i+1], projected[i+2] = x, y, z
+	}
+	return projected
+}
@@ -0,0 +1,20 @@
+package renderer
+
+import (
+	"testing"
+
+	"github.com/g3n/engine/math32"
+)
+
+func TestProjectShadow(t *testing.T) {
+	sun := math32.Vector3{X: 1, Y: 1, Z: 0}
+	p := projectShadow([]float32{0, 2, 0, 5, -1, 5}, sun, 0)
+	// a point 2 above the ground falls 2 away from the sun
+	assert(t, p[0], float32(-2))
+	assert(t, p[1], float32(0))
+	assert(t, p[2], float32(0))
+	// points below the ground keep their position
+	assert(t, p[3], float32(5))
+	assert(t, p[4], float32(-1))
+	assert(t, p[5], float32(5))
+}
@@ -0,0 +1,94 @@
+package renderer
+
+import (
+	"strings"
+	"time"
+)
+
+// time of day between two steps of a shadow study
+const studyStep = 10 * time.Minute
+
+// rendered frames per step of a shadow study
+const studyFrames = 6
+
+// shadowStudy is the sun moving across a day
+type shadowStudy struct {
+	times  []time.Time
+	frame  int
+	record bool
+}
+
+// Shadowstudy animates the sun with shadows from sunrise to sunset of a day, the value is
+// date,latitude,longitude[,north][,record] e.g. 2020-06-21,47.37,8.54,record.
+// With record the study is sent as animated GIF when it ends, an empty value stops a running study.
+// The study is started and stopped by the render loop, which animates the sun.
+func (app *RenderingApp) Shadowstudy(cmd Command) {
+	update := app.stopStudy
+	if cmd.Val != "" && cmd.Val != "off" {
+		value, record := cmd.Val, false
+		if strings.HasSuffix(value, ",record") {
+			value, record = strings.TrimSuffix(value, ",record"), true
+		}
+		day, site, err := parseSun(value)
+		if err != nil {
+			app.sendMessageToClient("shadowstudy", err.Error())
+			return
+		}
+		times := daylight(day, site.lat, site.lon)
+		if len(times) == 0 {
+			app.sendMessageToClient("shadowstudy", "the sun does not rise on "+day.Format("2006-01-02"))
+			return
+		}
+		update = func() {
+			app.sun.site = site
+			app.shadows.enabled = true
+			app.study = shadowStudy{times: times, record: record}
+			if record {
+				app.startRecording(nil)
+			}
+		}
+	}
+	select {
+	case app.sceneUpdates <- update:
+	case <-app.quit:
+	}
+}
+
+// animateSun moves the sun one step further in a running shadow study
+func (app *RenderingApp) animateSun(evname string, ev interface{}) {
+	s := &app.study
+	if len(s.times) == 0 {
+		return
+	}
+	if s.frame%studyFrames == 0 {
+		step := s.frame / studyFrames
+		if step >= len(s.times) {
+			app.stopStudy()
+			return
+		}
+		app.setSun(s.times[step])
+	}
+	s.frame++
+}
+
+// stopStudy ends a shadow study and sends its recording
+func (app *RenderingApp) stopStudy() {
+	if app.study.record {
+		app.stopRecording()
+	}
+	app.study = shadowStudy{}
+}
+
+// daylight returns the times in steps of studyStep at which the sun is above the horizon
+// during the local solar day of a date
+func daylight(day time.Time, lat float64, lon float64) []time.Time {
+	midnight := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC)
+	midnight = midnight.Add(-time.Duration(lon / 15 * float64(time.Hour)))
+	var times []time.Time
+	for t := midnight; t.Before(midnight.Add(24 * time.Hour)); t = t.Add(studyStep) {
+		if elevation, _ := sunPosition(t, lat, lon); elevation > 0 {
+			times = append(times, t)
+		}
+	}
+	return times
+}
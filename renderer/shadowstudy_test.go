@@ -0,0 +1,21 @@
+package renderer
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDaylight(t *testing.T) {
+	times := daylight(time.Date(2020, 6, 21, 0, 0, 0, 0, time.UTC), 47.37, 8.54)
+	// about 16 hours of daylight in Zurich at the summer solstice
+	if len(times) < 15*6 || len(times) > 17*6 {
+		t.Error("wrong day length:", len(times))
+	}
+	for _, at := range times {
+		if elevation, _ := sunPosition(at, 47.37, 8.54); elevation <= 0 {
+			t.Error("sun below horizon at", at)
+		}
+	}
+	// polar night
+	assert(t, len(daylight(time.Date(2020, 12, 21, 0, 0, 0, 0, time.UTC), 85, 0)), 0)
+}
@@ -0,0 +1,61 @@
+package renderer
+
+import (
+	"github.com/g3n/engine/graphic"
+	"github.com/g3n/engine/loader/gltf"
+)
+
+// shareMeshGeometry lets all gltf nodes referencing the same mesh share one geometry,
+// so repeated elements like bolts or chairs are held and uploaded to the GPU only once.
+// Each node still gets a mesh of its own and is drawn separately.
+// It returns the number of primitives which have been replaced by shared ones.
+func shareMeshGeometry(g *gltf.GLTF) int {
+	first := make(map[int][]*graphic.Mesh)
+	shared := 0
+	for i, nodeData := range g.Nodes {
+		if nodeData.Mesh == nil || nodeData.Skin != nil {
+			continue
+		}
+		inode, err := g.LoadNode(i)
+		if err != nil {
+			continue
+		}
+		// primitives are added before any gltf children
+		node := inode.GetNode()
+		count := len(g.Meshes[*nodeData.Mesh].Primitives)
+		if count > len(node.Children()) {
+			continue
+		}
+		var prims []*graphic.Mesh
+		for k := 0; k < count; k++ {
+			mesh, ok := node.ChildAt(k).(*graphic.Mesh)
+			if !ok {
+				break
+			}
+			prims = append(prims, mesh)
+		}
+		if len(prims) != count {
+			continue
+		}
+
+		src, found := first[*nodeData.Mesh]
+		if !found {
+			first[*nodeData.Mesh] = prims
+			continue
+		}
+		for k, mesh := range prims {
+			geom := src[k].GetGeometry().Incref()
+			replacement := graphic.NewMesh(geom, nil)
+			for _, gm := range src[k].Materials() {
+				replacement.AddMaterial(gm.IMaterial(), 0, 0)
+			}
+			node.RemoveAt(k)
+			node.AddAt(k, replacement)
+			// materials are cached and shared by the gltf loader,
+			// only the duplicated geometry gets released
+			mesh.GetGeometry().Dispose()
+			shared++
+		}
+	}
+	return shared
+}
@@ -0,0 +1,149 @@
+package renderer
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/math32"
+)
+
+// snapSettings for picks and measurements
+type snapSettings struct {
+	vertex   bool
+	edge     bool
+	midpoint bool
+	radius   float32 // screen-space radius in pixels
+}
+
+// default snap radius in pixels
+const defaultSnapRadius = 10
+
+// snapCandidate is a point a pick can snap to
+type snapCandidate struct {
+	point math32.Vector3
+	kind  string
+}
+
+// Snap configures snapping of picks and measurements.
+// The value is a comma separated list of vertex, edge and midpoint
+// with an optional radius in pixels, e.g. vertex,midpoint:15 or off
+func (app *RenderingApp) Snap(cmd Command) {
+	app.snap = parseSnapSettings(cmd.Val)
+}
+
+// parseSnapSettings parses snap modes and radius
+func parseSnapSettings(value string) snapSettings {
+	settings := snapSettings{radius: defaultSnapRadius}
+	s := strings.Split(value, ":")
+	if len(s) == 2 {
+		radius, err := strconv.ParseFloat(s[1], 32)
+		if err == nil {
+			settings.radius = float32(getFloatValueInRange(radius, 1, 100))
+		}
+	}
+	for _, mode := range strings.Split(s[0], ",") {
+		switch strings.TrimSpace(mode) {
+		case "vertex":
+			settings.vertex = true
+		case "edge":
+			settings.edge = true
+		case "midpoint":
+			settings.midpoint = true
+		}
+	}
+	return settings
+}
+
+// enabled returns true if any snap mode is active
+func (s snapSettings) enabled() bool {
+	return s.vertex || s.edge || s.midpoint
+}
+
+// getClosestPointOnSegment returns the point on segment ab closest to p
+func getClosestPointOnSegment(a math32.Vector3, b math32.Vector3, p math32.Vector3) math32.Vector3 {
+	ab := b
+	ab.Sub(&a)
+	ap := p
+	ap.Sub(&a)
+	lengthSq := ab.LengthSq()
+	if lengthSq == 0 {
+		return a
+	}
+	t := math32.Clamp(ap.Dot(&ab)/lengthSq, 0, 1)
+	ab.MultiplyScalar(t)
+	return *a.Add(&ab)
+}
+
+// getSnapCandidates returns the points of a face a pick at point can snap to,
+// vertices and midpoints first as they take precedence over edges
+func getSnapCandidates(face [3]math32.Vector3, point math32.Vector3, settings snapSettings) []snapCandidate {
+	var candidates []snapCandidate
+	for k := range face {
+		a := face[k]
+		b := face[(k+1)%3]
+		if settings.vertex {
+			candidates = append(candidates, snapCandidate{point: a, kind: "vertex"})
+		}
+		if settings.midpoint {
+			m := a
+			m.Add(&b).MultiplyScalar(0.5)
+			candidates = append(candidates, snapCandidate{point: m, kind: "midpoint"})
+		}
+	}
+	if settings.edge {
+		for k := range face {
+			p := getClosestPointOnSegment(face[k], face[(k+1)%3], point)
+			candidates = append(candidates, snapCandidate{point: p, kind: "edge"})
+		}
+	}
+	return candidates
+}
+
+// toScreen projects a world point to screen coordinates in pixels
+func (app *RenderingApp) toScreen(p math32.Vector3) (float32, float32) {
+	width, height := app.Window().Size()
+	app.CameraPersp().Project(&p)
+	return (p.X/2 + .5) * float32(width), (.5 - p.Y/2) * float32(height)
+}
+
+// snapHit moves a hit to the closest snap candidate within the snap radius
+func (app *RenderingApp) snapHit(hit *Hit, i core.Intersect, mx float32, my float32) {
+	face, ok := getFaceVertices(i)
+	if !ok {
+		return
+	}
+	best := float32(-1)
+	for _, c := range getSnapCandidates(face, i.Point, app.snap) {
+		// edges only count if no vertex or midpoint is in range
+		if c.kind == "edge" && best >= 0 {
+			break
+		}
+		x, y := app.toScreen(c.point)
+		d := math32.Sqrt((x-mx)*(x-mx) + (y-my)*(y-my))
+		if d <= app.snap.radius && (best < 0 || d < best) {
+			best = d
+			hit.Point = toArray(c.point)
+			hit.Snap = c.kind
+		}
+	}
+	if hit.Snap != "" {
+		origin := app.Camera().GetCamera().Position()
+		p := math32.Vector3{X: hit.Point[0], Y: hit.Point[1], Z: hit.Point[2]}
+		hit.Distance = origin.DistanceTo(&p)
+	}
+}
+
+// pick returns the snapped hit at the given screen coordinates or nil
+func (app *RenderingApp) pick(mx float32, my float32) *Hit {
+	i := app.raycast(mx, my)
+	if len(i) == 0 {
+		return nil
+	}
+	hit := newHit(i[0])
+	hit.Material = app.materialName(i[0].Object)
+	if app.snap.enabled() {
+		app.snapHit(hit, i[0], mx, my)
+	}
+	return hit
+}
@@ -0,0 +1,44 @@
+package renderer
+
+import (
+	"testing"
+
+	"github.com/g3n/engine/math32"
+)
+
+func TestParseSnapSettings(t *testing.T) {
+	s := parseSnapSettings("vertex,midpoint:15")
+	assert(t, s.vertex, true)
+	assert(t, s.midpoint, true)
+	assert(t, s.edge, false)
+	assert(t, s.radius, float32(15))
+
+	s = parseSnapSettings("off")
+	assert(t, s.enabled(), false)
+	assert(t, s.radius, float32(defaultSnapRadius))
+}
+
+func TestGetClosestPointOnSegment(t *testing.T) {
+	a := math32.Vector3{X: 0, Y: 0, Z: 0}
+	b := math32.Vector3{X: 10, Y: 0, Z: 0}
+	assert(t, getClosestPointOnSegment(a, b, math32.Vector3{X: 5, Y: 3, Z: 0}), math32.Vector3{X: 5, Y: 0, Z: 0})
+	assert(t, getClosestPointOnSegment(a, b, math32.Vector3{X: -5, Y: 3, Z: 0}), a)
+	assert(t, getClosestPointOnSegment(a, b, math32.Vector3{X: 15, Y: 3, Z: 0}), b)
+}
+
+func TestGetSnapCandidates(t *testing.T) {
+	face := [3]math32.Vector3{{X: 0, Y: 0, Z: 0}, {X: 2, Y: 0, Z: 0}, {X: 0, Y: 2, Z: 0}}
+	c := getSnapCandidates(face, math32.Vector3{X: 1, Y: 0.1, Z: 0}, snapSettings{vertex: true, midpoint: true, edge: true})
+	assert(t, len(c), 9)
+	assert(t, c[1].kind, "midpoint")
+	assert(t, c[1].point, math32.Vector3{X: 1, Y: 0, Z: 0})
+	assert(t, c[8].kind, "edge")
+}
+
+func TestParseScreenPoints(t *testing.T) {
+	p, ok := parseScreenPoints("1:2:3.5:4")
+	assert(t, ok, true)
+	assert(t, p[2], float32(3.5))
+	_, ok = parseScreenPoints("1:2:3")
+	assert(t, ok, false)
+}
@@ -0,0 +1,65 @@
+package renderer
+
+import (
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/graphic"
+	"github.com/g3n/engine/loader/gltf"
+)
+
+// SceneStats describes the complexity of the loaded scene
+type SceneStats struct {
+	Nodes         int `json:"nodes"`
+	Meshes        int `json:"meshes"`
+	Triangles     int `json:"triangles"`
+	TextureMemory int `json:"textureMemory"` // estimate in bytes
+	DrawCalls     int `json:"drawCalls"`     // graphics rendered in the last frame
+	CulledGroups  int `json:"culledGroups"`  // group nodes culled in the last frame
+	// resident vertex data and geometries swapped to disk while a memory budget is set
+	GeometryMemory    int64 `json:"geometryMemory,omitempty"`
+	SwappedGeometries int   `json:"swappedGeometries,omitempty"`
+}
+
+// countNodes adds nodes, meshes and triangles below a node to the stats
+func countNodes(inode core.INode, stats *SceneStats) {
+	for _, child := range inode.GetNode().Children() {
+		stats.Nodes++
+		if mesh, ok := child.(*graphic.Mesh); ok {
+			stats.Meshes++
+			geom := mesh.GetGeometry()
+			if geom.Indexed() {
+				stats.Triangles += len(geom.Indices()) / 3
+			} else {
+				stats.Triangles += geom.Items() / 3
+			}
+		}
+		countNodes(child, stats)
+	}
+}
+
+// estimateTextureMemory estimates the GPU memory of all gltf textures
+// as uncompressed RGBA including mipmaps
+func estimateTextureMemory(g *gltf.GLTF) int {
+	sizes := make(map[int]int)
+	total := 0
+	for _, tex := range g.Textures {
+		size, ok := sizes[tex.Source]
+		if !ok {
+			img, err := g.LoadImage(tex.Source)
+			if err != nil {
+				continue
+			}
+			size = len(img.Pix) * 4 / 3
+			sizes[tex.Source] = size
+		}
+		total += size
+	}
+	return total
+}
+
+// Stats sends scene statistics to the client
+func (app *RenderingApp) Stats(cmd Command) {
+	stats := SceneStats{TextureMemory: app.textureMemory, DrawCalls: app.Renderer().Stats().Graphics, CulledGroups: app.culling.last}
+	stats.GeometryMemory, stats.SwappedGeometries = app.memory.used, app.memory.evicted
+	countNodes(app.Scene(), &stats)
+	app.sendDataToClient("stats", stats)
+}
@@ -0,0 +1,20 @@
+package renderer
+
+import (
+	"testing"
+
+	"github.com/g3n/engine/core"
+)
+
+func TestCountNodes(t *testing.T) {
+	root := core.NewNode()
+	child := core.NewNode()
+	child.Add(core.NewNode())
+	root.Add(child)
+	root.Add(core.NewNode())
+
+	stats := SceneStats{}
+	countNodes(root, &stats)
+	assert(t, stats.Nodes, 3)
+	assert(t, stats.Meshes, 0)
+}
@@ -0,0 +1,123 @@
+package renderer
+
+import (
+	"strconv"
+
+	"github.com/g3n/engine/camera"
+	"github.com/g3n/engine/math32"
+)
+
+// DefaultIPD is the interpupillary distance of stereo rendering in millimeters
+var DefaultIPD float32 = 64
+
+// stereo rendering modes
+const (
+	stereoOff = iota
+	stereoSideBySide
+	stereoAnaglyph
+)
+
+// stereoSettings holds the stereo rendering mode
+type stereoSettings struct {
+	mode int
+	ipd  float32 // millimeters, 0 uses DefaultIPD
+	eye  *camera.Perspective
+}
+
+// Stereo toggles side-by-side stereo rendering, a value sets the interpupillary distance in millimeters
+// and enables stereo rendering
+func (app *RenderingApp) Stereo(cmd Command) {
+	app.setStereo(stereoSideBySide, cmd)
+}
+
+// Anaglyph toggles red/cyan anaglyph rendering, a value sets the interpupillary distance in millimeters
+// and enables anaglyph rendering
+func (app *RenderingApp) Anaglyph(cmd Command) {
+	app.setStereo(stereoAnaglyph, cmd)
+}
+
+// setStereo toggles a stereo mode or enables it with the interpupillary distance given by the command
+func (app *RenderingApp) setStereo(mode int, cmd Command) {
+	if cmd.Val == "" {
+		if app.stereo.mode == mode {
+			app.stereo.mode = stereoOff
+		} else {
+			app.stereo.mode = mode
+		}
+		return
+	}
+	ipd, err := strconv.ParseFloat(cmd.Val, 32)
+	if err != nil || ipd < 0 {
+		app.sendMessageToClient("stereo", "invalid interpupillary distance "+cmd.Val)
+		return
+	}
+	app.stereo.ipd = float32(ipd)
+	app.stereo.mode = mode
+}
+
+// renderStereo renders the scene for both eyes and returns the pixels of the composed frame
+func (app *RenderingApp) renderStereo() []byte {
+	w, h := app.Width, app.Height
+	pix := make([]byte, w*h*4)
+	if app.stereo.mode == stereoAnaglyph {
+		left, right := app.renderEyes(w, h)
+		composeAnaglyph(pix, left, right)
+		return pix
+	}
+	half := w / 2
+	left, right := app.renderEyes(half, h)
+	for y := 0; y < h; y++ {
+		copy(pix[y*w*4:], left[y*half*4:(y+1)*half*4])
+		copy(pix[(y*w+half)*4:], right[y*half*4:(y+1)*half*4])
+	}
+	return pix
+}
+
+// renderEyes renders the scene from the left and right eye with the given viewport size
+// and returns copies of both images
+func (app *RenderingApp) renderEyes(w, h int) ([]byte, []byte) {
+	cam := app.CameraPersp()
+	if app.stereo.eye == nil {
+		app.stereo.eye = camera.NewPerspective(cam.Fov(), float32(w)/float32(h), cam.Near(), cam.Far())
+	}
+	eye := app.stereo.eye
+	eye.SetFov(cam.Fov())
+	eye.SetAspect(float32(w) / float32(h))
+
+	position, quaternion := cam.Position(), cam.Quaternion()
+	ipd := app.stereo.ipd
+	if ipd == 0 {
+		ipd = DefaultIPD
+	}
+	right := math32.Vector3{X: 1}
+	right.ApplyQuaternion(&quaternion).MultiplyScalar(fromMillimeters(ipd, app.modelConfig.Unit) * ModelScale / 2)
+
+	gl := app.Gl()
+	gl.Viewport(0, 0, int32(w), int32(h))
+	var images [2][]byte
+	for i, side := range []float32{-1, 1} {
+		p := position
+		p.X += side * right.X
+		p.Y += side * right.Y
+		p.Z += side * right.Z
+		eye.SetPositionVec(&p)
+		eye.SetQuaternionQuat(&quaternion)
+		if _, err := app.Renderer().Render(eye); err != nil {
+			app.log.Error("stereo rendering failed: %v", err)
+		}
+		// read pixels are reused by the next read
+		images[i] = append([]byte(nil), gl.ReadPixels(0, 0, w, h, 6408, 5121)...)
+	}
+	gl.Viewport(0, 0, int32(app.Width), int32(app.Height))
+	return images[0], images[1]
+}
+
+// composeAnaglyph takes the red channel of the left and green and blue of the right image
+func composeAnaglyph(dst, left, right []byte) {
+	for i := 0; i+3 < len(dst); i += 4 {
+		dst[i] = left[i]
+		dst[i+1] = right[i+1]
+		dst[i+2] = right[i+2]
+		dst[i+3] = 255
+	}
+}
@@ -0,0 +1,29 @@
+package renderer
+
+import (
+	"testing"
+)
+
+func TestSetStereo(t *testing.T) {
+	app := RenderingApp{}
+	app.setStereo(stereoSideBySide, Command{})
+	assert(t, app.stereo.mode, stereoSideBySide)
+	app.setStereo(stereoAnaglyph, Command{})
+	assert(t, app.stereo.mode, stereoAnaglyph)
+	app.setStereo(stereoAnaglyph, Command{})
+	assert(t, app.stereo.mode, stereoOff)
+	app.setStereo(stereoSideBySide, Command{Val: "60"})
+	assert(t, app.stereo.mode, stereoSideBySide)
+	assert(t, app.stereo.ipd, float32(60))
+}
+
+func TestComposeAnaglyph(t *testing.T) {
+	left := []byte{10, 20, 30, 40}
+	right := []byte{50, 60, 70, 80}
+	dst := make([]byte, 4)
+	composeAnaglyph(dst, left, right)
+	assert(t, dst[0], byte(10))
+	assert(t, dst[1], byte(60))
+	assert(t, dst[2], byte(70))
+	assert(t, dst[3], byte(255))
+}
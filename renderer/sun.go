@@ -0,0 +1,161 @@
+package renderer
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/g3n/engine/light"
+	"github.com/g3n/engine/math32"
+)
+
+// sunIntensity is the intensity of the sun light above the horizon
+const sunIntensity = 1.0
+
+// sunSite is the location and orientation of a model on earth
+type sunSite struct {
+	lat, lon float64 // degrees, north and east are positive
+	north    float64 // angle of north from the -z axis towards +x in degrees
+}
+
+// sunState holds the sun light replacing the default light
+type sunState struct {
+	light *light.Directional
+	site  sunSite
+	time  time.Time
+}
+
+// SunPosition is the position of the sun in the sky sent to the client
+type SunPosition struct {
+	Time      time.Time `json:"time"`
+	Elevation float64   `json:"elevation"` // degrees above the horizon
+	Azimuth   float64   `json:"azimuth"`   // degrees from north, clockwise
+}
+
+// Sun places a directional sun light by time and location, the value is time,latitude,longitude[,north]
+// e.g. 2020-06-21T14:00:00+02:00,47.37,8.54. Times without zone are UTC, an empty value restores the default light.
+// The light is changed by the render loop.
+func (app *RenderingApp) Sun(cmd Command) {
+	update := app.removeSun
+	if cmd.Val != "" && cmd.Val != "off" {
+		t, site, err := parseSun(cmd.Val)
+		if err != nil {
+			app.sendMessageToClient("sun", err.Error())
+			return
+		}
+		update = func() {
+			app.sun.site = site
+			app.setSun(t)
+		}
+	}
+	select {
+	case app.sceneUpdates <- update:
+	case <-app.quit:
+	}
+}
+
+// setSun moves the sun light to the position of the given time at the current site
+func (app *RenderingApp) setSun(t time.Time) {
+	s := &app.sun
+	if s.light == nil {
+		s.light = light.NewDirectional(&math32.Color{R: 1, G: 0.97, B: 0.9}, sunIntensity)
+		app.Scene().Add(s.light)
+		if app.defaultLight != nil {
+			app.defaultLight.SetVisible(false)
+		}
+	}
+	s.time = t
+	elevation, azimuth := sunPosition(t, s.site.lat, s.site.lon)
+	// the sun does not light the scene below the horizon
+	if elevation > 0 {
+		s.light.SetIntensity(sunIntensity)
+	} else {
+		s.light.SetIntensity(0)
+	}
+	d := sunDirection(elevation, azimuth-s.site.north)
+	s.light.SetPositionVec(&d)
+	app.updateShadows()
+	app.sendDataToClient("sun", SunPosition{Time: t, Elevation: elevation, Azimuth: azimuth})
+}
+
+// removeSun removes the sun light and shows the default light again
+func (app *RenderingApp) removeSun() {
+	if app.sun.light == nil {
+		return
+	}
+	app.Scene().Remove(app.sun.light)
+	app.sun.light = nil
+	app.updateShadows()
+	app.applyLighting()
+}
+
+// parseSun parses time,latitude,longitude[,north]
+func parseSun(value string) (time.Time, sunSite, error) {
+	var site sunSite
+	s := strings.Split(value, ",")
+	if len(s) != 3 && len(s) != 4 {
+		return time.Time{}, site, fmt.Errorf("invalid sun %s, expected time,latitude,longitude[,north]", value)
+	}
+	t, err := parseSunTime(strings.TrimSpace(s[0]))
+	if err != nil {
+		return t, site, err
+	}
+	numbers := make([]float64, len(s)-1)
+	for i, v := range s[1:] {
+		if numbers[i], err = strconv.ParseFloat(strings.TrimSpace(v), 64); err != nil {
+			return t, site, fmt.Errorf("invalid number %s", v)
+		}
+	}
+	site.lat, site.lon = numbers[0], numbers[1]
+	if len(numbers) == 3 {
+		site.north = numbers[2]
+	}
+	if math.Abs(site.lat) > 90 || math.Abs(site.lon) > 180 {
+		return t, site, fmt.Errorf("latitude or longitude out of range")
+	}
+	return t, site, nil
+}
+
+// parseSunTime parses a RFC 3339 time, times without zone are UTC
+func parseSunTime(value string) (time.Time, error) {
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02T15:04", "2006-01-02"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time %s", value)
+}
+
+// sunPosition returns elevation and azimuth of the sun in degrees by the NOAA approximation,
+// which is accurate to a fraction of a degree
+func sunPosition(t time.Time, lat float64, lon float64) (float64, float64) {
+	t = t.UTC()
+	hours := float64(t.Hour()) + float64(t.Minute())/60 + float64(t.Second())/3600
+	gamma := 2 * math.Pi / 365 * (float64(t.YearDay()-1) + (hours-12)/24)
+	eqtime := 229.18 * (0.000075 + 0.001868*math.Cos(gamma) - 0.032077*math.Sin(gamma) -
+		0.014615*math.Cos(2*gamma) - 0.040849*math.Sin(2*gamma))
+	decl := 0.006918 - 0.399912*math.Cos(gamma) + 0.070257*math.Sin(gamma) -
+		0.006758*math.Cos(2*gamma) + 0.000907*math.Sin(2*gamma) -
+		0.002697*math.Cos(3*gamma) + 0.00148*math.Sin(3*gamma)
+	// true solar time in minutes and hour angle
+	solarTime := hours*60 + eqtime + 4*lon
+	ha := (solarTime/4 - 180) * math.Pi / 180
+	phi := lat * math.Pi / 180
+
+	cosZenith := math.Sin(phi)*math.Sin(decl) + math.Cos(phi)*math.Cos(decl)*math.Cos(ha)
+	elevation := 90 - math.Acos(math.Max(-1, math.Min(1, cosZenith)))*180/math.Pi
+	azimuth := math.Atan2(math.Sin(ha), math.Cos(ha)*math.Sin(phi)-math.Tan(decl)*math.Cos(phi))*180/math.Pi + 180
+	return elevation, math.Mod(azimuth, 360)
+}
+
+// sunDirection returns the unit vector pointing to the sun, north is -z and east is +x
+func sunDirection(elevation float64, azimuth float64) math32.Vector3 {
+	el, az := elevation*math.Pi/180, azimuth*math.Pi/180
+	return math32.Vector3{
+		X: float32(math.Cos(el) * math.Sin(az)),
+		Y: float32(math.Sin(el)),
+		Z: float32(-math.Cos(el) * math.Cos(az)),
+	}
+}
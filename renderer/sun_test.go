@@ -0,0 +1,51 @@
+package renderer
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestSunPosition(t *testing.T) {
+	// solar noon in Zurich at the summer solstice
+	elevation, azimuth := sunPosition(time.Date(2020, 6, 21, 11, 26, 0, 0, time.UTC), 47.37, 8.54)
+	if math.Abs(elevation-66.07) > 0.5 || math.Abs(azimuth-180) > 2 {
+		t.Error("wrong noon position:", elevation, azimuth)
+	}
+	// morning sun rises in the east
+	elevation, azimuth = sunPosition(time.Date(2020, 3, 20, 7, 0, 0, 0, time.UTC), 47.37, 8.54)
+	if elevation < 0 || elevation > 30 || azimuth < 90 || azimuth > 120 {
+		t.Error("wrong morning position:", elevation, azimuth)
+	}
+	// midnight is below the horizon
+	if elevation, _ = sunPosition(time.Date(2020, 6, 21, 23, 0, 0, 0, time.UTC), 47.37, 8.54); elevation > 0 {
+		t.Error("sun above horizon at midnight:", elevation)
+	}
+}
+
+func TestSunDirection(t *testing.T) {
+	d := sunDirection(0, 90)
+	if math.Abs(float64(d.X)-1) > 1e-6 || math.Abs(float64(d.Y)) > 1e-6 {
+		t.Error("east is not +x:", d)
+	}
+	d = sunDirection(90, 0)
+	if math.Abs(float64(d.Y)-1) > 1e-6 {
+		t.Error("zenith is not +y:", d)
+	}
+}
+
+func TestParseSun(t *testing.T) {
+	at, site, err := parseSun("2020-06-21T14:00:00+02:00, 47.37, 8.54, 30")
+	assert(t, err, nil)
+	assert(t, at.UTC(), time.Date(2020, 6, 21, 12, 0, 0, 0, time.UTC))
+	assert(t, site, sunSite{lat: 47.37, lon: 8.54, north: 30})
+	at, _, err = parseSun("2020-06-21T14:00,0,0")
+	assert(t, err, nil)
+	assert(t, at, time.Date(2020, 6, 21, 14, 0, 0, 0, time.UTC))
+	if _, _, err := parseSun("2020-06-21T14:00,95,0"); err == nil {
+		t.Error("missing range error")
+	}
+	if _, _, err := parseSun("noon,0,0"); err == nil {
+		t.Error("missing time error")
+	}
+}
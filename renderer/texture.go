@@ -0,0 +1,197 @@
+package renderer
+
+import (
+	"fmt"
+	"image"
+
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/loader/gltf"
+	"github.com/g3n/engine/material"
+	"github.com/g3n/engine/texture"
+	"github.com/moethu/imaging"
+)
+
+// size of low resolution textures shown while streaming
+const previewTextureSize = 256
+
+// deferredTexture is a material texture which gets loaded after the scene
+type deferredTexture struct {
+	material int
+	slot     string
+	texture  int
+}
+
+// downscaleTextures fits all gltf images into maxSize x maxSize pixels.
+// It has to run before the scene is loaded: the loader caches decoded images
+// and creates all textures from this cache, so they are uploaded downscaled.
+// It returns the number of downscaled images.
+func downscaleTextures(g *gltf.GLTF, maxSize int) int {
+	count := 0
+	for i := range g.Images {
+		img, err := g.LoadImage(i)
+		if err != nil {
+			continue
+		}
+		if downscaleImage(img, maxSize) {
+			count++
+		}
+	}
+	return count
+}
+
+// downscaleImage fits an image into maxSize x maxSize pixels in place
+func downscaleImage(img *image.RGBA, maxSize int) bool {
+	size := img.Rect.Size()
+	if maxSize <= 0 || (size.X <= maxSize && size.Y <= maxSize) {
+		return false
+	}
+	*img = *imaging.Fit(img, maxSize, maxSize, imaging.Lanczos)
+	return true
+}
+
+// deferTextures removes all texture references from pbr materials,
+// so the scene loads without decoding any image. It returns the removed references.
+func deferTextures(g *gltf.GLTF) []deferredTexture {
+	var deferred []deferredTexture
+	for i := range g.Materials {
+		m := &g.Materials[i]
+		if m.PbrMetallicRoughness == nil || len(m.Extensions) > 0 {
+			continue
+		}
+		if pbr := m.PbrMetallicRoughness; pbr.BaseColorTexture != nil {
+			deferred = append(deferred, deferredTexture{i, "baseColor", pbr.BaseColorTexture.Index})
+			pbr.BaseColorTexture = nil
+		}
+		if pbr := m.PbrMetallicRoughness; pbr.MetallicRoughnessTexture != nil {
+			deferred = append(deferred, deferredTexture{i, "metallicRoughness", pbr.MetallicRoughnessTexture.Index})
+			pbr.MetallicRoughnessTexture = nil
+		}
+		if m.NormalTexture != nil {
+			deferred = append(deferred, deferredTexture{i, "normal", m.NormalTexture.Index})
+			m.NormalTexture = nil
+		}
+		if m.OcclusionTexture != nil {
+			deferred = append(deferred, deferredTexture{i, "occlusion", m.OcclusionTexture.Index})
+			m.OcclusionTexture = nil
+		}
+		if m.EmissiveTexture != nil {
+			// the loader defaults the emissive factor to white for textured materials only
+			if m.EmissiveFactor == nil {
+				m.EmissiveFactor = &[3]float32{1, 1, 1}
+			}
+			deferred = append(deferred, deferredTexture{i, "emissive", m.EmissiveTexture.Index})
+			m.EmissiveTexture = nil
+		}
+	}
+	return deferred
+}
+
+// setMaterialMap assigns a texture to a slot of a pbr material
+func setMaterialMap(pm *material.Physical, slot string, tex *texture.Texture2D) {
+	switch slot {
+	case "baseColor":
+		pm.SetBaseColorMap(tex)
+	case "metallicRoughness":
+		pm.SetMetallicRoughnessMap(tex)
+	case "normal":
+		pm.SetNormalMap(tex)
+	case "occlusion":
+		pm.SetOcclusionMap(tex)
+	case "emissive":
+		pm.SetEmissiveMap(tex)
+	}
+}
+
+// streamTextures decodes deferred textures in the background.
+// Large textures get a low resolution preview first, which is replaced by the full texture afterwards.
+// Materials are only changed by the render loop, see applyTextureUpdates.
+func (app *RenderingApp) streamTextures(g *gltf.GLTF, deferred []deferredTexture, maxSize int, updates chan func()) {
+	previews := make([]*texture.Texture2D, len(deferred))
+	refine := false
+	for i, d := range deferred {
+		app.ShowHud("textures", fmt.Sprintf("Loading textures... %d%%", 100*i/len(deferred)), false, 0)
+		pm, img := app.loadDeferredTexture(g, d, maxSize)
+		if pm == nil {
+			continue
+		}
+		size := img.Rect.Size()
+		if size.X <= previewTextureSize && size.Y <= previewTextureSize {
+			queueTexture(g, pm, d, nil, updates)
+			continue
+		}
+		preview := texture.NewTexture2DFromRGBA(imaging.Fit(img, previewTextureSize, previewTextureSize, imaging.Linear))
+		preview.SetWrapS(gls.REPEAT)
+		preview.SetWrapT(gls.REPEAT)
+		previews[i] = preview
+		refine = true
+		updates <- func() { setMaterialMap(pm, d.slot, preview) }
+	}
+	if refine {
+		app.ShowHud("textures", "Refining textures...", false, 0)
+	}
+	for i, d := range deferred {
+		if previews[i] == nil {
+			continue
+		}
+		if pm, _ := app.loadDeferredTexture(g, d, maxSize); pm != nil {
+			queueTexture(g, pm, d, previews[i], updates)
+		}
+	}
+	memory := estimateTextureMemory(g)
+	updates <- func() { app.textureMemory = memory }
+	app.ShowHud("textures", "", false, 0)
+	app.log.Info("streamed %d textures", len(deferred))
+}
+
+// loadDeferredTexture returns the material and decoded image of a deferred texture,
+// the material is nil if the texture can't be loaded or the app has quit
+func (app *RenderingApp) loadDeferredTexture(g *gltf.GLTF, d deferredTexture, maxSize int) (*material.Physical, *image.RGBA) {
+	select {
+	case <-app.quit:
+		return nil, nil
+	default:
+	}
+	imat, err := g.LoadMaterial(d.material)
+	if err != nil {
+		return nil, nil
+	}
+	pm, ok := imat.(*material.Physical)
+	if !ok || d.texture < 0 || d.texture >= len(g.Textures) {
+		return nil, nil
+	}
+	img, err := g.LoadImage(g.Textures[d.texture].Source)
+	if err != nil {
+		app.log.Warn("loading texture %d: %v", d.texture, err)
+		return nil, nil
+	}
+	downscaleImage(img, maxSize)
+	return pm, img
+}
+
+// queueTexture queues the full resolution texture, replacing an optional preview
+func queueTexture(g *gltf.GLTF, pm *material.Physical, d deferredTexture, preview *texture.Texture2D, updates chan func()) {
+	tex, err := g.LoadTexture(d.texture)
+	if err != nil {
+		return
+	}
+	updates <- func() {
+		if preview != nil {
+			pm.RemoveTexture(preview)
+			preview.Dispose()
+		}
+		setMaterialMap(pm, d.slot, tex)
+	}
+}
+
+// applyTextureUpdates applies streamed textures before a frame gets rendered
+func (app *RenderingApp) applyTextureUpdates(evname string, ev interface{}) {
+	for {
+		select {
+		case update := <-app.textureUpdates:
+			update()
+			app.frameCache.invalidate()
+		default:
+			return
+		}
+	}
+}
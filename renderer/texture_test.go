@@ -0,0 +1,46 @@
+package renderer
+
+import (
+	"bytes"
+	"encoding/base64"
+	"image"
+	"image/png"
+	"testing"
+
+	"github.com/g3n/engine/loader/gltf"
+)
+
+// getImageDataURL returns a png data url of a blank image
+func getImageDataURL(w int, h int) string {
+	var buf bytes.Buffer
+	png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, w, h)))
+	return "data:image/png;base64," + base64.StdEncoding.EncodeToString(buf.Bytes())
+}
+
+func TestDownscaleTextures(t *testing.T) {
+	g := &gltf.GLTF{Images: []gltf.Image{{Uri: getImageDataURL(64, 32)}, {Uri: getImageDataURL(8, 8)}}}
+	assert(t, downscaleTextures(g, 0), 0)
+	assert(t, downscaleTextures(g, 16), 1)
+
+	img, _ := g.LoadImage(0)
+	assert(t, img.Rect.Size(), image.Point{X: 16, Y: 8})
+	img, _ = g.LoadImage(1)
+	assert(t, img.Rect.Size(), image.Point{X: 8, Y: 8})
+}
+
+func TestDeferTextures(t *testing.T) {
+	g := &gltf.GLTF{Materials: []gltf.Material{
+		{PbrMetallicRoughness: &gltf.PbrMetallicRoughness{BaseColorTexture: &gltf.TextureInfo{Index: 2}}},
+		{PbrMetallicRoughness: &gltf.PbrMetallicRoughness{}, EmissiveTexture: &gltf.TextureInfo{Index: 1}},
+		{PbrMetallicRoughness: &gltf.PbrMetallicRoughness{BaseColorTexture: &gltf.TextureInfo{Index: 0}},
+			Extensions: map[string]interface{}{"KHR_materials_common": nil}},
+	}}
+	deferred := deferTextures(g)
+	assert(t, len(deferred), 2)
+	assert(t, deferred[0], deferredTexture{0, "baseColor", 2})
+	assert(t, deferred[1], deferredTexture{1, "emissive", 1})
+	assert(t, g.Materials[0].PbrMetallicRoughness.BaseColorTexture == nil, true)
+	assert(t, *g.Materials[1].EmissiveFactor, [3]float32{1, 1, 1})
+	// materials with extensions are loaded as they are
+	assert(t, g.Materials[2].PbrMetallicRoughness.BaseColorTexture != nil, true)
+}
@@ -0,0 +1,113 @@
+package renderer
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/base64"
+	"image"
+	"strconv"
+	"strings"
+)
+
+// smallest tile edge length in pixels
+const minTileSize = 16
+
+// tileState holds the tiled frame stream
+type tileState struct {
+	size   int                      // tile edge length in pixels, 0 streams whole frames
+	bounds image.Rectangle          // frame size the hashes belong to
+	hashes map[image.Point][16]byte // pixel checksum of the last tile sent, by tile index
+}
+
+// tileFrame holds the tiles changed since the last frame, the client composites them onto its canvas
+type tileFrame struct {
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+	Size   int    `json:"size"`
+	Format string `json:"format"`
+	Tiles  []tile `json:"tiles"`
+}
+
+// tile is an encoded image at a tile index, tiles at the right and bottom edge may be smaller than the tile size
+type tile struct {
+	X     int    `json:"x"`
+	Y     int    `json:"y"`
+	Image string `json:"image"`
+}
+
+// Tiles streams frames as tiles of the given edge length, only changed tiles are sent.
+// An empty value or 0 streams whole frames again.
+func (app *RenderingApp) Tiles(cmd Command) {
+	size := 0
+	if cmd.Val != "" && cmd.Val != "off" {
+		var err error
+		size, err = strconv.Atoi(cmd.Val)
+		if err != nil || (size != 0 && size < minTileSize) {
+			app.sendMessageToClient("tiles", "tile size must be at least "+strconv.Itoa(minTileSize))
+			return
+		}
+	}
+	app.tiles.size = size
+	app.tiles.hashes = nil
+	if size > 0 {
+		app.delta = deltaState{}
+	}
+	// the next whole frame must be sent even if it did not change
+	md5SumBuffer = [16]byte{}
+}
+
+// streamTiles sends all tiles of a frame which changed since they were last sent
+func (app *RenderingApp) streamTiles(img *image.RGBA) {
+	t := &app.tiles
+	if t.hashes == nil || t.bounds != img.Bounds() {
+		t.hashes = make(map[image.Point][16]byte)
+		t.bounds = img.Bounds()
+	}
+	frame := tileFrame{Width: img.Bounds().Dx(), Height: img.Bounds().Dy(), Size: t.size, Format: "jpeg"}
+	if strings.HasPrefix(app.imageSettings.getEncoder(), "png") {
+		frame.Format = "png"
+	}
+	buf := encodePool.Get().(*bytes.Buffer)
+	defer encodePool.Put(buf)
+	for _, r := range tileRects(img.Bounds(), t.size) {
+		index := image.Pt((r.Min.X-img.Rect.Min.X)/t.size, (r.Min.Y-img.Rect.Min.Y)/t.size)
+		hash := tileHash(img, r)
+		if h, ok := t.hashes[index]; ok && h == hash {
+			continue
+		}
+		if err := app.encodeImage(buf, img.SubImage(r)); err != nil {
+			app.log.Error("encoding tile failed: %v", err)
+			continue
+		}
+		t.hashes[index] = hash
+		frame.Tiles = append(frame.Tiles, tile{X: index.X, Y: index.Y, Image: base64.StdEncoding.EncodeToString(buf.Bytes())})
+	}
+	if len(frame.Tiles) == 0 {
+		return
+	}
+	app.stampFrame()
+	app.sendDataToClient("tiles", frame)
+}
+
+// tileRects splits bounds into tiles row by row
+func tileRects(bounds image.Rectangle, size int) []image.Rectangle {
+	var rects []image.Rectangle
+	for y := bounds.Min.Y; y < bounds.Max.Y; y += size {
+		for x := bounds.Min.X; x < bounds.Max.X; x += size {
+			rects = append(rects, image.Rect(x, y, x+size, y+size).Intersect(bounds))
+		}
+	}
+	return rects
+}
+
+// tileHash returns the checksum of the pixels within a tile
+func tileHash(img *image.RGBA, r image.Rectangle) [16]byte {
+	h := md5.New()
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		start := img.PixOffset(r.Min.X, y)
+		h.Write(img.Pix[start : start+r.Dx()*4])
+	}
+	var sum [16]byte
+	copy(sum[:], h.Sum(nil))
+	return sum
+}
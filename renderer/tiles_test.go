@@ -0,0 +1,25 @@
+package renderer
+
+import (
+	"image"
+	"testing"
+)
+
+func TestTileRects(t *testing.T) {
+	rects := tileRects(image.Rect(0, 0, 100, 40), 32)
+	assert(t, len(rects), 8)
+	assert(t, rects[0], image.Rect(0, 0, 32, 32))
+	assert(t, rects[3], image.Rect(96, 0, 100, 32))
+	assert(t, rects[7], image.Rect(96, 32, 100, 40))
+}
+
+func TestTileHash(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 64, 64))
+	left, right := image.Rect(0, 0, 32, 32), image.Rect(32, 0, 64, 32)
+	before := tileHash(img, right)
+	img.Pix[img.PixOffset(40, 10)] = 255
+	assert(t, tileHash(img, left), before)
+	if tileHash(img, right) == before {
+		t.Error("changed tile not detected")
+	}
+}
@@ -0,0 +1,51 @@
+package renderer
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// timestampState numbers the frames sent to the client
+type timestampState struct {
+	enabled bool
+	id      uint64
+	input   int64 // client time of the last command sent with a time, set atomically by the command loop
+}
+
+// frameStamp is sent as frame message right before each frame while timestamps are enabled
+type frameStamp struct {
+	ID    uint64 `json:"id"`              // sequence number of sent frames
+	Frame uint64 `json:"frame"`           // rendered frame number, gaps are frames which were not sent
+	Time  int64  `json:"time"`            // server time in unix milliseconds
+	Input int64  `json:"input,omitempty"` // echoed client time of the last command, for round trip latency
+}
+
+// Timestamps announces each frame by a frame message with a sequence number and the server time, an empty value toggles.
+// Commands may carry the client time in milliseconds as time, the frame message echoes the time of the last one.
+func (app *RenderingApp) Timestamps(cmd Command) {
+	switch cmd.Val {
+	case "":
+		app.timestamps.enabled = !app.timestamps.enabled
+	case "on":
+		app.timestamps.enabled = true
+	case "off":
+		app.timestamps.enabled = false
+	default:
+		app.sendMessageToClient("timestamps", "invalid value "+cmd.Val)
+	}
+}
+
+// stampFrame sends the frame message of the next frame
+func (app *RenderingApp) stampFrame() {
+	t := &app.timestamps
+	if !t.enabled {
+		return
+	}
+	t.id++
+	app.sendDataToClient("frame", newFrameStamp(t.id, app.FrameCount(), time.Now(), atomic.LoadInt64(&t.input)))
+}
+
+// newFrameStamp returns the frame message of a frame sent at a time
+func newFrameStamp(id, frame uint64, now time.Time, input int64) frameStamp {
+	return frameStamp{ID: id, Frame: frame, Time: now.UnixNano() / int64(time.Millisecond), Input: input}
+}
@@ -0,0 +1,25 @@
+package renderer
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestNewFrameStamp(t *testing.T) {
+	now := time.Unix(1600000000, 250*int64(time.Millisecond))
+	stamp := newFrameStamp(7, 42, now, 1599999999900)
+	assert(t, stamp.ID, uint64(7))
+	assert(t, stamp.Frame, uint64(42))
+	assert(t, stamp.Time, int64(1600000000250))
+
+	data, err := json.Marshal(newFrameStamp(1, 2, now, 0))
+	assert(t, err, nil)
+	assert(t, string(data), `{"id":1,"frame":2,"time":1600000000250}`)
+}
+
+func TestCommandTime(t *testing.T) {
+	var cmd Command
+	assert(t, json.Unmarshal([]byte(`{"x":1,"y":2,"cmd":"","time":1600000000250}`), &cmd), nil)
+	assert(t, cmd.Time, int64(1600000000250))
+}
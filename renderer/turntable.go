@@ -0,0 +1,153 @@
+package renderer
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/png"
+	"strconv"
+	"strings"
+
+	"github.com/g3n/engine/math32"
+)
+
+// EventTurntableExported is sent to webhooks with the archive of a finished turntable export
+const EventTurntableExported = "turntable.exported"
+
+// default and maximum number of frames of a turntable export
+const (
+	turntableFrames    = 36
+	maxTurntableFrames = 360
+)
+
+// turntableState holds a running turntable export
+type turntableState struct {
+	active bool
+	views  []cameraView
+	start  cameraView // restored when the export is done
+	buf    *bytes.Buffer
+	zip    *zip.Writer
+	frame  int
+}
+
+// Turntable renders frames around the model at equal angular steps and sends them as zip archive of png images,
+// e.g. for thumbnails or 360° sprite sheets. The value is the number of frames, 36 by default.
+// The camera keeps its distance and elevation. Webhooks subscribed to turntable.exported receive the archive as well.
+func (app *RenderingApp) Turntable(cmd Command) {
+	n, err := parseTurntableFrames(cmd.Val)
+	if err != nil {
+		app.sendMessageToClient("turntable", err.Error())
+		return
+	}
+	select {
+	case app.sceneUpdates <- func() { app.startTurntable(n) }:
+	case <-app.quit:
+	}
+}
+
+// startTurntable starts a turntable export of n frames on the render thread, which animates and captures it
+func (app *RenderingApp) startTurntable(n int) {
+	if app.turntable.active {
+		app.sendMessageToClient("turntable", "a turntable export is running")
+		return
+	}
+	if app.modelRoot == nil {
+		return
+	}
+	app.stopTransition()
+	cam := app.Camera().GetCamera()
+	bbox := app.modelRoot.BoundingBox()
+	buf := new(bytes.Buffer)
+	app.turntable = turntableState{
+		active: true,
+		views:  turntableViews(cam.Position(), *bbox.Center(nil), n),
+		start:  cameraView{position: cam.Position(), target: cam.Target()},
+		buf:    buf,
+		zip:    zip.NewWriter(buf),
+	}
+}
+
+// animateTurntable moves the camera to the view of the next frame of a running turntable export
+func (app *RenderingApp) animateTurntable(evname string, ev interface{}) {
+	t := &app.turntable
+	if !t.active {
+		return
+	}
+	v := t.views[t.frame]
+	cam := app.Camera().GetCamera()
+	cam.SetPositionVec(&v.position)
+	cam.LookAt(&v.target)
+}
+
+// turntableFrame adds a finished frame to a running turntable export and sends the archive after the last frame
+func (app *RenderingApp) turntableFrame(img *image.RGBA) {
+	t := &app.turntable
+	if !t.active {
+		return
+	}
+	f, err := t.zip.Create(fmt.Sprintf("frame-%03d.png", t.frame))
+	if err == nil {
+		err = png.Encode(f, app.watermark(img))
+	}
+	if err != nil {
+		app.log.Error("turntable frame failed: %v", err)
+		app.sendMessageToClient("turntable", err.Error())
+		app.stopTurntable()
+		return
+	}
+	t.frame++
+	if t.frame < len(t.views) {
+		return
+	}
+	if err := t.zip.Close(); err != nil {
+		app.log.Error("turntable archive failed: %v", err)
+		app.sendMessageToClient("turntable", err.Error())
+		app.stopTurntable()
+		return
+	}
+	export := annotationExport{
+		Name:   "turntable.zip",
+		Format: "zip",
+		File:   base64.StdEncoding.EncodeToString(t.buf.Bytes()),
+	}
+	app.stopTurntable()
+	app.sendDataToClient("turntable", export)
+	FireEvent(EventTurntableExported, app.log.Session(), export)
+}
+
+// stopTurntable ends a turntable export and moves the camera back to where it started
+func (app *RenderingApp) stopTurntable() {
+	start := app.turntable.start
+	app.turntable = turntableState{}
+	cam := app.Camera().GetCamera()
+	cam.SetPositionVec(&start.position)
+	cam.LookAt(&start.target)
+}
+
+// turntableViews returns n views around the vertical axis through the center, starting at the camera position
+func turntableViews(position, center math32.Vector3, n int) []cameraView {
+	offset := position
+	offset.Sub(&center)
+	views := make([]cameraView, n)
+	for i := range views {
+		p := offset
+		p.ApplyAxisAngle(&math32.Vector3{Y: 1}, 2*math32.Pi*float32(i)/float32(n))
+		views[i] = cameraView{position: *p.Add(&center), target: center}
+	}
+	return views
+}
+
+// parseTurntableFrames parses the number of frames of a turntable export
+func parseTurntableFrames(value string) (int, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return turntableFrames, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 1 || n > maxTurntableFrames {
+		return 0, fmt.Errorf("invalid number of frames %s, expected 1 to %d", value, maxTurntableFrames)
+	}
+	return n, nil
+}
@@ -0,0 +1,33 @@
+package renderer
+
+import (
+	"testing"
+
+	"github.com/g3n/engine/math32"
+)
+
+func TestTurntableViews(t *testing.T) {
+	center := math32.Vector3{X: 1, Y: 0, Z: 1}
+	views := turntableViews(math32.Vector3{X: 1, Y: 2, Z: 5}, center, 4)
+	assert(t, len(views), 4)
+	expected := []math32.Vector3{{X: 1, Y: 2, Z: 5}, {X: 5, Y: 2, Z: 1}, {X: 1, Y: 2, Z: -3}, {X: -3, Y: 2, Z: 1}}
+	for i, v := range views {
+		assert(t, v.target, center)
+		if v.position.DistanceTo(&expected[i]) > 1e-4 {
+			t.Errorf("view %d at %v, expected %v", i, v.position, expected[i])
+		}
+	}
+}
+
+func TestParseTurntableFrames(t *testing.T) {
+	n, err := parseTurntableFrames("")
+	assert(t, err, nil)
+	assert(t, n, turntableFrames)
+	n, _ = parseTurntableFrames(" 24 ")
+	assert(t, n, 24)
+	for _, v := range []string{"0", "361", "many"} {
+		if _, err := parseTurntableFrames(v); err == nil {
+			t.Errorf("invalid number of frames %s accepted", v)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package renderer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"path/filepath"
+	"strings"
+)
+
+// ModelScale is a global scale factor applied to all models at load
+var ModelScale float32 = 1.0
+
+// DefaultUnit is the unit of models without unit configuration
+var DefaultUnit = "m"
+
+// supported model units
+var units = []string{"mm", "cm", "m", "ft-in"}
+
+// modelConfig holds per model settings, read from an optional
+// json file next to the model, e.g. models/Building.json for models/Building.gltf
+type modelConfig struct {
+	Unit  string                   `json:"unit"`
+	Views map[string]ViewDirection `json:"views"`
+}
+
+// IsUnit returns true for supported unit names
+func IsUnit(unit string) bool {
+	for _, u := range units {
+		if u == unit {
+			return true
+		}
+	}
+	return false
+}
+
+// loadModelConfig reads the model configuration, falling back to defaults
+func loadModelConfig(modelpath string) modelConfig {
+	config := modelConfig{Unit: DefaultUnit}
+	if IsRemoteModel(modelpath) {
+		return config
+	}
+	data, err := ioutil.ReadFile(strings.TrimSuffix(modelpath, filepath.Ext(modelpath)) + ".json")
+	if err != nil {
+		return config
+	}
+	if err := json.Unmarshal(data, &config); err != nil || !IsUnit(config.Unit) {
+		config.Unit = DefaultUnit
+	}
+	return config
+}
+
+// toModelUnits converts a scene length back to model units
+func toModelUnits(length float32) float32 {
+	if ModelScale == 0 {
+		return length
+	}
+	return length / ModelScale
+}
+
+// formatLength formats a length given in model units
+func formatLength(length float32, unit string) string {
+	switch unit {
+	case "mm":
+		return fmt.Sprintf("%.0f mm", length)
+	case "cm":
+		return fmt.Sprintf("%.1f cm", length)
+	case "ft-in":
+		// round to 1/16 inch
+		sixteenths := int(math.Round(float64(length) * 12 * 16))
+		sign := ""
+		if sixteenths < 0 {
+			sign = "-"
+			sixteenths = -sixteenths
+		}
+		feet := sixteenths / (12 * 16)
+		inches := float64(sixteenths%(12*16)) / 16
+		return fmt.Sprintf("%s%d' %g\"", sign, feet, inches)
+	default:
+		return fmt.Sprintf("%.3f m", length)
+	}
+}
+
+// fromMillimeters converts a length in millimeters to model units
+func fromMillimeters(length float32, unit string) float32 {
+	switch unit {
+	case "mm":
+		return length
+	case "cm":
+		return length / 10
+	case "ft-in":
+		return length / 304.8
+	default:
+		return length / 1000
+	}
+}
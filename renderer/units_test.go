@@ -0,0 +1,31 @@
+package renderer
+
+import (
+	"testing"
+)
+
+func TestFormatLength(t *testing.T) {
+	assert(t, formatLength(1234.4, "mm"), "1234 mm")
+	assert(t, formatLength(12.34, "cm"), "12.3 cm")
+	assert(t, formatLength(1.5, "m"), "1.500 m")
+	assert(t, formatLength(5.25, "ft-in"), "5' 3\"")
+	assert(t, formatLength(1.0+1.0/24, "ft-in"), "1' 0.5\"")
+}
+
+func TestIsUnit(t *testing.T) {
+	assert(t, IsUnit("ft-in"), true)
+	assert(t, IsUnit("yd"), false)
+}
+
+func TestToModelUnits(t *testing.T) {
+	ModelScale = 2
+	defer func() { ModelScale = 1 }()
+	assert(t, toModelUnits(10), float32(5))
+}
+
+func TestFromMillimeters(t *testing.T) {
+	assert(t, fromMillimeters(64, "mm"), float32(64))
+	assert(t, fromMillimeters(64, "cm"), float32(6.4))
+	assert(t, fromMillimeters(64, "m"), float32(0.064))
+	assert(t, fromMillimeters(304.8, "ft-in"), float32(1))
+}
@@ -0,0 +1,225 @@
+package renderer
+
+import (
+	"image"
+
+	"github.com/g3n/engine/camera"
+	"github.com/g3n/engine/math32"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+// viewports of the quad view, left to right and top to bottom
+const (
+	viewportTop = iota
+	viewportFront
+	viewportRight
+	viewportPerspective
+)
+
+// viewportNames are the standard views of the orthographic viewports and the labels of all viewports
+var viewportNames = [...]string{"top", "front", "right", "perspective"}
+
+// quadState holds the quad view of a session
+type quadState struct {
+	enabled bool
+	active  int        // viewport receiving cursor input
+	pressed bool       // a mouse button is down, the active viewport keeps the input while dragging
+	last    [2]float32 // cursor position of the last drag event
+	half    float32    // half height of orthographic viewports at zoom 1 in scene units
+	views   [3]orthoView
+	pix     []byte // composed frame
+}
+
+// orthoView is the pan and zoom of an orthographic viewport
+type orthoView struct {
+	offset math32.Vector3
+	zoom   float32
+}
+
+// Viewports switches between the single view (single) and the quad view (quad) of top, front, right and perspective viewports,
+// an empty value toggles. Cursor input goes to the viewport under the cursor, orthographic viewports pan on drag and zoom on scroll.
+func (app *RenderingApp) Viewports(cmd Command) {
+	q := &app.quad
+	switch cmd.Val {
+	case "":
+		q.enabled = !q.enabled
+	case "quad":
+		q.enabled = true
+	case "single":
+		q.enabled = false
+	default:
+		app.sendMessageToClient("viewports", "invalid layout "+cmd.Val)
+		return
+	}
+	q.active, q.pressed = viewportPerspective, false
+	for i := range q.views {
+		q.views[i] = orthoView{zoom: 1}
+	}
+}
+
+// singleView returns true if frames show the camera of the session only
+func (app *RenderingApp) singleView() bool {
+	return app.stereo.mode == stereoOff && !app.quad.enabled
+}
+
+// viewportInput routes a cursor command to the viewport under the cursor and returns true if the command was consumed.
+// Coordinates within the perspective viewport are scaled to the full frame, so it is handled like the single view.
+func (app *RenderingApp) viewportInput(cmd *Command) bool {
+	q := &app.quad
+	if !q.enabled {
+		return false
+	}
+	w, h := app.Width, app.Height
+	switch cmd.Cmd {
+	case "Mousedown":
+		q.active = quadrant(cmd.X, cmd.Y, w, h)
+		q.pressed = true
+		q.last = [2]float32{cmd.X, cmd.Y}
+	case "Navigate":
+		if !q.pressed {
+			q.active = quadrant(cmd.X, cmd.Y, w, h)
+		} else if q.active != viewportPerspective {
+			scale := 2 * q.half / q.views[q.active].zoom / float32(h/2)
+			q.views[q.active].pan(viewportNames[q.active], cmd.X-q.last[0], cmd.Y-q.last[1], scale)
+			q.last = [2]float32{cmd.X, cmd.Y}
+		}
+	case "Mouseup":
+		q.pressed = false
+	case "Zoom":
+		if q.active != viewportPerspective {
+			v := &q.views[q.active]
+			v.zoom = math32.Max(0.01, v.zoom*(1+app.zoom.offset(cmd.Y)/10))
+		}
+		return q.active != viewportPerspective
+	}
+	if q.active != viewportPerspective {
+		return true
+	}
+	cmd.X, cmd.Y = toQuadrant(cmd.X, cmd.Y, w, h)
+	return false
+}
+
+// renderQuad renders all viewports and returns the pixels of the composed frame
+func (app *RenderingApp) renderQuad() []byte {
+	w, h := app.Width, app.Height
+	hw, hh := w/2, h/2
+	q := &app.quad
+	if len(q.pix) != w*h*4 {
+		q.pix = make([]byte, w*h*4)
+	}
+	bbox := app.Scene().ChildAt(0).BoundingBox()
+	center := bbox.Center(nil)
+	radius := center.DistanceTo(&bbox.Max)
+	q.half = radius * 1.1
+
+	gl := app.Gl()
+	gl.Viewport(0, 0, int32(hw), int32(hh))
+	var quads [4][]byte
+	for i := range quads {
+		var cam camera.ICamera = app.Camera()
+		if i != viewportPerspective {
+			cam = q.views[i].camera(viewportNames[i], *center, radius, q.half, float32(w)/float32(h))
+		}
+		if _, err := app.Renderer().Render(cam); err != nil {
+			app.log.Error("%s viewport failed: %v", viewportNames[i], err)
+		}
+		// read pixels are reused by the next read
+		quads[i] = append(quads[i], gl.ReadPixels(0, 0, hw, hh, 6408, 5121)...)
+	}
+	gl.Viewport(0, 0, int32(w), int32(h))
+	composeQuad(q.pix, quads, w, h)
+	return q.pix
+}
+
+// camera returns an orthographic camera of a standard view fitting a model of the given center and radius
+func (v orthoView) camera(view string, center math32.Vector3, radius, half, aspect float32) camera.ICamera {
+	dir, up, _ := orthoAxes(view)
+	cam := camera.NewOrthographic(-half*aspect, half*aspect, half, -half, 0, 4*radius)
+	cam.SetZoom(v.zoom)
+	target := center
+	target.Add(&v.offset)
+	position := target
+	position.Add(dir.MultiplyScalar(-2 * radius))
+	cam.SetPositionVec(&position)
+	cam.SetUp(&up)
+	cam.LookAt(&target)
+	return cam
+}
+
+// pan moves the view by pixels converted to scene units by scale, the model follows the cursor
+func (v *orthoView) pan(view string, dx, dy, scale float32) {
+	_, up, right := orthoAxes(view)
+	v.offset.Add(right.MultiplyScalar(-dx * scale)).Add(up.MultiplyScalar(dy * scale))
+}
+
+// orthoAxes returns the view direction and the up and right screen axes of a standard view
+func orthoAxes(view string) (math32.Vector3, math32.Vector3, math32.Vector3) {
+	dir := getViewVectorByName(view)
+	dir.Negate().Normalize()
+	up := math32.Vector3{Y: 1}
+	if math32.Abs(dir.Y) > 0.9 {
+		// north is up in plan views
+		up = math32.Vector3{Z: -1}
+	}
+	right := *dir.Clone().Cross(&up).Normalize()
+	return dir, up, right
+}
+
+// quadrant returns the viewport at a cursor position
+func quadrant(x, y float32, w, h int) int {
+	q := 0
+	if x >= float32(w/2) {
+		q++
+	}
+	if y >= float32(h/2) {
+		q += 2
+	}
+	return q
+}
+
+// toQuadrant converts a cursor position within a viewport to the full frame
+func toQuadrant(x, y float32, w, h int) (float32, float32) {
+	if x >= float32(w/2) {
+		x -= float32(w / 2)
+	}
+	if y >= float32(h/2) {
+		y -= float32(h / 2)
+	}
+	return x * 2, y * 2
+}
+
+// composeQuad copies the viewports into a frame, pixel rows of opengl start at the bottom
+func composeQuad(dst []byte, quads [4][]byte, w, h int) {
+	hw, hh := w/2, h/2
+	for i, src := range quads {
+		x0, y0 := (i%2)*(w-hw), 0
+		if i < 2 {
+			y0 = h - hh
+		}
+		for y := 0; y < hh; y++ {
+			copy(dst[((y0+y)*w+x0)*4:((y0+y)*w+x0+hw)*4], src[y*hw*4:(y+1)*hw*4])
+		}
+	}
+	// dividers between the viewports
+	for x := 0; x < w; x++ {
+		copy(dst[(hh*w+x)*4:], []byte{64, 64, 64, 255})
+	}
+	for y := 0; y < h; y++ {
+		copy(dst[(y*w+hw)*4:], []byte{64, 64, 64, 255})
+	}
+}
+
+// DrawViewportLabels writes the name of each viewport into its top left corner
+func DrawViewportLabels(img *image.RGBA) *image.RGBA {
+	b := img.Bounds()
+	d := font.Drawer{Dst: img, Src: image.Black, Face: basicfont.Face7x13}
+	for i, name := range viewportNames {
+		x := b.Min.X + (i%2)*(b.Dx()/2) + 8
+		y := b.Min.Y + (i/2)*(b.Dy()/2) + 18
+		d.Dot = fixed.P(x, y)
+		d.DrawString(name)
+	}
+	return img
+}
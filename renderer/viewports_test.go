@@ -0,0 +1,60 @@
+package renderer
+
+import (
+	"testing"
+
+	"github.com/g3n/engine/math32"
+)
+
+func TestQuadrant(t *testing.T) {
+	assert(t, quadrant(10, 10, 800, 600), viewportTop)
+	assert(t, quadrant(410, 10, 800, 600), viewportFront)
+	assert(t, quadrant(10, 310, 800, 600), viewportRight)
+	assert(t, quadrant(799, 599, 800, 600), viewportPerspective)
+
+	x, y := toQuadrant(600, 450, 800, 600)
+	assert(t, x, float32(400))
+	assert(t, y, float32(300))
+	x, y = toQuadrant(100, 50, 800, 600)
+	assert(t, x, float32(200))
+	assert(t, y, float32(100))
+}
+
+func TestOrthoAxes(t *testing.T) {
+	dir, up, right := orthoAxes("top")
+	assert(t, dir, math32.Vector3{Y: -1})
+	assert(t, up, math32.Vector3{Z: -1})
+	assert(t, right, math32.Vector3{X: 1})
+	dir, up, right = orthoAxes("front")
+	assert(t, dir, math32.Vector3{Z: -1})
+	assert(t, up, math32.Vector3{Y: 1})
+	assert(t, right, math32.Vector3{X: 1})
+}
+
+func TestOrthoPan(t *testing.T) {
+	v := orthoView{zoom: 1}
+	// dragging right and down moves the view left and up, the model follows the cursor
+	v.pan("top", 10, 20, 0.5)
+	assert(t, v.offset, math32.Vector3{X: -5, Z: -10})
+}
+
+func TestComposeQuad(t *testing.T) {
+	w, h := 4, 4
+	var quads [4][]byte
+	for i := range quads {
+		quads[i] = make([]byte, 2*2*4)
+		for j := range quads[i] {
+			quads[i][j] = byte(i + 1)
+		}
+	}
+	dst := make([]byte, w*h*4)
+	composeQuad(dst, quads, w, h)
+	at := func(x, y int) byte { return dst[(y*w+x)*4] }
+	// opengl rows start at the bottom, the top viewports are in the last rows
+	assert(t, at(0, 3), byte(viewportTop+1))
+	assert(t, at(3, 3), byte(viewportFront+1))
+	assert(t, at(0, 0), byte(viewportRight+1))
+	assert(t, at(3, 0), byte(viewportPerspective+1))
+	assert(t, at(2, 0), byte(64))
+	assert(t, at(0, 2), byte(64))
+}
@@ -0,0 +1,151 @@
+package renderer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/g3n/engine/math32"
+)
+
+// builtin standard views of setCamera
+var builtinViews = []string{"top", "bottom", "front", "rear", "left", "right"}
+
+// ViewDirection is a named view of a model, the camera looks along the direction at the model center
+type ViewDirection struct {
+	Direction [3]float32 `json:"direction"`
+	Up        [3]float32 `json:"up"`
+}
+
+// viewList lists the standard views of a model sent to the client
+type viewList struct {
+	Builtin []string                 `json:"builtin"`
+	Custom  map[string]ViewDirection `json:"custom"`
+}
+
+// Defineview adds a named view to the model, the value is name[,dx,dy,dz[,ux,uy,uz]].
+// Without direction the current camera direction is used. Views of local models are saved to the model configuration.
+func (app *RenderingApp) Defineview(cmd Command) {
+	name, view, err := parseView(cmd.Val)
+	if err != nil {
+		app.sendMessageToClient("defineview", err.Error())
+		return
+	}
+	if view == nil {
+		state := app.cameraState()
+		view = &ViewDirection{Direction: state.Direction, Up: state.Up}
+	}
+	if app.modelConfig.Views == nil {
+		app.modelConfig.Views = make(map[string]ViewDirection)
+	}
+	app.modelConfig.Views[name] = *view
+	app.saveViews()
+}
+
+// Removeview removes a named view from the model
+func (app *RenderingApp) Removeview(cmd Command) {
+	if _, ok := app.modelConfig.Views[cmd.Val]; !ok {
+		app.sendMessageToClient("removeview", "unknown view "+cmd.Val)
+		return
+	}
+	delete(app.modelConfig.Views, cmd.Val)
+	app.saveViews()
+}
+
+// Views sends the builtin and custom standard views of the model
+func (app *RenderingApp) Views(cmd Command) {
+	views := viewList{Builtin: builtinViews, Custom: app.modelConfig.Views}
+	if views.Custom == nil {
+		views.Custom = map[string]ViewDirection{}
+	}
+	app.sendDataToClient("views", views)
+}
+
+// saveViews writes the custom views into the configuration file of a local model and sends the updated list
+func (app *RenderingApp) saveViews() {
+	if !IsRemoteModel(app.modelpath) {
+		if err := saveModelViews(app.modelpath, app.modelConfig.Views); err != nil {
+			app.log.Error("saving views failed: %v", err)
+			app.sendMessageToClient("views", err.Error())
+		}
+	}
+	app.Views(Command{})
+}
+
+// isBuiltinView returns true for the names of the builtin views
+func isBuiltinView(name string) bool {
+	for _, v := range builtinViews {
+		if v == name {
+			return true
+		}
+	}
+	return false
+}
+
+// customView returns the direction of a custom view by name
+func (app *RenderingApp) customView(name string) (ViewDirection, bool) {
+	view, ok := app.modelConfig.Views[name]
+	return view, ok
+}
+
+// saveModelViews sets the views of a model configuration file, keeping all other settings
+func saveModelViews(modelpath string, views map[string]ViewDirection) error {
+	path := strings.TrimSuffix(modelpath, filepath.Ext(modelpath)) + ".json"
+	config := make(map[string]json.RawMessage)
+	if data, err := ioutil.ReadFile(path); err == nil {
+		if err := json.Unmarshal(data, &config); err != nil {
+			return fmt.Errorf("invalid model configuration %s: %v", path, err)
+		}
+	}
+	data, err := json.Marshal(views)
+	if err != nil {
+		return err
+	}
+	config["views"] = data
+	if len(views) == 0 {
+		delete(config, "views")
+	}
+	data, err = json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, data, 0644)
+}
+
+// parseView parses name[,dx,dy,dz[,ux,uy,uz]], the view is nil without direction
+func parseView(value string) (string, *ViewDirection, error) {
+	s := strings.Split(value, ",")
+	name := strings.TrimSpace(s[0])
+	if name == "" {
+		return "", nil, fmt.Errorf("missing view name")
+	}
+	if isBuiltinView(name) {
+		return "", nil, fmt.Errorf("view %s is builtin", name)
+	}
+	if len(s) == 1 {
+		return name, nil, nil
+	}
+	if len(s) != 4 && len(s) != 7 {
+		return "", nil, fmt.Errorf("invalid view %s, expected name[,dx,dy,dz[,ux,uy,uz]]", value)
+	}
+	numbers := make([]float32, len(s)-1)
+	for i, v := range s[1:] {
+		n, err := strconv.ParseFloat(strings.TrimSpace(v), 32)
+		if err != nil {
+			return "", nil, fmt.Errorf("invalid number %s", v)
+		}
+		numbers[i] = float32(n)
+	}
+	view := &ViewDirection{Direction: [3]float32{numbers[0], numbers[1], numbers[2]}, Up: [3]float32{0, 1, 0}}
+	if len(numbers) == 6 {
+		view.Up = [3]float32{numbers[3], numbers[4], numbers[5]}
+	}
+	direction := math32.Vector3{X: view.Direction[0], Y: view.Direction[1], Z: view.Direction[2]}
+	if direction.Length() == 0 {
+		return "", nil, fmt.Errorf("view direction must not be zero")
+	}
+	return name, view, nil
+}
@@ -0,0 +1,52 @@
+package renderer
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseView(t *testing.T) {
+	name, view, err := parseView("entrance, 0, -0.5, -1")
+	assert(t, err, nil)
+	assert(t, name, "entrance")
+	assert(t, view.Direction, [3]float32{0, -0.5, -1})
+	assert(t, view.Up, [3]float32{0, 1, 0})
+
+	_, view, err = parseView("plan,0,-1,0,0,0,-1")
+	assert(t, err, nil)
+	assert(t, view.Up, [3]float32{0, 0, -1})
+
+	name, view, err = parseView("current")
+	assert(t, err, nil)
+	assert(t, name, "current")
+	if view != nil {
+		t.Error("view without direction has a direction")
+	}
+
+	for _, value := range []string{"", "top", "a,1,2", "a,0,0,0", "a,x,1,1"} {
+		if _, _, err := parseView(value); err == nil {
+			t.Error("accepted invalid view", value)
+		}
+	}
+}
+
+func TestSaveModelViews(t *testing.T) {
+	dir, err := ioutil.TempDir("", "views")
+	assert(t, err, nil)
+	defer os.RemoveAll(dir)
+	model := filepath.Join(dir, "Building.gltf")
+	assert(t, ioutil.WriteFile(filepath.Join(dir, "Building.json"), []byte(`{"unit": "mm"}`), 0644), nil)
+
+	views := map[string]ViewDirection{"entrance": {Direction: [3]float32{0, 0, -1}, Up: [3]float32{0, 1, 0}}}
+	assert(t, saveModelViews(model, views), nil)
+	config := loadModelConfig(model)
+	assert(t, config.Unit, "mm")
+	assert(t, config.Views["entrance"], views["entrance"])
+
+	assert(t, saveModelViews(model, nil), nil)
+	config = loadModelConfig(model)
+	assert(t, config.Unit, "mm")
+	assert(t, len(config.Views), 0)
+}
@@ -0,0 +1,75 @@
+package renderer
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Shownodes shows nodes by their ids of the scene graph, given comma separated or as json array
+func (app *RenderingApp) Shownodes(cmd Command) {
+	app.setNodesVisible(cmd, true)
+}
+
+// Hidenodes hides nodes by their ids of the scene graph, given comma separated or as json array
+func (app *RenderingApp) Hidenodes(cmd Command) {
+	app.setNodesVisible(cmd, false)
+}
+
+// setNodesVisible changes the visibility of the nodes listed by a command, unknown ids are reported to the client
+func (app *RenderingApp) setNodesVisible(cmd Command, visible bool) {
+	ids, err := parseNodeIds(cmd.Val)
+	if err != nil {
+		app.sendMessageToClient(strings.ToLower(cmd.Cmd), err.Error())
+		return
+	}
+	var changed, unknown []string
+	for _, id := range ids {
+		inode, ok := app.nodeBuffer[id]
+		if !ok {
+			unknown = append(unknown, id)
+			continue
+		}
+		inode.GetNode().SetVisible(visible)
+		changed = append(changed, id)
+	}
+	if len(changed) > 0 {
+		sort.Strings(changed)
+		action := "shown"
+		if !visible {
+			action = "hidden"
+		}
+		app.audit(action, strings.Join(changed, ","), true)
+	}
+	if len(unknown) > 0 {
+		app.sendMessageToClient(strings.ToLower(cmd.Cmd), "unknown nodes "+strings.Join(unknown, ","))
+	}
+}
+
+// parseNodeIds returns the node ids of a comma separated list or a json array, duplicates are removed
+func parseNodeIds(value string) ([]string, error) {
+	value = strings.TrimSpace(value)
+	var list []string
+	if strings.HasPrefix(value, "[") {
+		if err := json.Unmarshal([]byte(value), &list); err != nil {
+			return nil, fmt.Errorf("invalid node list %s", value)
+		}
+	} else {
+		list = strings.Split(value, ",")
+	}
+	var ids []string
+	seen := make(map[string]bool)
+	for _, id := range list {
+		id = strings.TrimSpace(id)
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		ids = append(ids, id)
+	}
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("no node ids given")
+	}
+	return ids, nil
+}
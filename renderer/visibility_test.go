@@ -0,0 +1,23 @@
+package renderer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseNodeIds(t *testing.T) {
+	ids, err := parseNodeIds(" /0/1, /0/2 ,/0/1,")
+	assert(t, err, nil)
+	assert(t, strings.Join(ids, ";"), "/0/1;/0/2")
+
+	ids, err = parseNodeIds(`["/0/3", "/0/3/1"]`)
+	assert(t, err, nil)
+	assert(t, strings.Join(ids, ";"), "/0/3;/0/3/1")
+
+	if _, err = parseNodeIds(" , "); err == nil {
+		t.Error("empty list accepted")
+	}
+	if _, err = parseNodeIds(`["/0/1"`); err == nil {
+		t.Error("invalid json accepted")
+	}
+}
@@ -0,0 +1,99 @@
+package renderer
+
+import (
+	"os"
+	"time"
+)
+
+// WatchModels reloads the scene of all sessions when their model file changes on disk
+var WatchModels = false
+
+// watchInterval is the interval of model file checks
+const watchInterval = time.Second
+
+// modelWatch tracks the version of the model file on disk
+type modelWatch struct {
+	loaded  os.FileInfo // version shown in the scene
+	seen    os.FileInfo // version seen on the last check
+	pending bool        // set while a reload is queued
+}
+
+// changed returns true if the file differs from the loaded version
+// and has not been written to since the last check
+func (w *modelWatch) changed(info os.FileInfo) bool {
+	if sameFile(info, w.loaded) {
+		return false
+	}
+	if !sameFile(info, w.seen) {
+		w.seen = info
+		return false
+	}
+	return true
+}
+
+// sameFile returns true if both file infos have the same size and modification time
+func sameFile(a os.FileInfo, b os.FileInfo) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Size() == b.Size() && a.ModTime().Equal(b.ModTime())
+}
+
+// watchModel starts watching the local model file
+func (app *RenderingApp) watchModel() {
+	if !WatchModels || IsRemoteModel(app.modelpath) {
+		return
+	}
+	info, err := os.Stat(app.modelpath)
+	if err != nil {
+		return
+	}
+	app.watch = modelWatch{loaded: info, seen: info}
+	app.SetInterval(watchInterval, nil, app.checkModel)
+}
+
+// checkModel queues a reload once the model file has changed
+func (app *RenderingApp) checkModel(arg interface{}) {
+	if app.watch.pending {
+		return
+	}
+	info, err := os.Stat(app.modelpath)
+	if err != nil || !app.watch.changed(info) {
+		return
+	}
+	// the render thread must not wait for the command goroutine, a full queue retries on the next check
+	select {
+	case app.commandUpdates <- func() { app.reloadScene(info) }:
+		app.watch.pending = true
+	default:
+	}
+}
+
+// reloadScene replaces the model by the current file, keeping the camera.
+// It runs on the command goroutine, which waits for the render thread to load the scene.
+func (app *RenderingApp) reloadScene(info os.FileInfo) {
+	var err error
+	reloaded := false
+	ok := app.renderSync(func() {
+		// switching the model in the meantime resets the watch and drops the reload
+		if !app.watch.pending {
+			return
+		}
+		reloaded = true
+		app.watch.pending = false
+		app.watch.loaded = info
+		app.log.Info("reloading %s", app.modelpath)
+		app.resetSelection()
+		err = app.loadScene(app.modelpath)
+	})
+	if !ok || !reloaded {
+		return
+	}
+	if err != nil {
+		app.log.Error("reloading scene failed: %v", err)
+		app.sendMessageToClient("model-reload-failed", err.Error())
+		return
+	}
+	app.audit("reload", app.modelpath, true)
+	app.sendMessageToClient("model-reloaded", app.modelpath)
+}
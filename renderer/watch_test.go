@@ -0,0 +1,34 @@
+package renderer
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+// fileInfo is a minimal os.FileInfo
+type fileInfo struct {
+	size    int64
+	modTime time.Time
+}
+
+func (f fileInfo) Name() string       { return "model.glb" }
+func (f fileInfo) Size() int64        { return f.size }
+func (f fileInfo) Mode() os.FileMode  { return 0644 }
+func (f fileInfo) ModTime() time.Time { return f.modTime }
+func (f fileInfo) IsDir() bool        { return false }
+func (f fileInfo) Sys() interface{}   { return nil }
+
+func TestModelWatchChanged(t *testing.T) {
+	now := time.Now()
+	v1 := fileInfo{size: 10, modTime: now}
+	w := modelWatch{loaded: v1, seen: v1}
+	assert(t, w.changed(v1), false)
+
+	// a file being written is reloaded once it stopped changing
+	v2 := fileInfo{size: 20, modTime: now.Add(time.Second)}
+	assert(t, w.changed(v2), false)
+	v3 := fileInfo{size: 30, modTime: now.Add(2 * time.Second)}
+	assert(t, w.changed(v3), false)
+	assert(t, w.changed(v3), true)
+}
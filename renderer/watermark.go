@@ -0,0 +1,80 @@
+package renderer
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+// margin of the watermark to the image border in pixels
+const watermarkMargin = 10
+
+// Watermark brands snapshots and recordings with a logo at the bottom right and a caption at the bottom left
+type Watermark struct {
+	Logo    image.Image
+	Opacity float32 // opacity of the logo from 0 to 1
+	Caption string  // placeholders {model}, {time} and {user} are replaced
+}
+
+// DefaultWatermark is applied to snapshots and recordings of all sessions, the zero value disables it
+var DefaultWatermark Watermark
+
+// enabled returns true if there is anything to draw
+func (w Watermark) enabled() bool {
+	return w.Logo != nil || w.Caption != ""
+}
+
+// watermark returns a branded copy of a frame, or the frame itself if no watermark is configured
+func (app *RenderingApp) watermark(img *image.RGBA) *image.RGBA {
+	if !DefaultWatermark.enabled() {
+		return img
+	}
+	caption := expandCaption(DefaultWatermark.Caption, filepath.Base(app.modelpath), app.loadOptions.User, time.Now())
+	return DefaultWatermark.draw(img, caption)
+}
+
+// draw returns a copy of an image with logo and caption
+func (w Watermark) draw(img *image.RGBA, caption string) *image.RGBA {
+	b := img.Bounds()
+	dst := image.NewRGBA(b)
+	copy(dst.Pix, img.Pix)
+
+	if w.Logo != nil {
+		lb := w.Logo.Bounds()
+		at := image.Pt(b.Max.X-watermarkMargin-lb.Dx(), b.Max.Y-watermarkMargin-lb.Dy())
+		mask := image.NewUniform(color.Alpha{A: uint8(clamp01(w.Opacity) * 255)})
+		draw.DrawMask(dst, lb.Sub(lb.Min).Add(at), w.Logo, lb.Min, mask, image.Point{}, draw.Over)
+	}
+	if caption != "" {
+		face := basicfont.Face7x13
+		width := font.MeasureString(face, caption).Ceil()
+		box := image.Rect(b.Min.X+watermarkMargin-4, b.Max.Y-watermarkMargin-17, b.Min.X+watermarkMargin+width+4, b.Max.Y-watermarkMargin)
+		draw.Draw(dst, box, image.NewUniform(color.RGBA{A: 128}), image.Point{}, draw.Over)
+		d := font.Drawer{Dst: dst, Src: image.White, Face: face, Dot: fixed.P(b.Min.X+watermarkMargin, b.Max.Y-watermarkMargin-4)}
+		d.DrawString(caption)
+	}
+	return dst
+}
+
+// expandCaption replaces the placeholders of a caption
+func expandCaption(caption string, model string, user string, t time.Time) string {
+	return strings.NewReplacer("{model}", model, "{time}", t.Format("2006-01-02 15:04"), "{user}", user).Replace(caption)
+}
+
+// clamp01 limits a value to the range from 0 to 1
+func clamp01(v float32) float32 {
+	if v < 0 {
+		return 0
+	}
+	if v > 1 {
+		return 1
+	}
+	return v
+}
@@ -0,0 +1,27 @@
+package renderer
+
+import (
+	"image"
+	"image/color"
+	"testing"
+	"time"
+)
+
+func TestExpandCaption(t *testing.T) {
+	at := time.Date(2020, 6, 1, 14, 30, 0, 0, time.UTC)
+	assert(t, expandCaption("{model} by {user}, {time}", "Cathedral.glb", "Ann", at), "Cathedral.glb by Ann, 2020-06-01 14:30")
+}
+
+func TestWatermarkDraw(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 100, 100))
+	logo := image.NewRGBA(image.Rect(0, 0, 20, 10))
+	for i := 0; i < len(logo.Pix); i += 4 {
+		logo.Pix[i], logo.Pix[i+3] = 255, 255
+	}
+	w := Watermark{Logo: logo, Opacity: 1}
+	out := w.draw(img, "")
+	assert(t, out.RGBAAt(85, 85), color.RGBA{R: 255, A: 255})
+	assert(t, out.RGBAAt(50, 50), color.RGBA{})
+	// the frame itself is left unchanged
+	assert(t, img.RGBAAt(85, 85), color.RGBA{})
+}
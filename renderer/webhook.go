@@ -0,0 +1,87 @@
+package renderer
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Events sent to webhooks
+const (
+	EventSessionStarted    = "session.started"
+	EventSessionClosed     = "session.closed"
+	EventNodeSelected      = "node.selected"
+	EventAnnotationCreated = "annotation.created"
+	EventScreenshotTaken   = "screenshot.taken"
+)
+
+// Webhooks receiving viewer events, empty by default
+var Webhooks []Webhook
+
+// Webhook posts viewer events as JSON to an URL
+type Webhook struct {
+	URL    string
+	Events []string // empty list receives all events
+}
+
+// webhookPayload is the JSON body posted to webhooks
+type webhookPayload struct {
+	Event   string      `json:"event"`
+	Session string      `json:"session"`
+	Time    time.Time   `json:"time"`
+	Data    interface{} `json:"data,omitempty"`
+}
+
+var webhookClient = &http.Client{Timeout: 5 * time.Second}
+
+// ParseWebhook parses an URL with an optional comma separated event filter, e.g.
+// http://example.com/hook#node.selected,session.started
+func ParseWebhook(value string) Webhook {
+	s := strings.SplitN(value, "#", 2)
+	hook := Webhook{URL: s[0]}
+	if len(s) == 2 && s[1] != "" {
+		hook.Events = strings.Split(s[1], ",")
+	}
+	return hook
+}
+
+// accepts returns true if the webhook subscribed to the event
+func (h Webhook) accepts(event string) bool {
+	if len(h.Events) == 0 {
+		return true
+	}
+	for _, e := range h.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// FireEvent posts an event to all subscribed webhooks without blocking the caller
+func FireEvent(event string, session string, data interface{}) {
+	if len(Webhooks) == 0 {
+		return
+	}
+	body, err := json.Marshal(webhookPayload{Event: event, Session: session, Time: time.Now().UTC(), Data: data})
+	if err != nil {
+		log.Println("webhook:", err)
+		return
+	}
+	for _, hook := range Webhooks {
+		if !hook.accepts(event) {
+			continue
+		}
+		go func(url string) {
+			resp, err := webhookClient.Post(url, "application/json", bytes.NewReader(body))
+			if err != nil {
+				log.Println("webhook:", err)
+				return
+			}
+			resp.Body.Close()
+		}(hook.URL)
+	}
+}
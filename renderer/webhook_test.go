@@ -0,0 +1,21 @@
+package renderer
+
+import (
+	"testing"
+)
+
+func TestParseWebhook(t *testing.T) {
+	hook := ParseWebhook("http://localhost/hook")
+	assert(t, hook.URL, "http://localhost/hook")
+	assert(t, hook.accepts(EventNodeSelected), true)
+
+	hook = ParseWebhook("http://localhost/hook#session.started,session.closed")
+	assert(t, hook.URL, "http://localhost/hook")
+	assert(t, len(hook.Events), 2)
+	assert(t, hook.accepts(EventSessionClosed), true)
+	assert(t, hook.accepts(EventNodeSelected), false)
+
+	hook = ParseWebhook("http://localhost/hook#annotation.created")
+	assert(t, hook.accepts(EventAnnotationCreated), true)
+	assert(t, hook.accepts(EventScreenshotTaken), false)
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"sync"
+
+	"github.com/moethu/webg3n/renderer"
+)
+
+// participantColors are assigned to the participants of a room in order of joining
+var participantColors = []string{"#e6194b", "#3cb44b", "#4363d8", "#f58231", "#911eb4", "#42d4f4", "#f032e6", "#9a6324"}
+
+// participant is a session within a room
+type participant struct {
+	client   *Client
+	name     string
+	color    string
+	presence renderer.Presence
+}
+
+// roomManager groups sessions viewing a model together, participants see each other's cursors and selections
+type roomManager struct {
+	mu      sync.Mutex
+	rooms   map[string][]*participant // by room name
+	members map[string]string         // room name by session id
+}
+
+// newRoomManager creates a manager without rooms
+func newRoomManager() *roomManager {
+	return &roomManager{rooms: make(map[string][]*participant), members: make(map[string]string)}
+}
+
+// join adds a session to a room, the room is created by its first participant
+func (m *roomManager) join(room string, client *Client, name string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	p := &participant{client: client, name: name, color: m.freeColor(room), presence: renderer.Presence{Selection: []string{}}}
+	m.rooms[room] = append(m.rooms[room], p)
+	m.members[client.id] = room
+	m.broadcast(room)
+}
+
+// leave removes a session from its room, empty rooms are closed
+func (m *roomManager) leave(id string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	room, ok := m.members[id]
+	if !ok {
+		return
+	}
+	delete(m.members, id)
+	participants := m.rooms[room]
+	for i, p := range participants {
+		if p.client.id == id {
+			m.rooms[room] = append(participants[:i:i], participants[i+1:]...)
+			break
+		}
+	}
+	if len(m.rooms[room]) == 0 {
+		delete(m.rooms, room)
+		return
+	}
+	m.broadcast(room)
+}
+
+// update stores the presence of a session and shows it to the other participants of its room
+func (m *roomManager) update(id string, presence renderer.Presence) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	room, ok := m.members[id]
+	if !ok {
+		return
+	}
+	for _, p := range m.rooms[room] {
+		if p.client.id == id {
+			p.presence = presence
+		}
+	}
+	m.broadcast(room)
+}
+
+// chat sends a message of a session to all participants of its room, including the sender
+func (m *roomManager) chat(id string, message renderer.ChatMessage) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	room, ok := m.members[id]
+	if !ok {
+		return
+	}
+	for _, p := range m.rooms[room] {
+		if p.client.id == id {
+			message.Name, message.Color = p.name, p.color
+		}
+	}
+	for _, p := range m.rooms[room] {
+		renderer.ShowChat(&p.client.app, message)
+	}
+}
+
+// broadcast sends every participant of a room all others
+func (m *roomManager) broadcast(room string) {
+	participants := m.rooms[room]
+	for _, p := range participants {
+		others := []renderer.Participant{}
+		for _, o := range participants {
+			if o != p {
+				others = append(others, renderer.Participant{Name: o.name, Color: o.color, Presence: o.presence})
+			}
+		}
+		p.client.app.ShowParticipants(others)
+	}
+}
+
+// freeColor returns the first color not used within a room, colors repeat in rooms with many participants
+func (m *roomManager) freeColor(room string) string {
+	used := make(map[string]bool)
+	for _, p := range m.rooms[room] {
+		used[p.color] = true
+	}
+	for _, c := range participantColors {
+		if !used[c] {
+			return c
+		}
+	}
+	return participantColors[len(m.rooms[room])%len(participantColors)]
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/moethu/webg3n/renderer"
+)
+
+func TestRoomColors(t *testing.T) {
+	m := newRoomManager()
+	a, b, c := &Client{id: "a"}, &Client{id: "b"}, &Client{id: "c"}
+	m.join("review", a, "Ann")
+	m.join("review", b, "Bob")
+	if m.rooms["review"][0].color == m.rooms["review"][1].color {
+		t.Error("participants share a color")
+	}
+	m.leave("a")
+	m.join("review", c, "Cid")
+	if m.rooms["review"][1].color != participantColors[0] {
+		t.Error("color of leaving participant not reused:", m.rooms["review"][1].color)
+	}
+}
+
+func TestRoomLeave(t *testing.T) {
+	m := newRoomManager()
+	m.join("review", &Client{id: "a"}, "Ann")
+	m.update("a", renderer.Presence{Selection: []string{"wall"}})
+	if m.rooms["review"][0].presence.Selection[0] != "wall" {
+		t.Error("presence not stored")
+	}
+	m.leave("a")
+	m.leave("unknown")
+	if len(m.rooms) != 0 || len(m.members) != 0 {
+		t.Error("empty room not closed")
+	}
+}
@@ -0,0 +1,3 @@
+-- hides all nodes tagged as furniture in the gltf extras
+hide(find("userdata.category == furniture"))
+zoomextent()
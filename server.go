@@ -1,9 +1,12 @@
 package main
 
 import (
+	"encoding/json"
 	"log"
+	"net/http"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/moethu/webg3n/renderer"
@@ -17,127 +20,236 @@ const (
 	writeTimeout   = 10 * time.Second
 	readTimeout    = 60 * time.Second
 	pingPeriod     = (readTimeout * 9) / 10
-	maxMessageSize = 512
+	maxMessageSize = 64 << 10 // large enough for inline scripts
 )
 
 // Client holding g3napp, socket and channels
 type Client struct {
-	app renderer.RenderingApp
+	id    string
+	model string
+	addr  string // address of the client
+	user  string // name given by the client
+	app   renderer.RenderingApp
+	log   *renderer.Logger
 
-	// The websocket connection.
-	conn *websocket.Conn
+	// The websocket or WebTransport connection.
+	conn transport
 
 	// Buffered channels messages.
 	write chan []byte // images and data to client
 	read  chan []byte // commands from client
+
+	// closed once the rendering app has finished
+	done chan struct{}
 }
 
-// streamReader reads messages from the websocket connection and fowards them to the read channel
+// streamReader reads messages from the connection and fowards them to the read channel
 func (c *Client) streamReader() {
-	defer func() {
-		c.conn.Close()
-	}()
-	c.conn.SetReadLimit(maxMessageSize)
-	c.conn.SetReadDeadline(time.Now().Add(readTimeout))
-	// SetPongHandler sets the handler for pong messages received from the peer.
-	c.conn.SetPongHandler(func(string) error { c.conn.SetReadDeadline(time.Now().Add(readTimeout)); return nil })
+	c.conn.keepAlive()
 	for {
-		_, message, err := c.conn.ReadMessage()
+		message, err := c.conn.readMessage()
 		if err != nil {
 			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
-				log.Printf("error: %v", err)
+				c.log.Error("websocket: %v", err)
 			}
-			break
+			c.conn.close(websocket.CloseNoStatusReceived, "")
+			return
 		}
+		sessions.touch(c.id)
 		// feed message to command channel
-		c.read <- message
+		select {
+		case c.read <- message:
+		case <-c.done:
+			return
+		}
 	}
 }
 
-// streamWriter writes messages from the write channel to the websocket connection
+// streamWriter writes messages from the write channel to the connection
 func (c *Client) streamWriter() {
 	ticker := time.NewTicker(pingPeriod)
-	defer func() {
-		ticker.Stop()
-		c.conn.Close()
-	}()
+	defer ticker.Stop()
 	for {
 		// Go’s select lets you wait on multiple channel operations.
 		// We’ll use select to await both of these values simultaneously.
 		select {
 		case message, ok := <-c.write:
-			c.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
 			if !ok {
-				c.conn.WriteMessage(websocket.CloseMessage, []byte{})
+				c.conn.close(websocket.CloseNoStatusReceived, "")
 				return
 			}
 
-			// NextWriter returns a writer for the next message to send.
-			// The writer's Close method flushes the complete message to the network.
-			w, err := c.conn.NextWriter(websocket.TextMessage)
-			if err != nil {
+			if err := c.conn.writeMessage(message); err != nil {
+				c.conn.close(websocket.CloseNoStatusReceived, "")
 				return
 			}
-			w.Write(message)
 
-			// Add queued messages to the current websocket message
+			// Write queued messages right away
 			n := len(c.write)
 			for i := 0; i < n; i++ {
-				w.Write(<-c.write)
+				if err := c.conn.writeMessage(<-c.write); err != nil {
+					c.conn.close(websocket.CloseNoStatusReceived, "")
+					return
+				}
 			}
 
-			if err := w.Close(); err != nil {
-				return
-			}
+		// rendering app has finished, all frames have been written
+		case <-c.done:
+			c.conn.close(websocket.CloseGoingAway, "")
+			return
 
 		//a channel that will send the time with a period specified by the duration argument
 		case <-ticker.C:
-			// SetWriteDeadline sets the deadline for future Write calls
-			// and any currently-blocked Write call.
-			// Even if write times out, it may return n > 0, indicating that
-			// some of the data was successfully written.
-			c.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
-			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+			if err := c.conn.ping(); err != nil {
+				c.conn.close(websocket.CloseNoStatusReceived, "")
 				return
 			}
 		}
 	}
 }
 
+// isJSONMessage returns true for messages and data, false for image frames
+func isJSONMessage(message []byte) bool {
+	return len(message) > 0 && message[0] == '{'
+}
+
 // serveWebsocket handles websocket requests from the peer.
 func serveWebsocket(c *gin.Context) {
+	if sessions.isDraining() || sessions.isFull() {
+		c.AbortWithStatus(http.StatusServiceUnavailable)
+		return
+	}
 	sessionId := uuid.NewV4()
-	// upgrade connection to websocket
-	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+	sessionLog := renderer.NewLogger(sessionId.String(), c.ClientIP())
+
+	modelPath := strings.TrimSuffix(*modelDir, "/") + "/"
+	model := c.Request.URL.Query().Get("model")
+	if model == "" {
+		model = *defaultModel
+	}
+	if renderer.IsRemoteModel(model) {
+		if !renderer.IsAllowedModelSource(model) {
+			sessionLog.Warn("model source not allowed: %s", model)
+			model = modelPath + *defaultModel
+		}
+	} else {
+		if _, err := os.Stat(modelPath + model); os.IsNotExist(err) {
+			model = *defaultModel
+		}
+		model = modelPath + model
+	}
+
+	// get scene width and height from url query params
+	// default to the server resolution if they are not set
+	settingsMutex.RLock()
+	height := getParameterDefault(c, "h", *defaultHeight)
+	width := getParameterDefault(c, "w", *defaultWidth)
+
+	// mesh simplification and texture settings, defaulting to server settings
+	options := renderer.DefaultLoadOptions
+	settingsMutex.RUnlock()
+
+	// an orchestrator may move the session to a dedicated host, sessions run locally if it fails
+	requested := hookEvent{Event: hookSessionRequested, Session: sessionId.String(), Client: c.ClientIP(), Model: strings.TrimPrefix(model, modelPath), Width: width, Height: height}
+	decision, err := hook.call(requested)
 	if err != nil {
-		log.Println(err)
-		return
+		sessionLog.Warn("session hook: %v", err)
+	}
+	// every requested session is ended for the hook, also if it failed to start
+	endSession := func(duration time.Duration) {
+		go func() {
+			if _, err := hook.call(endEvent(requested, duration)); err != nil {
+				sessionLog.Warn("session hook: %v", err)
+			}
+		}()
 	}
-	conn.EnableWriteCompression(true)
 
+	// upgrade connection to websocket or WebTransport
+	conn, err := upgradeClient(c)
+	if err != nil {
+		sessionLog.Error("websocket upgrade: %v", err)
+		endSession(0)
+		return
+	}
+	if decision.Reject != "" {
+		sessionLog.Info("session rejected: %s", decision.Reject)
+		conn.close(websocket.CloseTryAgainLater, decision.Reject)
+		return
+	}
+	if decision.Redirect != "" {
+		sessionLog.Info("session redirected to %s", decision.Redirect)
+		if message, err := json.Marshal(renderer.Message{Action: "redirect", Value: decision.Redirect}); err == nil {
+			conn.writeMessage(message)
+		}
+		conn.close(websocket.CloseNormalClosure, "redirect")
+		return
+	}
 	// create two channels for read write concurrency
 	cWrite := make(chan []byte)
 	cRead := make(chan []byte)
 
-	client := &Client{conn: conn, write: cWrite, read: cRead}
-
-	// get scene width and height from url query params
-	// default to 800 if they are not set
-	height := getParameterDefault(c, "h", 800)
-	width := getParameterDefault(c, "w", 800)
+	client := &Client{id: sessionId.String(), model: strings.TrimPrefix(model, modelPath), addr: c.ClientIP(), user: c.Request.URL.Query().Get("name"), log: sessionLog, conn: conn, write: cWrite, read: cRead, done: make(chan struct{})}
+	if !sessions.add(sessionId.String(), client) {
+		reason := "server-busy"
+		if sessions.isDraining() {
+			reason = "server-closing"
+		}
+		conn.close(websocket.CloseTryAgainLater, reason)
+		endSession(0)
+		return
+	}
+	sessionLog.Info("session started")
+	renderer.FireEvent(renderer.EventSessionStarted, sessionId.String(), map[string]string{"client": c.ClientIP()})
 
-	modelPath := "models/"
-	defaultModel := "Cathedral.glb"
-	model := c.Request.URL.Query().Get("model")
-	if model == "" {
-		model = defaultModel
+	if maxTriangles, err := strconv.Atoi(c.Request.URL.Query().Get("maxtriangles")); err == nil {
+		options.MaxTriangles = maxTriangles
+	}
+	if tolerance, err := strconv.ParseFloat(c.Request.URL.Query().Get("tolerance"), 32); err == nil {
+		options.Tolerance = float32(tolerance)
+	}
+	// clients may only lower the server's texture limit
+	if maxTexture, err := strconv.Atoi(c.Request.URL.Query().Get("maxtexture")); err == nil && maxTexture > 0 {
+		if options.MaxTexture == 0 || maxTexture < options.MaxTexture {
+			options.MaxTexture = maxTexture
+		}
+	}
+	if progressive, err := strconv.ParseBool(c.Request.URL.Query().Get("progressive")); err == nil {
+		options.Progressive = progressive
 	}
-	if _, err := os.Stat(modelPath + model); os.IsNotExist(err) {
-		model = defaultModel
+	options.Checksum = c.Request.URL.Query().Get("sha256")
+	options.User = c.Request.URL.Query().Get("name")
+	if renderer.JournalDir != "" {
+		// clients reconnect with the token of their journal to restore their state
+		options.Journal = sessionId.String()
+		if token := c.Request.URL.Query().Get("journal"); token != "" {
+			if key, ok := renderer.JournalKey(token); ok {
+				options.Journal = key
+			} else {
+				sessionLog.Warn("invalid journal token, starting a new journal")
+			}
+		}
+	}
+
+	// sessions of the same room share cursors and selections
+	if room := c.Request.URL.Query().Get("room"); room != "" {
+		name := c.Request.URL.Query().Get("name")
+		if name == "" {
+			name = "guest"
+		}
+		rooms.join(room, client, name)
 	}
 
 	// run 3d application in separate go routine
-	go renderer.LoadRenderingApp(&client.app, sessionId.String(), height, width, cWrite, cRead, modelPath+model)
+	go func() {
+		defer sessions.remove(sessionId.String())
+		defer rooms.leave(sessionId.String())
+		defer close(client.done)
+		started := time.Now()
+		renderer.LoadRenderingApp(&client.app, sessionLog, height, width, cWrite, cRead, model, options)
+		sessionLog.Info("session closed")
+		renderer.FireEvent(renderer.EventSessionClosed, sessionId.String(), nil)
+		endSession(time.Since(started))
+	}()
 
 	// run reader and writer in two different go routines
 	// so they can act concurrently
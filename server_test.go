@@ -0,0 +1,12 @@
+package main
+
+import "testing"
+
+func TestIsJSONMessage(t *testing.T) {
+	if !isJSONMessage([]byte(`{"action":"loaded"}`)) {
+		t.Error("message not detected")
+	}
+	if isJSONMessage([]byte("/9j/4AAQSkZJRg")) || isJSONMessage(nil) {
+		t.Error("image frame detected as message")
+	}
+}
@@ -0,0 +1,221 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// session holds a client and its lifetime information
+type session struct {
+	client     *Client
+	started    time.Time
+	lastActive time.Time
+	warned     bool
+	evicted    bool
+}
+
+// expiry returns the time a session gets evicted,
+// a zero time means the session never expires
+func (s *session) expiry(ttl time.Duration, idle time.Duration) time.Time {
+	var t time.Time
+	if ttl > 0 {
+		t = s.started.Add(ttl)
+	}
+	if idle > 0 {
+		i := s.lastActive.Add(idle)
+		if t.IsZero() || i.Before(t) {
+			t = i
+		}
+	}
+	return t
+}
+
+// sessionInfo describes a running session for the admin API
+type sessionInfo struct {
+	ID        string    `json:"id"`
+	Client    string    `json:"client"`
+	User      string    `json:"user,omitempty"`
+	Model     string    `json:"model"`
+	GPU       string    `json:"gpu"`
+	Started   time.Time `json:"started"`
+	Uptime    float64   `json:"uptime"` // seconds
+	Idle      float64   `json:"idle"`   // seconds since the last client message
+	BytesSent int64     `json:"bytes_sent"`
+	Bandwidth float64   `json:"bandwidth"` // average bytes per second
+}
+
+// SessionManager keeps track of all running client sessions
+type SessionManager struct {
+	mu       sync.Mutex
+	sessions map[string]*session
+	draining bool
+	limit    int // maximum number of sessions, 0 is unlimited
+	wg       sync.WaitGroup
+}
+
+// NewSessionManager creates an empty session manager
+func NewSessionManager() *SessionManager {
+	return &SessionManager{sessions: make(map[string]*session)}
+}
+
+// add registers a client session, returns false if the manager is draining or full
+func (m *SessionManager) add(id string, client *Client) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.draining || (m.limit > 0 && len(m.sessions) >= m.limit) {
+		return false
+	}
+	now := time.Now()
+	m.sessions[id] = &session{client: client, started: now, lastActive: now}
+	m.wg.Add(1)
+	return true
+}
+
+// remove unregisters a client session once its rendering app has finished
+func (m *SessionManager) remove(id string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.sessions[id]; ok {
+		delete(m.sessions, id)
+		m.wg.Done()
+	}
+}
+
+// touch marks a session as active
+func (m *SessionManager) touch(id string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if s, ok := m.sessions[id]; ok {
+		s.lastActive = time.Now()
+		s.warned = false
+	}
+}
+
+// clients returns the clients of a session or of all sessions showing a model,
+// empty filters match all sessions
+func (m *SessionManager) clients(id string, model string) []*Client {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var clients []*Client
+	for sid, s := range m.sessions {
+		if (id == "" || id == sid) && (model == "" || model == s.client.model) {
+			clients = append(clients, s.client)
+		}
+	}
+	return clients
+}
+
+// list describes all running sessions, oldest first
+func (m *SessionManager) list(now time.Time) []sessionInfo {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	infos := make([]sessionInfo, 0, len(m.sessions))
+	for id, s := range m.sessions {
+		info := sessionInfo{
+			ID:        id,
+			Client:    s.client.addr,
+			User:      s.client.user,
+			Model:     s.client.model,
+			GPU:       gpus.name(0), // a process renders on a single GPU
+			Started:   s.started,
+			Uptime:    now.Sub(s.started).Seconds(),
+			Idle:      now.Sub(s.lastActive).Seconds(),
+			BytesSent: s.client.app.BytesSent(),
+		}
+		if info.Uptime > 0 {
+			info.Bandwidth = float64(info.BytesSent) / info.Uptime
+		}
+		infos = append(infos, info)
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Started.Before(infos[j].Started) })
+	return infos
+}
+
+// setLimit sets the maximum number of sessions, running sessions above the limit are kept
+func (m *SessionManager) setLimit(limit int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.limit = limit
+}
+
+// count returns the number of running sessions
+func (m *SessionManager) count() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.sessions)
+}
+
+// isFull returns true if the session limit is reached
+func (m *SessionManager) isFull() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.limit > 0 && len(m.sessions) >= m.limit
+}
+
+// isDraining returns true once the manager stopped accepting new sessions
+func (m *SessionManager) isDraining() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.draining
+}
+
+// Drain stops accepting new sessions, notifies all connected clients
+// and waits until every session has closed or the context expires
+func (m *SessionManager) Drain(ctx context.Context) error {
+	m.mu.Lock()
+	m.draining = true
+	clients := make([]*Client, 0, len(m.sessions))
+	for _, s := range m.sessions {
+		clients = append(clients, s.client)
+	}
+	m.mu.Unlock()
+
+	for _, client := range clients {
+		go client.app.Shutdown()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		m.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Evict enforces session lifetime and idle timeout.
+// Clients get a warning message before their session is evicted.
+// A zero ttl or idle duration disables the respective limit.
+func (m *SessionManager) Evict(ttl time.Duration, idle time.Duration, warning time.Duration) {
+	if ttl <= 0 && idle <= 0 {
+		return
+	}
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for now := range ticker.C {
+		m.mu.Lock()
+		for _, s := range m.sessions {
+			expiry := s.expiry(ttl, idle)
+			if s.evicted || expiry.IsZero() {
+				continue
+			}
+			if !now.Before(expiry) {
+				s.evicted = true
+				go s.client.app.Disconnect("evicted", "")
+			} else if !s.warned && now.Add(warning).After(expiry) {
+				s.warned = true
+				remaining := fmt.Sprintf("%.0f", expiry.Sub(now).Seconds())
+				go s.client.app.Notify("session-expiring", remaining)
+			}
+		}
+		m.mu.Unlock()
+	}
+}
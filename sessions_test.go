@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestSessionManagerDrain(t *testing.T) {
+	m := NewSessionManager()
+	if !m.add("a", &Client{}) {
+		t.Error("session rejected before draining")
+	}
+	m.remove("a")
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := m.Drain(ctx); err != nil {
+		t.Error("drain without sessions failed:", err)
+	}
+	if !m.isDraining() {
+		t.Error("manager not draining")
+	}
+	if m.add("b", &Client{}) {
+		t.Error("session accepted while draining")
+	}
+}
+
+func TestSessionManagerClients(t *testing.T) {
+	m := NewSessionManager()
+	m.add("a", &Client{model: "Cathedral.glb"})
+	m.add("b", &Client{model: "Building.glb"})
+	assert(t, len(m.clients("", "")), 2)
+	assert(t, len(m.clients("a", "")), 1)
+	assert(t, len(m.clients("", "Building.glb")), 1)
+	assert(t, len(m.clients("a", "Building.glb")), 0)
+}
+
+func TestSessionExpiry(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	s := &session{started: start, lastActive: start.Add(time.Minute)}
+	if !s.expiry(0, 0).IsZero() {
+		t.Error("session without limits expires")
+	}
+	assert(t, s.expiry(time.Hour, 0), start.Add(time.Hour))
+	assert(t, s.expiry(0, time.Minute), start.Add(2*time.Minute))
+	assert(t, s.expiry(time.Hour, time.Minute), start.Add(2*time.Minute))
+	assert(t, s.expiry(time.Minute, time.Hour), start.Add(time.Minute))
+}
+
+func assert(t *testing.T, actual interface{}, expected interface{}) {
+	if actual != expected {
+		t.Error("Actual:", actual, "Expected:", expected)
+	}
+}
+
+func TestSessionManagerLimit(t *testing.T) {
+	m := NewSessionManager()
+	m.limit = 1
+	if !m.add("a", &Client{}) {
+		t.Error("session rejected below limit")
+	}
+	if !m.isFull() {
+		t.Error("manager not full")
+	}
+	if m.add("b", &Client{}) {
+		t.Error("session accepted above limit")
+	}
+}
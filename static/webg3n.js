@@ -1,10 +1,120 @@
 let host = document.currentScript.getAttribute('host');
+// sessions are opened by WebTransport if the server offers it and the browser supports it
+let webtransport = window.WebTransport ? document.currentScript.getAttribute('webtransport') : null;
 
 $(document).ready(function () {
     console.log("ready!");
     fitToContainer(canvas)
 });
 
+// WebTransportSocket offers the interface of a websocket for a session over WebTransport.
+// Commands and JSON messages are sent on one bidirectional stream, each prefixed by its length as 32 bit big endian,
+// every image frame arrives on a unidirectional stream of its own and frames overtaken by newer ones are dropped.
+function WebTransportSocket(url) {
+    let self = this;
+    let encoder = new TextEncoder();
+    let decoder = new TextDecoder();
+    let transport = new WebTransport(url);
+    let writer = null;
+    let opened = false;
+    let closed = false;
+    let accepted = 0; // frame streams accepted
+    let shown = 0; // number of the newest frame shown
+
+    let message = function (data) {
+        if (self.onmessage) {
+            self.onmessage({data: data});
+        }
+    };
+    let close = function (code, reason) {
+        if (closed) {
+            return;
+        }
+        closed = true;
+        if (self.onclose) {
+            self.onclose({code: code, reason: reason, opened: opened});
+        }
+    };
+
+    let readMessages = async function (readable) {
+        let reader = readable.getReader();
+        let buffer = new Uint8Array(0);
+        for (;;) {
+            let {value, done} = await reader.read();
+            if (done) {
+                // the server finished the session, it is closed once all messages were read
+                transport.close();
+                return;
+            }
+            let joined = new Uint8Array(buffer.length + value.length);
+            joined.set(buffer);
+            joined.set(value, buffer.length);
+            buffer = joined;
+            while (buffer.length >= 4) {
+                let size = new DataView(buffer.buffer, buffer.byteOffset).getUint32(0);
+                if (buffer.length < 4 + size) {
+                    break;
+                }
+                message(decoder.decode(buffer.subarray(4, 4 + size)));
+                buffer = buffer.slice(4 + size);
+            }
+        }
+    };
+    let readFrame = async function (stream, number) {
+        let frame = await new Response(stream).text();
+        if (number > shown) {
+            shown = number;
+            message(frame);
+        }
+    };
+    let readFrames = async function (streams) {
+        let reader = streams.getReader();
+        for (;;) {
+            let {value, done} = await reader.read();
+            if (done) {
+                return;
+            }
+            readFrame(value, ++accepted).catch(() => {});
+        }
+    };
+
+    this.send = function (text) {
+        if (!writer) {
+            return;
+        }
+        let data = encoder.encode(text);
+        let frame = new Uint8Array(4 + data.length);
+        new DataView(frame.buffer).setUint32(0, data.length);
+        frame.set(data, 4);
+        writer.write(frame);
+    };
+    this.close = function () {
+        transport.close({closeCode: 1000, reason: ""});
+    };
+
+    transport.ready.then(async function () {
+        let stream = await transport.createBidirectionalStream();
+        writer = stream.writable.getWriter();
+        // an empty message announces the stream to the server
+        await writer.write(new Uint8Array(4));
+        opened = true;
+        if (self.onopen) {
+            self.onopen({});
+        }
+        readMessages(stream.readable).catch(() => {});
+        readFrames(transport.incomingUnidirectionalStreams).catch(() => {});
+    }).catch(function (err) {
+        if (self.onerror) {
+            self.onerror({data: err});
+        }
+    });
+    transport.closed.then(function (info) {
+        close(info.closeCode, info.reason);
+    }).catch(function (err) {
+        close(1006, String(err));
+    });
+}
+
 function fitToContainer(canvas) {
     // Make it visually fill the positioned parent
     canvas.style.width = '100%';
@@ -18,7 +128,12 @@ window.addEventListener("load", function (evt) {
     var canvas = document.getElementById("canvas");
     var spinner = document.getElementById("spinner");
     var selection_ui = document.getElementById("selection");
+    var coordinates_ui = document.getElementById("coordinates");
+    var participants_ui = document.getElementById("participants");
+    var chat_ui = document.getElementById("chat");
+    var messages_ui = document.getElementById("messages");
     var ws;
+    var redirect = null;
     var mouse_moved = false;
     var prev_x = undefined;
     var prev_y = undefined;
@@ -33,11 +148,101 @@ window.addEventListener("load", function (evt) {
         if (ws) {
             return false;
         }
+        connect(webtransport || host);
+        return false;
+    };
+
+    // drawTiles composites changed tiles of a frame onto the canvas
+    function drawTiles(frame) {
+        var ctx = document.getElementById('canvas').getContext('2d');
+        let sx = w / frame.width, sy = h / frame.height;
+        frame.tiles.forEach(tile => {
+            let img = new Image();
+            img.onload = function () {
+                ctx.drawImage(img, tile.x * frame.size * sx, tile.y * frame.size * sy, img.width * sx, img.height * sy);
+            };
+            img.src = `data:image/${frame.format};base64,${tile.image}`;
+        });
+    }
 
+    // delta frames are decoded in order onto an offscreen canvas holding the last frame
+    var delta_canvas = document.createElement("canvas");
+    var delta_pixels = null;
+    var delta_queue = Promise.resolve();
+
+    // drawDelta draws a png keyframe or applies the run length encoded xor difference to the last frame
+    function drawDelta(frame) {
+        delta_queue = delta_queue.then(() => new Promise(resolve => {
+            let dctx = delta_canvas.getContext('2d');
+            let show = function () {
+                document.getElementById('canvas').getContext('2d').drawImage(delta_canvas, 0, 0, w, h);
+                resolve();
+            };
+            if (frame.key) {
+                let img = new Image();
+                img.onload = function () {
+                    delta_canvas.width = frame.width;
+                    delta_canvas.height = frame.height;
+                    dctx.drawImage(img, 0, 0);
+                    delta_pixels = dctx.getImageData(0, 0, frame.width, frame.height);
+                    show();
+                };
+                img.src = `data:image/png;base64,${frame.data}`;
+                return;
+            }
+            if (!delta_pixels) {
+                resolve();
+                return;
+            }
+            let data = Uint8Array.from(atob(frame.data), c => c.charCodeAt(0));
+            let pix = delta_pixels.data;
+            let i = 0, p = 0;
+            let uvarint = function () {
+                let v = 0, shift = 0, b;
+                do {
+                    b = data[i++];
+                    v += (b & 0x7f) * Math.pow(2, shift);
+                    shift += 7;
+                } while (b & 0x80);
+                return v;
+            };
+            while (i < data.length) {
+                p += uvarint();
+                let n = uvarint();
+                for (let end = p + n; p < end; p++) {
+                    pix[p] ^= data[i++];
+                }
+            }
+            dctx.putImageData(delta_pixels, 0, 0);
+            show();
+        }));
+    }
+
+    // connect opens a session, following redirects of the server to a dedicated host.
+    // The query of the previous connection is passed on to the redirect target, parameters of the target take precedence.
+    function connect(url, query) {
         h = $('#canvas').height();
         w = $('#canvas').width();
         console.log(h, w)
-        ws = new WebSocket(`${host}?h=${h}&w=${w}`);
+        let target = new URL(url, window.location.href);
+        let params = new URLSearchParams(query || "");
+        target.searchParams.forEach(function (value, key) {
+            params.set(key, value);
+        });
+        params.set("h", h);
+        params.set("w", w);
+        // a room and name given to the page join a shared session
+        let page = new URLSearchParams(window.location.search);
+        if (page.has("room")) {
+            params.set("room", page.get("room"));
+            params.set("name", page.get("name") || "");
+        }
+        target.search = params.toString();
+        if (target.protocol == "https:") {
+            ws = new WebTransportSocket(target.toString());
+        } else {
+            ws = new WebSocket(target.toString());
+        }
 
         ws.onopen = function (evt) {
             print("Connected to Server");
@@ -45,6 +250,18 @@ window.addEventListener("load", function (evt) {
         ws.onclose = function (evt) {
             print("Closed Connection");
             ws = null;
+            if (evt.opened === false && url == webtransport) {
+                // WebTransport is blocked on the way to the server, e.g. UDP by a firewall
+                print("WebTransport unavailable, using websocket");
+                webtransport = null;
+                connect(host, query);
+                return;
+            }
+            if (redirect) {
+                let next = redirect;
+                redirect = null;
+                connect(next, target.search);
+            }
         }
         ws.onmessage = function (evt) {
             if (evt.data.startsWith('{') && evt.data.endsWith('}')) {
@@ -56,6 +273,58 @@ window.addEventListener("load", function (evt) {
                 if (feedback.action == "loading") {
                     spinner.style.display = 'block';
                 }
+                if (feedback.action == "tiles") {
+                    drawTiles(feedback.data);
+                }
+                if (feedback.action == "delta") {
+                    drawDelta(feedback.data);
+                }
+                if (feedback.action == "participants") {
+                    participants_ui.innerHTML = feedback.data.map(p =>
+                        `<span class="badge" style="background-color: ${p.color}; color: white">${p.name}</span> ${p.selection.join(", ")}`
+                    ).join(" ");
+                }
+                if (feedback.action == "chat") {
+                    let m = feedback.data;
+                    let line = document.createElement("div");
+                    line.innerHTML = `<span class="badge" style="background-color: ${m.color}; color: white"></span> `;
+                    line.firstChild.textContent = m.name;
+                    line.appendChild(document.createTextNode(m.point ? `points here ${m.text || ""}` : m.text));
+                    messages_ui.prepend(line);
+                }
+                if ((feedback.action == "export" || feedback.action == "recording" || feedback.action == "turntable" || feedback.action == "screenshot") && feedback.data) {
+                    let link = document.createElement("a");
+                    link.href = `data:application/octet-stream;base64,${feedback.data.file}`;
+                    link.download = feedback.data.name;
+                    link.click();
+                }
+                if (feedback.action == "redirect") {
+                    print(`Session moved to ${feedback.value}`);
+                    redirect = feedback.value;
+                }
+                if (feedback.action == "model-reloaded") {
+                    print("Model has been reloaded");
+                }
+                if (feedback.action == "server-closing") {
+                    print("Server is shutting down");
+                }
+                if (feedback.action == "session-expiring") {
+                    print(`Session expires in ${feedback.value} seconds`);
+                }
+                if (feedback.action == "load-failed") {
+                    print(`Loading the model failed: ${feedback.value}`);
+                }
+                if (feedback.action == "evicted") {
+                    print("Session has been closed by the server");
+                }
+                if (feedback.action == "coordinates") {
+                    if (feedback.data) {
+                        let p = feedback.data.point.map(v => v.toFixed(3)).join(", ");
+                        coordinates_ui.innerHTML = `XYZ <span class="badge badge-secondary">${p}</span>`;
+                    } else {
+                        coordinates_ui.innerHTML = "";
+                    }
+                }
                 if (feedback.action == "selected") {
                     if (feedback.value == "") {
                         selection_ui.innerHTML = "No selection"
@@ -75,8 +344,7 @@ window.addEventListener("load", function (evt) {
         ws.onerror = function (evt) {
             print("Error: " + evt.data);
         }
-        return false;
-    };
+    }
 
     canvas.onmousemove = function (evt) {
         if (!ws) {
@@ -86,7 +354,7 @@ window.addEventListener("load", function (evt) {
         var rect = evt.target.getBoundingClientRect();
         var x = (evt.clientX - rect.left);
         var y = (evt.clientY - rect.top);
-        ws.send(`{"x":${x},"y":${y}, "cmd":""}`);
+        ws.send(`{"x":${x},"y":${y}, "cmd":"", "time":${Date.now()}}`);
         return false;
     }
 
@@ -95,7 +363,7 @@ window.addEventListener("load", function (evt) {
         if (!ws) {
             return false;
         }
-        ws.send(`{"x":${evt.deltaX},"y":${evt.deltaY}, "cmd":"Zoom"}`);
+        ws.send(`{"x":${evt.deltaX},"y":${evt.deltaY}, "cmd":"Zoom", "time":${Date.now()}}`);
         return false;
     }
 
@@ -114,7 +382,7 @@ window.addEventListener("load", function (evt) {
         var x = (evt.clientX - rect.left);
         var y = (evt.clientY - rect.top);
         checkMouseMoved(x, y);
-        ws.send(`{"x":${x},"y":${y}, "cmd":"Mousedown", "val":"${evt.button}", "moved":${mouse_moved}}`);
+        ws.send(`{"x":${x},"y":${y}, "cmd":"Mousedown", "val":"${evt.button}", "moved":${mouse_moved}, "ctrl":${evt.ctrlKey}, "shift":${evt.shiftKey}, "alt":${evt.altKey}}`);
         prev_x = x;
         prev_y = y;
         return false;
@@ -139,7 +407,7 @@ window.addEventListener("load", function (evt) {
         var x = (evt.clientX - rect.left);
         var y = (evt.clientY - rect.top);
         checkMouseMoved(x, y);
-        ws.send(`{"x":${x},"y":${y}, "cmd":"Mouseup", "val":"${evt.button}", "moved":${mouse_moved}, "ctrl":${evt.ctrlKey}}`);
+        ws.send(`{"x":${x},"y":${y}, "cmd":"Mouseup", "val":"${evt.button}", "moved":${mouse_moved}, "ctrl":${evt.ctrlKey}, "shift":${evt.shiftKey}, "alt":${evt.altKey}}`);
 
         // open context menu if mouse hasn't been moved
         if (evt.button == 2 && !mouse_moved) {
@@ -154,13 +422,35 @@ window.addEventListener("load", function (evt) {
         return false;
     }
 
+    chat_ui.onkeydown = function (e) {
+        e.stopPropagation();
+        if (e.key == "Enter" && ws && chat_ui.value != "") {
+            ws.send(JSON.stringify({cmd: "Chat", val: chat_ui.value}));
+            chat_ui.value = "";
+        }
+    }
+
+    chat_ui.onkeyup = function (e) {
+        e.stopPropagation();
+    }
+
+    canvas.ondblclick = function (evt) {
+        if (!ws) {
+            return false;
+        }
+        var rect = evt.target.getBoundingClientRect();
+        ws.send(JSON.stringify({x: evt.clientX - rect.left, y: evt.clientY - rect.top, cmd: "Pointer", val: chat_ui.value}));
+        chat_ui.value = "";
+        return false;
+    }
+
     this.document.onkeydown = function (e) {
         e.preventDefault();
         e = e || window.event;
         if (!ws) {
             return false;
         }
-        ws.send(`{"cmd":"Keydown", "val":"${e.keyCode}"}`);
+        ws.send(`{"cmd":"Keydown", "val":"${e.keyCode}", "ctrl":${e.ctrlKey}, "shift":${e.shiftKey}, "alt":${e.altKey}}`);
         return false;
     }
 
@@ -170,7 +460,7 @@ window.addEventListener("load", function (evt) {
         if (!ws) {
             return false;
         }
-        ws.send(`{"cmd":"Keyup", "val":"${e.keyCode}"}`);
+        ws.send(`{"cmd":"Keyup", "val":"${e.keyCode}", "ctrl":${e.ctrlKey}, "shift":${e.shiftKey}, "alt":${e.altKey}}`);
         return false;
     }
 
@@ -318,6 +608,22 @@ window.addEventListener("load", function (evt) {
         return false;
     };
 
+    document.getElementById("cmd_encodeprogressive").onclick = function (evt) {
+        if (!ws) {
+            return false;
+        }
+        ws.send(`{"cmd":"Encoder", "val":"progressive"}`);
+        return false;
+    };
+
+    document.getElementById("cmd_encodepnginterlaced").onclick = function (evt) {
+        if (!ws) {
+            return false;
+        }
+        ws.send(`{"cmd":"Encoder", "val":"png-interlaced"}`);
+        return false;
+    };
+
     document.getElementById("cmd_qhigh").onclick = function (evt) {
         if (!ws) {
             return false;
@@ -352,6 +658,22 @@ window.addEventListener("load", function (evt) {
         return false;
     };
 
+    document.getElementById("cmd_coordinates").onclick = function (evt) {
+        if (!ws) {
+            return false;
+        }
+        ws.send(`{"cmd":"Coordinates"}`);
+        return false;
+    };
+
+    document.getElementById("cmd_viewports").onclick = function (evt) {
+        if (!ws) {
+            return false;
+        }
+        ws.send(`{"cmd":"Viewports"}`);
+        return false;
+    };
+
     document.getElementById("cmd_imageinvert").onclick = function (evt) {
         if (!ws) {
             return false;
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+// transport carries the messages of a session between client and server, a websocket or a WebTransport session.
+// Messages are JSON or base64 encoded image frames.
+type transport interface {
+	// readMessage returns the next message of the client, a *websocket.CloseError once the client closed the connection
+	readMessage() ([]byte, error)
+	// writeMessage sends a message to the client
+	writeMessage(message []byte) error
+	// keepAlive disconnects clients not answering pings within readTimeout
+	keepAlive()
+	// ping probes the client
+	ping() error
+	// close ends the connection with a websocket close code and reason
+	close(code int, reason string)
+}
+
+// upgradeClient turns a request into a websocket, or a WebTransport session for HTTP/3 CONNECT requests
+func upgradeClient(c *gin.Context) (transport, error) {
+	if c.Request.Method == http.MethodConnect {
+		return upgradeWebTransport(c)
+	}
+	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &wsTransport{conn: conn}, nil
+}
+
+// wsTransport sends messages as websocket text messages
+type wsTransport struct {
+	conn *websocket.Conn
+}
+
+func (t *wsTransport) readMessage() ([]byte, error) {
+	_, message, err := t.conn.ReadMessage()
+	return message, err
+}
+
+// writeMessage writes a single websocket message.
+// Only JSON messages get compressed, encoded images would hardly shrink.
+func (t *wsTransport) writeMessage(message []byte) error {
+	t.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+	t.conn.EnableWriteCompression(isJSONMessage(message))
+	return t.conn.WriteMessage(websocket.TextMessage, message)
+}
+
+func (t *wsTransport) keepAlive() {
+	t.conn.SetReadLimit(maxMessageSize)
+	t.conn.SetReadDeadline(time.Now().Add(readTimeout))
+	// SetPongHandler sets the handler for pong messages received from the peer.
+	t.conn.SetPongHandler(func(string) error { t.conn.SetReadDeadline(time.Now().Add(readTimeout)); return nil })
+}
+
+// ping and close write control messages, which may be sent concurrently to other messages
+func (t *wsTransport) ping() error {
+	return t.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(writeTimeout))
+}
+
+// close sends a close message, websocket.CloseNoStatusReceived sends it without code, and closes the connection
+func (t *wsTransport) close(code int, reason string) {
+	t.conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason), time.Now().Add(writeTimeout))
+	t.conn.Close()
+}
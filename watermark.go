@@ -0,0 +1,28 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	_ "image/jpeg" // jpeg logos
+	_ "image/png"  // png logos
+	"os"
+
+	"github.com/moethu/webg3n/renderer"
+)
+
+// loadWatermark reads the watermark logo, an empty path draws the caption only
+func loadWatermark(path string, opacity float32, caption string) (renderer.Watermark, error) {
+	w := renderer.Watermark{Opacity: opacity, Caption: caption}
+	if path == "" {
+		return w, nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return w, fmt.Errorf("watermark: %v", err)
+	}
+	defer f.Close()
+	if w.Logo, _, err = image.Decode(f); err != nil {
+		return w, fmt.Errorf("watermark %s: %v", path, err)
+	}
+	return w, nil
+}
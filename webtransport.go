@@ -0,0 +1,176 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/quic-go/quic-go"
+	"github.com/quic-go/quic-go/http3"
+	"github.com/quic-go/webtransport-go"
+)
+
+// webTransportLinger is the time given to clients to read the last messages before their session is closed
+const webTransportLinger = time.Second
+
+// webTransportServer serves sessions over WebTransport, nil if disabled
+var webTransportServer *webtransport.Server
+
+// webTransportWriterKey is the request context key of the HTTP/3 response writer, which gin wraps
+type webTransportWriterKey struct{}
+
+// newWebTransportServer serves the routes of the router over HTTP/3 on a UDP address,
+// so clients can open sessions by WebTransport instead of a websocket
+func newWebTransportServer(addr string, router http.Handler) *webtransport.Server {
+	s := &webtransport.Server{
+		H3: http3.Server{
+			Addr:       addr,
+			QUICConfig: &quic.Config{MaxIdleTimeout: readTimeout, KeepAlivePeriod: pingPeriod},
+		},
+		CheckOrigin: sameHostname,
+	}
+	s.H3.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		router.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), webTransportWriterKey{}, w)))
+	})
+	return s
+}
+
+// sameHostname accepts sessions opened by pages of this host, the page is served from another port than WebTransport
+func sameHostname(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	host, _, err := net.SplitHostPort(r.Host)
+	if err != nil {
+		host = r.Host
+	}
+	return u.Hostname() == host
+}
+
+// upgradeWebTransport accepts a WebTransport session and the stream the client opens for commands
+func upgradeWebTransport(c *gin.Context) (transport, error) {
+	w, ok := c.Request.Context().Value(webTransportWriterKey{}).(http.ResponseWriter)
+	if !ok || webTransportServer == nil {
+		return nil, errors.New("webtransport is not enabled")
+	}
+	session, err := webTransportServer.Upgrade(w, c.Request)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), readTimeout)
+	defer cancel()
+	control, err := session.AcceptStream(ctx)
+	if err != nil {
+		session.CloseWithError(webtransport.SessionErrorCode(websocket.CloseProtocolError), "missing control stream")
+		return nil, err
+	}
+	return &wtTransport{session: session, control: control, reader: bufio.NewReader(control)}, nil
+}
+
+// wtTransport sends JSON messages on a control stream and every frame on a unidirectional stream of its own,
+// so a lost packet only delays its frame instead of all following ones.
+// Messages on the control stream are prefixed by their length as 32 bit big endian, commands of the client alike.
+type wtTransport struct {
+	session *webtransport.Session
+	control *webtransport.Stream
+	reader  *bufio.Reader
+	mu      sync.Mutex // guards writes to the control stream
+}
+
+// readMessage returns the next command, empty messages only open the control stream and are skipped
+func (t *wtTransport) readMessage() ([]byte, error) {
+	for {
+		var size uint32
+		if err := binary.Read(t.reader, binary.BigEndian, &size); err != nil {
+			return nil, t.closeError(err)
+		}
+		if size > maxMessageSize {
+			return nil, fmt.Errorf("message of %d bytes exceeds the limit", size)
+		}
+		if size == 0 {
+			continue
+		}
+		message := make([]byte, size)
+		if _, err := io.ReadFull(t.reader, message); err != nil {
+			return nil, t.closeError(err)
+		}
+		return message, nil
+	}
+}
+
+// closeError returns the close code and reason of a session closed by the client like a websocket
+func (t *wtTransport) closeError(err error) error {
+	var se *webtransport.SessionError
+	if errors.As(err, &se) {
+		return &websocket.CloseError{Code: int(se.ErrorCode), Text: se.Message}
+	}
+	return err
+}
+
+func (t *wtTransport) writeMessage(message []byte) error {
+	if !isJSONMessage(message) {
+		return t.writeFrame(message)
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.control.SetWriteDeadline(time.Now().Add(writeTimeout))
+	var size [4]byte
+	binary.BigEndian.PutUint32(size[:], uint32(len(message)))
+	if _, err := t.control.Write(size[:]); err != nil {
+		return err
+	}
+	_, err := t.control.Write(message)
+	return err
+}
+
+// writeFrame sends a frame on a new unidirectional stream, the client reads it to the end
+func (t *wtTransport) writeFrame(frame []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), writeTimeout)
+	defer cancel()
+	str, err := t.session.OpenUniStreamSync(ctx)
+	if err != nil {
+		return err
+	}
+	str.SetWriteDeadline(time.Now().Add(writeTimeout))
+	if _, err := str.Write(frame); err != nil {
+		str.CancelWrite(0)
+		return err
+	}
+	return str.Close()
+}
+
+// keepAlive and ping are left to QUIC, which closes sessions idle for readTimeout
+func (t *wtTransport) keepAlive() {}
+
+func (t *wtTransport) ping() error {
+	return nil
+}
+
+// close ends the session with the websocket close code as application error code.
+// Streams still sending are reset by closing the session, so the control stream is finished first
+// and the client closes the session once it read the last messages, e.g. a redirect.
+func (t *wtTransport) close(code int, reason string) {
+	t.mu.Lock()
+	t.control.Close()
+	t.mu.Unlock()
+	select {
+	case <-t.session.Context().Done():
+	case <-time.After(webTransportLinger):
+	}
+	t.session.CloseWithError(webtransport.SessionErrorCode(code), reason)
+}
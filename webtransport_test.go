@@ -0,0 +1,36 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestSameHostname(t *testing.T) {
+	for origin, expected := range map[string]bool{
+		"":                          true,
+		"http://viewer.local:8000":  true,
+		"https://viewer.local":      true,
+		"http://other.local:8000":   false,
+		"http://viewer.local.other": false,
+	} {
+		r := &http.Request{Host: "viewer.local:4433", Header: http.Header{}}
+		if origin != "" {
+			r.Header.Set("Origin", origin)
+		}
+		if sameHostname(r) != expected {
+			t.Errorf("origin %q accepted %v, expected %v", origin, !expected, expected)
+		}
+	}
+}
+
+func TestWebTransportURL(t *testing.T) {
+	if u := webTransportURL("viewer.local:8000", ":4433"); u != "https://viewer.local:4433/webg3n" {
+		t.Error("unexpected url", u)
+	}
+	if u := webTransportURL("viewer.local", "0.0.0.0:4433"); u != "https://viewer.local:4433/webg3n" {
+		t.Error("unexpected url", u)
+	}
+	if u := webTransportURL("viewer.local:8000", ""); u != "" {
+		t.Error("url without webtransport", u)
+	}
+}